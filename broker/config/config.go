@@ -11,6 +11,9 @@ type Config struct {
 	CatalogPath              string  `json:"catalog_path"`
 	DefaultProbability       float64 `json:"default_probability"`
 	DefaultFrequency         int     `json:"default_frequency"`
+	OperatorUsername         string  `json:"operator_username"`
+	OperatorPassword         string  `json:"operator_password"`
+	RouteServiceURL          string  `json:"route_service_url"`
 	DatabaseConnectionString string
 }
 
@@ -0,0 +1,11 @@
+package model
+
+import (
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+)
+
+// History struct - the chaos history of a service instance
+type History struct {
+	Recovery []sharedModel.RecoveryStats `json:"recovery"`
+	Events   []sharedModel.Event         `json:"events"`
+}
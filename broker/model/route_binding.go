@@ -0,0 +1,8 @@
+package model
+
+// RouteBinding struct - a route bound to a service instance, its traffic sent through the route service
+type RouteBinding struct {
+	ID                string `json:"id"`
+	ServiceInstanceID string `json:"service_instance_id"`
+	Route             string `json:"route"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import (
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+)
+
+// ScheduleRequest struct - a change to schedule for a service instance, reverted to the values it replaces at revert_at when
+// one is given
+type ScheduleRequest struct {
+	sharedModel.ScheduledChange
+	RevertAt string `json:"revert_at"`
+}
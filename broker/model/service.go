@@ -6,6 +6,7 @@ type Service struct {
 	ID          string        `json:"id"`
 	Description string        `json:"description"`
 	Bindable    bool          `json:"bindable"`
+	Requires    []string      `json:"requires,omitempty"`
 	Plans       []ServicePlan `json:"plans"`
 	Metadata    interface{}   `json:"metadata, omitempty"`
 }
@@ -2,7 +2,19 @@ package model
 
 // CreateServiceBindingResponse struct
 type CreateServiceBindingResponse struct {
-	Credentials interface{} `json:"credentials"`
+	Credentials     interface{} `json:"credentials"`
+	RouteServiceURL string      `json:"route_service_url,omitempty"`
+}
+
+// BindRequest struct - the body of a bind request, binding either an app or a route
+type BindRequest struct {
+	AppID        string       `json:"app_guid"`
+	BindResource BindResource `json:"bind_resource"`
+}
+
+// BindResource struct - the resource a service instance is bound to
+type BindResource struct {
+	Route string `json:"route"`
 }
 
 // Credential struct
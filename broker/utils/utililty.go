@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/FidelityInternational/chaos-galago/broker/model"
 	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
 	"github.com/gorilla/mux"
 	"io"
 	"io/ioutil"
@@ -86,6 +88,23 @@ func SetupBindingDB(db *sql.DB) error {
 	return nil
 }
 
+// SetupRouteBindingDB - creates the route_bindings DB if it does not exist
+func SetupRouteBindingDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS route_bindings
+	(
+		id varchar(255),
+		serviceInstanceID varchar(255),
+		route varchar(255),
+		PRIMARY KEY (id)
+	)`)
+
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
 // UpdateServiceInstance - update service_instances database
 func UpdateServiceInstance(db *sql.DB, serviceInstanceID string, probability float64, frequency int) error {
 	_, err := db.Exec("UPDATE service_instances SET probability=?,frequency=? WHERE id=?", probability, frequency, serviceInstanceID)
@@ -95,6 +114,34 @@ func UpdateServiceInstance(db *sql.DB, serviceInstanceID string, probability flo
 	return nil
 }
 
+// UpdateInstanceSettings - writes the settings of a service instance to instance_settings database
+func UpdateInstanceSettings(db *sql.DB, settings sharedModel.InstanceSettings) error {
+	var placeholders, updates []string
+	for _, column := range sharedUtils.InstanceSettingsColumns {
+		placeholders = append(placeholders, "?")
+		if column != "serviceInstanceID" {
+			updates = append(updates, fmt.Sprintf("%s=VALUES(%s)", column, column))
+		}
+	}
+
+	_, err := db.Exec(fmt.Sprintf("INSERT INTO instance_settings (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s",
+		strings.Join(sharedUtils.InstanceSettingsColumns, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", ")),
+		sharedUtils.InstanceSettingsValues(settings)...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteInstanceSettings - deletes from instance_settings based on service instance ID
+func DeleteInstanceSettings(db *sql.DB, serviceInstanceID string) error {
+	_, err := db.Exec("DELETE FROM instance_settings WHERE serviceInstanceID=?", serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // GetServiceInstance - loads a service instance to memory from database
 func GetServiceInstance(db *sql.DB, serviceInstanceID string) (sharedModel.ServiceInstance, error) {
 	var (
@@ -153,6 +200,55 @@ func AddServiceBinding(db *sql.DB, serviceBinding sharedModel.ServiceBinding) er
 	return nil
 }
 
+// AddRouteBinding - adds a row to route_bindings database
+func AddRouteBinding(db *sql.DB, routeBinding model.RouteBinding) error {
+	_, err := db.Exec("INSERT INTO route_bindings VALUES (?, ?, ?)", routeBinding.ID, routeBinding.ServiceInstanceID, routeBinding.Route)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetRouteBindings - loads the routes bound to a service instance from database
+func GetRouteBindings(db *sql.DB, serviceInstanceID string) ([]model.RouteBinding, error) {
+	rows, err := db.Query("SELECT id, serviceInstanceID, route FROM route_bindings WHERE serviceInstanceID=? ORDER BY route", serviceInstanceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	routeBindings := []model.RouteBinding{}
+	for rows.Next() {
+		var routeBinding model.RouteBinding
+		if err = rows.Scan(&routeBinding.ID, &routeBinding.ServiceInstanceID, &routeBinding.Route); err != nil {
+			return nil, err
+		}
+		routeBindings = append(routeBindings, routeBinding)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return routeBindings, nil
+}
+
+// DeleteRouteBinding - deletes from route_bindings based on service binding ID
+func DeleteRouteBinding(db *sql.DB, serviceBindingID string) error {
+	_, err := db.Exec("DELETE FROM route_bindings WHERE id=?", serviceBindingID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteServiceInstanceRouteBindings - deletes from route_bindings based on service instance ID
+func DeleteServiceInstanceRouteBindings(db *sql.DB, serviceInstanceID string) error {
+	_, err := db.Exec("DELETE FROM route_bindings WHERE serviceInstanceID=?", serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // AddServiceInstance - adds a row to service_isntances database
 func AddServiceInstance(db *sql.DB, serviceInstance sharedModel.ServiceInstance) error {
 	_, err := db.Exec("INSERT INTO service_instances VALUES (?, ?, ?, ?, ?)", serviceInstance.ID, serviceInstance.DashboardURL, serviceInstance.PlanID, serviceInstance.Probability, serviceInstance.Frequency)
@@ -179,7 +275,7 @@ func WriteResponse(w http.ResponseWriter, code int, object interface{}) {
 		}
 	}
 	w.WriteHeader(code)
-	fmt.Fprintf(w, string(data))
+	w.Write(data)
 }
 
 // ProvisionDataFromRequest - Unmarhsals json to object
@@ -2,6 +2,7 @@ package utils_test
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/FidelityInternational/chaos-galago/broker/model"
@@ -118,6 +119,15 @@ var _ = Describe("#WriteResponse", func() {
 			Expect(mockRecorder.Code).To(Equal(200))
 			Expect(mockRecorder.Body.String()).To(Equal(`Just a nice string`))
 		})
+
+		Context("and it contains a percent sign", func() {
+			It("returns the string value unformatted", func() {
+				mockRecorder := httptest.NewRecorder()
+				utils.WriteResponse(mockRecorder, 200, "100% of instances")
+				Expect(mockRecorder.Code).To(Equal(200))
+				Expect(mockRecorder.Body.String()).To(Equal(`100% of instances`))
+			})
+		})
 	})
 })
 
@@ -193,6 +203,76 @@ var _ = Describe("#UpdateServiceInstance", func() {
 	})
 })
 
+var _ = Describe("#UpdateInstanceSettings", func() {
+	It("Upserts the instance settings", func() {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		settings := sharedModel.NewInstanceSettings("test")
+		settings.Seed = 42
+		settings.Strategy = sharedModel.StrategyFixed
+		settings.StrategyIndex = 3
+		settings.KillPercent = 50
+		settings.MinHealthy = 2
+		settings.HealthyStates = []string{"RUNNING", "STARTING"}
+		settings.Action = sharedModel.ActionRestage
+		settings.MTBFMinutes = 240
+		settings.AdaptiveStep = 5
+
+		mock.ExpectExec("INSERT INTO instance_settings .* ON DUPLICATE KEY UPDATE .*").WithArgs("test", 42, "fixed", 3, 1, 50, 2, 1, 100, "RUNNING,STARTING", "restage", 30, 50, 240, 5, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+		Expect(utils.UpdateInstanceSettings(db, settings)).To(BeNil())
+	})
+
+	Context("When the sql command raises an error", func() {
+		It("returns an error", func() {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			mock.ExpectExec("INSERT INTO instance_settings.*").WillReturnError(fmt.Errorf("An error has occured: %s", "INSERT error"))
+			err = utils.UpdateInstanceSettings(db, sharedModel.NewInstanceSettings("test"))
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal("An error has occured: INSERT error"))
+		})
+	})
+})
+
+var _ = Describe("#DeleteInstanceSettings", func() {
+	It("Deletes the instance settings", func() {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		mock.ExpectExec("DELETE FROM instance_settings WHERE serviceInstanceID=").WithArgs("test").WillReturnResult(sqlmock.NewResult(1, 1))
+		Expect(utils.DeleteInstanceSettings(db, "test")).To(BeNil())
+	})
+
+	Context("When the sql command raises an error", func() {
+		It("returns an error", func() {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			mock.ExpectExec("DELETE FROM instance_settings WHERE serviceInstanceID=").WithArgs("test").WillReturnError(fmt.Errorf("An error has occured: %s", "DELETE error"))
+			err = utils.DeleteInstanceSettings(db, "test")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
+
 var _ = Describe("#DeleteServiceInstance", func() {
 	It("Deletes service instance from the database", func() {
 		var instance sharedModel.ServiceInstance
@@ -427,6 +507,105 @@ var _ = Describe("#AddServiceBinding", func() {
 	})
 })
 
+var _ = Describe("Route bindings", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+		err  error
+	)
+
+	BeforeEach(func() {
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupRouteBindingDB", func() {
+		It("creates the route_bindings table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS route_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupRouteBindingDB(db)).To(BeNil())
+		})
+
+		Context("When the create command returns an error", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS route_bindings.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+				Expect(utils.SetupRouteBindingDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#AddRouteBinding", func() {
+		It("adds the route binding to the database", func() {
+			mock.ExpectExec("INSERT INTO route_bindings").WithArgs("binding-1", "test", "www.example.com/shop").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.AddRouteBinding(db, model.RouteBinding{ID: "binding-1", ServiceInstanceID: "test", Route: "www.example.com/shop"})).To(BeNil())
+		})
+
+		Context("When the sql insert command raises an error", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO route_bindings").WillReturnError(fmt.Errorf("An error has occured: %s", "INSERT error"))
+				Expect(utils.AddRouteBinding(db, model.RouteBinding{ID: "binding-1"})).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetRouteBindings", func() {
+		It("returns the routes bound to the service instance", func() {
+			rows := sqlmock.NewRows([]string{"id", "serviceInstanceID", "route"}).
+				AddRow("binding-1", "test", "api.example.com").
+				AddRow("binding-2", "test", "www.example.com/shop")
+			mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("test").WillReturnRows(rows)
+			routeBindings, err := utils.GetRouteBindings(db, "test")
+			Expect(err).To(BeNil())
+			Expect(routeBindings).To(Equal([]model.RouteBinding{
+				{ID: "binding-1", ServiceInstanceID: "test", Route: "api.example.com"},
+				{ID: "binding-2", ServiceInstanceID: "test", Route: "www.example.com/shop"},
+			}))
+		})
+
+		Context("When the sql query raises an error", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM route_bindings").WillReturnError(fmt.Errorf("An error has occured: %s", "SELECT error"))
+				_, err := utils.GetRouteBindings(db, "test")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeleteRouteBinding", func() {
+		It("deletes the route binding from the database", func() {
+			mock.ExpectExec("DELETE FROM route_bindings WHERE id=").WithArgs("binding-1").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.DeleteRouteBinding(db, "binding-1")).To(BeNil())
+		})
+
+		Context("When the sql delete command raises an error", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("DELETE FROM route_bindings WHERE id=").WillReturnError(fmt.Errorf("An error has occured: %s", "DELETE error"))
+				Expect(utils.DeleteRouteBinding(db, "binding-1")).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeleteServiceInstanceRouteBindings", func() {
+		It("deletes the route bindings of the service instance from the database", func() {
+			mock.ExpectExec("DELETE FROM route_bindings WHERE serviceInstanceID=").WithArgs("test").WillReturnResult(sqlmock.NewResult(1, 2))
+			Expect(utils.DeleteServiceInstanceRouteBindings(db, "test")).To(BeNil())
+		})
+
+		Context("When the sql delete command raises an error", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("DELETE FROM route_bindings WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occured: %s", "DELETE error"))
+				Expect(utils.DeleteServiceInstanceRouteBindings(db, "test")).ToNot(BeNil())
+			})
+		})
+	})
+})
+
 var _ = Describe("#SetupInstanceDB", func() {
 	Context("When the table exists", func() {
 		It("does nothing", func() {
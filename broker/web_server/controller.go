@@ -1,22 +1,34 @@
 package webServer
 
 import (
+	"crypto/subtle"
 	"database/sql"
 	"fmt"
 	"github.com/FidelityInternational/chaos-galago/broker/config"
 	model "github.com/FidelityInternational/chaos-galago/broker/model"
 	utils "github.com/FidelityInternational/chaos-galago/broker/utils"
 	sharedModel "github.com/FidelityInternational/chaos-galago/shared/model"
+	sharedUtils "github.com/FidelityInternational/chaos-galago/shared/utils"
+	"html"
 	"net/http"
+	"net/url"
 	"os"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	defaultPollingIntervalSeconds = 10
 )
 
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
 // Controller struct
 type Controller struct {
 	DB   *sql.DB
@@ -188,6 +200,62 @@ func (c *Controller) RemoveServiceInstance(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	err = utils.DeleteInstanceSettings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = sharedUtils.DeleteProbes(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = sharedUtils.DeletePausedBindings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = utils.DeleteServiceInstanceRouteBindings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = sharedUtils.DeleteRouteFaults(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = sharedUtils.DeleteProfile(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = sharedUtils.DeleteProbabilityChanges(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = sharedUtils.DeleteScheduledChanges(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	err = utils.DeleteServiceInstance(c.DB, instance)
 	if err != nil {
 		fmt.Println(err)
@@ -211,13 +279,16 @@ func (c *Controller) DeleteAssociatedBindings(instanceID string) error {
 
 // Bind - bins a service instance
 func (c *Controller) Bind(w http.ResponseWriter, r *http.Request) {
-	var binding sharedModel.ServiceBinding
+	var (
+		request model.BindRequest
+		binding sharedModel.ServiceBinding
+	)
 	fmt.Println("Bind Service Instance...")
 
 	bindingID := utils.ExtractVarsFromRequest(r, "service_binding_guid")
 	instanceID := utils.ExtractVarsFromRequest(r, "service_instance_guid")
 
-	err := utils.ProvisionDataFromRequest(r.Body, &binding)
+	err := utils.ProvisionDataFromRequest(r.Body, &request)
 	if err != nil {
 		fmt.Println(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -246,12 +317,19 @@ func (c *Controller) Bind(w http.ResponseWriter, r *http.Request) {
 	response := model.CreateServiceBindingResponse{
 		Credentials: credential,
 	}
-	if binding.AppID == "" {
+
+	if request.BindResource.Route != "" {
+		c.bindRoute(w, instance, bindingID, request.BindResource.Route, response)
+		return
+	}
+
+	if request.AppID == "" {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	binding.ID = bindingID
+	binding.AppID = request.AppID
 	binding.ServicePlanID = instance.PlanID
 	binding.ServiceInstanceID = instance.ID
 
@@ -265,6 +343,26 @@ func (c *Controller) Bind(w http.ResponseWriter, r *http.Request) {
 	utils.WriteResponse(w, http.StatusCreated, response)
 }
 
+// bindRoute - binds a route to a service instance, sending its traffic through the route service
+func (c *Controller) bindRoute(w http.ResponseWriter, instance sharedModel.ServiceInstance, bindingID string, route string, response model.CreateServiceBindingResponse) {
+	routeServiceURL, err := GetConfigVariable(c, "ROUTE_SERVICE_URL", "RouteServiceURL")
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = utils.AddRouteBinding(c.DB, model.RouteBinding{ID: bindingID, ServiceInstanceID: instance.ID, Route: route})
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.RouteServiceURL = strings.TrimSuffix(routeServiceURL, "/") + "/" + instance.ID
+	utils.WriteResponse(w, http.StatusCreated, response)
+}
+
 // UnBind - unbinds a service instance
 func (c *Controller) UnBind(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("Unbind Service Instance...")
@@ -289,6 +387,20 @@ func (c *Controller) UnBind(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = utils.DeleteRouteBinding(c.DB, bindingID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	_, err = sharedUtils.ResumeBinding(c.DB, instanceID, bindingID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	utils.WriteResponse(w, http.StatusOK, "{}")
 }
 
@@ -308,6 +420,74 @@ func (c *Controller) GetDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	settings, err := sharedUtils.GetInstanceSettings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	recoveryStats, err := sharedUtils.GetRecoveryStats(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	paused, err := sharedUtils.GetPausedBindings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	halt, err := sharedUtils.GetHalt(c.DB)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	routeBindings, err := utils.GetRouteBindings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	routeFaults, err := sharedUtils.GetRouteFaults(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	profile, err := sharedUtils.GetProfile(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	changes, err := sharedUtils.GetProbabilityChanges(c.DB, instanceID, 20)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var lastChange sharedModel.ProbabilityChange
+	if len(changes) > 0 {
+		lastChange = changes[0]
+	}
+	probability := sharedUtils.EffectiveProbability(settings, instance.Probability, lastChange)
+
+	scheduledChanges, err := sharedUtils.GetScheduledChanges(c.DB, instanceID, 20)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	response := fmt.Sprintf(`<html>
 	<head>
 		<link rel="stylesheet" href="/css/bootstrap.min.css">
@@ -318,8 +498,10 @@ func (c *Controller) GetDashboard(w http.ResponseWriter, r *http.Request) {
 		<title>Dashboard</title>
 	</head>
 	<body>
-		<div class="container">
+		<div class="container">%s
 			<h1>Change Service Instance Config</h1>
+			<p>Effective Probability: %v</p>
+			<p>Expected Kills Per Day: %.1f</p>
 			<form action="/dashboard/%s" method="POST">
 				<fieldset class="form-group">
 					<label for "probability">Probability</label>
@@ -329,22 +511,171 @@ func (c *Controller) GetDashboard(w http.ResponseWriter, r *http.Request) {
 					<label for "frequency">Frequency</label>
 					<input type="number" min="1" max="60" class="form-control" id="frequency" name="frequency" placeholder="%v">
 				</fieldset>
+				<fieldset class="form-group">
+					<label for "mtbf_minutes">Mean Time Between Failures (minutes)</label>
+					<input type="number" min="1" class="form-control" id="mtbf_minutes" name="mtbf_minutes" value="%s" placeholder="unused">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "adaptive_step">Adaptive Step (percentage points)</label>
+					<input type="number" step="1" min="1" max="100" class="form-control" id="adaptive_step" name="adaptive_step" value="%s" placeholder="unused">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "max_recovery_seconds">Adaptive Recovery Threshold (seconds)</label>
+					<input type="number" step="1" min="1" class="form-control" id="max_recovery_seconds" name="max_recovery_seconds" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "seed">Seed</label>
+					<input type="number" step="1" class="form-control" id="seed" name="seed" value="%s" placeholder="random">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "strategy">Victim Strategy</label>
+					<select class="form-control" id="strategy" name="strategy">%s
+					</select>
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "strategy_index">Fixed Instance Index</label>
+					<input type="number" step="1" min="0" class="form-control" id="strategy_index" name="strategy_index" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "kill_count">Instances Killed Per Run</label>
+					<input type="number" step="1" min="1" class="form-control" id="kill_count" name="kill_count" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "kill_percent">Percentage Of Instances Killed Per Run</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="kill_percent" name="kill_percent" value="%s" placeholder="unused">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "min_healthy">Minimum Healthy Instances</label>
+					<input type="number" step="1" min="0" class="form-control" id="min_healthy" name="min_healthy" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "min_instances">Minimum Instances</label>
+					<input type="number" step="1" min="0" class="form-control" id="min_instances" name="min_instances" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "min_healthy_percent">Minimum Healthy Percentage</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="min_healthy_percent" name="min_healthy_percent" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "healthy_states">Healthy States</label>
+					<input type="text" class="form-control" id="healthy_states" name="healthy_states" value="%s" placeholder="RUNNING">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "action">Action</label>
+					<select class="form-control" id="action" name="action">%s
+					</select>
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "action_delay">Action Delay (seconds)</label>
+					<input type="number" step="1" min="0" class="form-control" id="action_delay" name="action_delay" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "intensity">Resource Exhaustion Intensity (percent)</label>
+					<input type="number" step="1" min="1" max="100" class="form-control" id="intensity" name="intensity" value="%v">
+				</fieldset>
 				<div class="form-group row">
 					<button type="submit" class="btn btn-primary">Submit</button>
 				</div>
 			</form>
+			<h2>Scheduled Changes</h2>
+			<table class="table">
+				<tr><th>Apply At (UTC)</th><th>Probability</th><th>Frequency</th><th>State</th><th></th></tr>%s
+			</table>
+			<form action="/dashboard/%s/scheduled_changes" method="POST">
+				<fieldset class="form-group">
+					<label for "apply_at">Apply At (UTC)</label>
+					<input type="datetime-local" class="form-control" id="apply_at" name="apply_at">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "scheduled_probability">Probability</label>
+					<input type="number" step="0.01" min="0" max="1" class="form-control" id="scheduled_probability" name="probability" placeholder="unchanged">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "scheduled_frequency">Frequency</label>
+					<input type="number" min="1" max="60" class="form-control" id="scheduled_frequency" name="frequency" placeholder="unchanged">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "revert_at">Revert At (UTC)</label>
+					<input type="datetime-local" class="form-control" id="revert_at" name="revert_at">
+				</fieldset>
+				<div class="form-group row">
+					<button type="submit" class="btn btn-primary">Schedule Change</button>
+				</div>
+			</form>
+			<h2>Action Profile</h2>
+			<form action="/dashboard/%s/profile" method="POST">
+				<fieldset class="form-group">
+					<label for "profile">Weighted Actions (action:weight, comma separated)</label>
+					<input type="text" class="form-control" id="profile" name="profile" value="%s" placeholder="unused, e.g. kill:70,restart:20,network-partition:10">
+				</fieldset>
+				<div class="form-group row">
+					<button type="submit" class="btn btn-primary">Save Profile</button>
+				</div>
+			</form>
+			<h2>Recovery</h2>
+			<table class="table">
+				<tr><th>App</th><th>Kills</th><th>Recovered</th><th>Timed Out</th><th>MTTR (seconds)</th><th>Slowest Recovery (seconds)</th></tr>%s
+			</table>
+			<p><a href="/api/service_instances/%s/history">Full history</a></p>
+			<h2>Adaptive Probability</h2>
+			<table class="table">
+				<tr><th>Changed At</th><th>App</th><th>Change</th><th>Probability</th><th>Reason</th></tr>%s
+			</table>
+			<h2>Paused Apps</h2>
+			<table class="table">
+				<tr><th>App</th><th>Paused At</th><th>Reason</th><th></th></tr>%s
+			</table>
+			<h2>Route Faults</h2>
+			<table class="table">
+				<tr><th>Bound Route</th></tr>%s
+			</table>
+			<form action="/dashboard/%s/route_faults" method="POST">
+				<fieldset class="form-group">
+					<label for "latency_ms">Latency (milliseconds)</label>
+					<input type="number" step="1" min="0" class="form-control" id="latency_ms" name="latency_ms" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "latency_percent">Percentage Of Requests Delayed</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="latency_percent" name="latency_percent" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "error_status">Error Status Code</label>
+					<input type="number" step="1" min="400" max="599" class="form-control" id="error_status" name="error_status" value="%s" placeholder="unused">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "error_percent">Percentage Of Requests Failed</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="error_percent" name="error_percent" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "drop_percent">Percentage Of Connections Dropped</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="drop_percent" name="drop_percent" value="%v">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "bandwidth_kbps">Bandwidth Limit (KB per second)</label>
+					<input type="number" step="1" min="0" class="form-control" id="bandwidth_kbps" name="bandwidth_kbps" value="%s" placeholder="unlimited">
+				</fieldset>
+				<div class="form-group row">
+					<button type="submit" class="btn btn-primary">Save Route Faults</button>
+				</div>
+			</form>
 		</div>
 	</body>
 </html>
-`, instanceID, instance.Probability, instance.Frequency)
+`, haltBanner(halt), probability, sharedUtils.ExpectedKillsPerDay(probability, instance.Frequency, settings.MTBFMinutes), instanceID,
+		instance.Probability, instance.Frequency, optionalValue(settings.MTBFMinutes), optionalValue(settings.AdaptiveStep), settings.MaxRecoverySeconds,
+		seedValue(settings.Seed), strategyOptions(settings.Strategy), settings.StrategyIndex,
+		settings.KillCount, optionalValue(settings.KillPercent), settings.MinHealthy,
+		settings.MinInstances, settings.MinHealthyPercent, strings.Join(settings.HealthyStates, ","), actionOptions(settings.Action), settings.ActionDelay,
+		settings.Intensity, scheduledChangeRows(instanceID, scheduledChanges), instanceID, instanceID, html.EscapeString(sharedUtils.FormatProfile(profile.Actions)), recoveryRows(recoveryStats), instanceID, probabilityRows(changes), pausedRows(instanceID, paused), routeRows(routeBindings), instanceID,
+		routeFaults.LatencyMs, routeFaults.LatencyPercent, optionalValue(routeFaults.ErrorStatus), routeFaults.ErrorPercent, routeFaults.DropPercent,
+		optionalValue(routeFaults.BandwidthKBps))
 
 	utils.WriteResponse(w, http.StatusOK, response)
 }
 
-// UpdateServiceInstance - updates a service instance
-func (c *Controller) UpdateServiceInstance(w http.ResponseWriter, r *http.Request) {
-	var valid = true
-	fmt.Println("Updating Service Instance...")
+// GetHistory - returns the chaos events of a service instance with the time to recover statistics of its apps
+func (c *Controller) GetHistory(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Service Instance History...")
 
 	instanceID := utils.ExtractVarsFromRequest(r, "service_instance_guid")
 	instance, err := utils.GetServiceInstance(c.DB, instanceID)
@@ -358,47 +689,1044 @@ func (c *Controller) UpdateServiceInstance(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	probability, _ := strconv.ParseFloat(r.FormValue("probability"), 64)
-	frequency, _ := strconv.Atoi(r.FormValue("frequency"))
+	limit := defaultHistoryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || !(limit >= 1 && limit <= maxHistoryLimit) {
+			utils.WriteResponse(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit))
+			return
+		}
+	}
 
-	if !(probability >= 0 && probability <= 1) {
-		fmt.Printf("\nProbability: %v\n", probability)
-		valid = false
+	var history model.History
+	history.Recovery, err = sharedUtils.GetRecoveryStats(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	history.Events, err = sharedUtils.GetEvents(c.DB, instanceID, limit)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
-	if !(frequency >= 1 && frequency <= 60) {
-		fmt.Printf("\nFrequency: %v\n", frequency)
-		valid = false
+	utils.WriteResponse(w, http.StatusOK, history)
+}
+
+// ReplayEvent - asks for the run recorded by an event of a service instance to be replayed against its app on the next
+// loop of the processor, drawing from the seed recorded with the event
+func (c *Controller) ReplayEvent(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Replay Service Instance Event...")
+
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
 	}
 
-	if valid {
-		err = utils.UpdateServiceInstance(c.DB, instanceID, probability, frequency)
-		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return
+	}
 
-		response := fmt.Sprintf(`<html>
-	<head>
-		<link rel="stylesheet" href="/css/bootstrap.min.css">
-		<link rel="stylesheet" href="/css/bootstrap-theme.min.css">
-		<script src="/js/jquery-1.11.3.min.js"></script>
-		<script src="/js/bootstrap.min.js"></script>
-		<meta name="viewport" content="width=device-width, initial-scale=1">
-		<title>Dashboard</title>
-	</head>
-	<body>
-		<div class="container">
-			<h1>New Service Instance Configuration</h1>
-			<p>Probability: %v</p>
-			<p>Frequency: %v</p>
-		</div>
-	</body>
-</html>`, probability, frequency)
-		utils.WriteResponse(w, http.StatusAccepted, response)
-	} else {
-		response := `<html>
+	var request sharedModel.Replay
+	err := utils.ProvisionDataFromRequest(r.Body, &request)
+	if err != nil {
+		utils.WriteResponse(w, http.StatusBadRequest, "replay must be a JSON object")
+		return
+	}
+
+	event, err := sharedUtils.GetEvent(c.DB, instanceID, request.EventID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if event.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if event.Seed == 0 || event.ServiceBindingID == "" {
+		utils.WriteResponse(w, http.StatusBadRequest, "event has no recorded run to replay")
+		return
+	}
+
+	replay := sharedModel.Replay{ServiceBindingID: event.ServiceBindingID, ServiceInstanceID: instanceID, AppID: event.AppID, EventID: event.ID,
+		Seed: event.Seed, RequestedAt: time.Now().UTC().Format(time.RFC3339)}
+	err = sharedUtils.RequestReplay(c.DB, replay)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusAccepted, replay)
+}
+
+// GetProbes - lists the steady state probes of a service instance
+func (c *Controller) GetProbes(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Service Instance Probes...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return
+	}
+
+	probes, err := sharedUtils.GetProbes(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, probes)
+}
+
+// AddProbe - adds a steady state probe to a service instance. Only operators may add probes, as the processor requests
+// their URLs from inside the foundation.
+func (c *Controller) AddProbe(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Add Service Instance Probe...")
+
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return
+	}
+
+	var probe sharedModel.Probe
+	err := utils.ProvisionDataFromRequest(r.Body, &probe)
+	if err != nil {
+		utils.WriteResponse(w, http.StatusBadRequest, "probe must be a JSON object")
+		return
+	}
+	if reason := validateProbe(probe); reason != "" {
+		utils.WriteResponse(w, http.StatusBadRequest, reason)
+		return
+	}
+
+	probe.ServiceInstanceID = instanceID
+	probe.ID, err = sharedUtils.AddProbe(c.DB, probe)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusCreated, probe)
+}
+
+// RemoveProbe - deletes a steady state probe of a service instance
+func (c *Controller) RemoveProbe(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Remove Service Instance Probe...")
+
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return
+	}
+
+	probeID, err := strconv.ParseInt(utils.ExtractVarsFromRequest(r, "probe_id"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	deleted, err := sharedUtils.DeleteProbe(c.DB, instanceID, probeID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, "{}")
+}
+
+// GetPausedBindings - lists the bindings of a service instance chaos is paused for
+func (c *Controller) GetPausedBindings(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Service Instance Paused Bindings...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return
+	}
+
+	paused, err := sharedUtils.GetPausedBindings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, paused)
+}
+
+// ResumeBinding - resumes chaos for a paused binding of a service instance
+func (c *Controller) ResumeBinding(w http.ResponseWriter, r *http.Request) {
+	if c.resumeBinding(w, r) {
+		utils.WriteResponse(w, http.StatusOK, "{}")
+	}
+}
+
+// ResumeBindingFromDashboard - resumes chaos for a paused binding of a service instance and returns to the dashboard
+func (c *Controller) ResumeBindingFromDashboard(w http.ResponseWriter, r *http.Request) {
+	if c.resumeBinding(w, r) {
+		http.Redirect(w, r, "/dashboard/"+utils.ExtractVarsFromRequest(r, "service_instance_guid"), http.StatusSeeOther)
+	}
+}
+
+// resumeBinding - resumes chaos for the paused binding of a request, writing the response when it cannot be resumed
+func (c *Controller) resumeBinding(w http.ResponseWriter, r *http.Request) bool {
+	fmt.Println("Resume Service Binding...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return false
+	}
+
+	resumed, err := sharedUtils.ResumeBinding(c.DB, instanceID, utils.ExtractVarsFromRequest(r, "service_binding_guid"))
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !resumed {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// GetRouteFaults - returns the faults the route service injects into the routes bound to a service instance
+func (c *Controller) GetRouteFaults(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Service Instance Route Faults...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return
+	}
+
+	faults, err := sharedUtils.GetRouteFaults(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, faults)
+}
+
+// UpdateRouteFaults - sets the faults the route service injects into the routes bound to a service instance
+func (c *Controller) UpdateRouteFaults(w http.ResponseWriter, r *http.Request) {
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	var faults sharedModel.RouteFaults
+	err := utils.ProvisionDataFromRequest(r.Body, &faults)
+	if err != nil {
+		utils.WriteResponse(w, http.StatusBadRequest, "route faults must be a JSON object")
+		return
+	}
+
+	if c.updateRouteFaults(w, r, &faults) {
+		utils.WriteResponse(w, http.StatusOK, faults)
+	}
+}
+
+// UpdateRouteFaultsFromDashboard - sets the route faults of a service instance from the dashboard form and returns to the dashboard
+func (c *Controller) UpdateRouteFaultsFromDashboard(w http.ResponseWriter, r *http.Request) {
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	var faults sharedModel.RouteFaults
+	for name, value := range map[string]*int{
+		"latency_ms":      &faults.LatencyMs,
+		"latency_percent": &faults.LatencyPercent,
+		"error_status":    &faults.ErrorStatus,
+		"error_percent":   &faults.ErrorPercent,
+		"drop_percent":    &faults.DropPercent,
+		"bandwidth_kbps":  &faults.BandwidthKBps,
+	} {
+		if formValue := r.FormValue(name); formValue != "" {
+			var err error
+			*value, err = strconv.Atoi(formValue)
+			if err != nil {
+				utils.WriteResponse(w, http.StatusBadRequest, name+" must be a whole number")
+				return
+			}
+		}
+	}
+
+	if c.updateRouteFaults(w, r, &faults) {
+		http.Redirect(w, r, "/dashboard/"+utils.ExtractVarsFromRequest(r, "service_instance_guid"), http.StatusSeeOther)
+	}
+}
+
+// updateRouteFaults - validates and stores the route faults of the service instance of a request, writing the response when they cannot be stored
+func (c *Controller) updateRouteFaults(w http.ResponseWriter, r *http.Request, faults *sharedModel.RouteFaults) bool {
+	fmt.Println("Update Service Instance Route Faults...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return false
+	}
+	if reason := validateRouteFaults(*faults); reason != "" {
+		utils.WriteResponse(w, http.StatusBadRequest, reason)
+		return false
+	}
+
+	faults.ServiceInstanceID = instanceID
+	err := sharedUtils.UpdateRouteFaults(c.DB, *faults)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// GetScheduledChanges - returns the latest changes scheduled for a service instance
+func (c *Controller) GetScheduledChanges(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Service Instance Scheduled Changes...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return
+	}
+
+	changes, err := sharedUtils.GetScheduledChanges(c.DB, instanceID, defaultHistoryLimit)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, changes)
+}
+
+// AddScheduledChange - schedules a change to the probability and frequency of a service instance, and its revert when
+// revert_at is given, returning the changes scheduled
+func (c *Controller) AddScheduledChange(w http.ResponseWriter, r *http.Request) {
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	var request model.ScheduleRequest
+	err := utils.ProvisionDataFromRequest(r.Body, &request)
+	if err != nil {
+		utils.WriteResponse(w, http.StatusBadRequest, "scheduled change must be a JSON object")
+		return
+	}
+
+	if changes, ok := c.scheduleChange(w, r, request); ok {
+		utils.WriteResponse(w, http.StatusCreated, changes)
+	}
+}
+
+// AddScheduledChangeFromDashboard - schedules a change from the dashboard form, whose times are in UTC, and returns to the dashboard
+func (c *Controller) AddScheduledChangeFromDashboard(w http.ResponseWriter, r *http.Request) {
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	var request model.ScheduleRequest
+	request.ApplyAt = dashboardTime(r.FormValue("apply_at"))
+	request.RevertAt = dashboardTime(r.FormValue("revert_at"))
+	if formValue := r.FormValue("probability"); formValue != "" {
+		probability, err := strconv.ParseFloat(formValue, 64)
+		if err != nil {
+			utils.WriteResponse(w, http.StatusBadRequest, "probability must be a number")
+			return
+		}
+		request.Probability = &probability
+	}
+	if formValue := r.FormValue("frequency"); formValue != "" {
+		frequency, err := strconv.Atoi(formValue)
+		if err != nil {
+			utils.WriteResponse(w, http.StatusBadRequest, "frequency must be a whole number")
+			return
+		}
+		request.Frequency = &frequency
+	}
+
+	if _, ok := c.scheduleChange(w, r, request); ok {
+		http.Redirect(w, r, "/dashboard/"+utils.ExtractVarsFromRequest(r, "service_instance_guid"), http.StatusSeeOther)
+	}
+}
+
+// scheduleChange - validates and stores a change scheduled for the service instance of a request, and a revert to the values
+// it replaces, recorded when it is applied, when one is asked for, writing the response when they cannot be stored
+func (c *Controller) scheduleChange(w http.ResponseWriter, r *http.Request, request model.ScheduleRequest) ([]sharedModel.ScheduledChange, bool) {
+	fmt.Println("Schedule Service Instance Change...")
+
+	instanceID := utils.ExtractVarsFromRequest(r, "service_instance_guid")
+	instance, err := utils.GetServiceInstance(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+	if instance == (sharedModel.ServiceInstance{}) {
+		w.WriteHeader(http.StatusGone)
+		return nil, false
+	}
+	if reason := validateScheduledChange(&request, time.Now()); reason != "" {
+		utils.WriteResponse(w, http.StatusBadRequest, reason)
+		return nil, false
+	}
+
+	changes := []sharedModel.ScheduledChange{request.ScheduledChange}
+	if request.RevertAt != "" {
+		changes = append(changes, sharedModel.ScheduledChange{ApplyAt: request.RevertAt})
+	}
+
+	for i := range changes {
+		changes[i].ServiceInstanceID = instanceID
+		changes[i].State = sharedModel.ChangePending
+		changes[i].AppliedAt = ""
+		changes[i].RevertsID, changes[i].ReplacedProbability, changes[i].ReplacedFrequency = 0, nil, nil
+		if i > 0 {
+			changes[i].RevertsID = changes[0].ID
+		}
+		changes[i].ID, err = sharedUtils.AddScheduledChange(c.DB, changes[i])
+		if err != nil {
+			fmt.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return nil, false
+		}
+	}
+	return changes, true
+}
+
+// CancelScheduledChange - cancels a pending change scheduled for a service instance
+func (c *Controller) CancelScheduledChange(w http.ResponseWriter, r *http.Request) {
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+	if c.cancelScheduledChange(w, r) {
+		utils.WriteResponse(w, http.StatusOK, "{}")
+	}
+}
+
+// CancelScheduledChangeFromDashboard - cancels a pending change scheduled for a service instance and returns to the dashboard
+func (c *Controller) CancelScheduledChangeFromDashboard(w http.ResponseWriter, r *http.Request) {
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+	if c.cancelScheduledChange(w, r) {
+		http.Redirect(w, r, "/dashboard/"+utils.ExtractVarsFromRequest(r, "service_instance_guid"), http.StatusSeeOther)
+	}
+}
+
+// cancelScheduledChange - cancels the scheduled change of a request, writing the response when it is not pending
+func (c *Controller) cancelScheduledChange(w http.ResponseWriter, r *http.Request) bool {
+	fmt.Println("Cancel Scheduled Change...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return false
+	}
+	changeID, err := strconv.ParseInt(utils.ExtractVarsFromRequest(r, "change_id"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+
+	cancelled, err := sharedUtils.CancelScheduledChange(c.DB, instanceID, changeID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !cancelled {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// GetProfile - returns the weighted chaos actions the processor samples from on every run of a service instance
+func (c *Controller) GetProfile(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Service Instance Profile...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := sharedUtils.GetProfile(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, profile)
+}
+
+// UpdateProfile - sets the weighted chaos actions of a service instance, an empty profile falls back to the configured action
+func (c *Controller) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	var profile sharedModel.Profile
+	err := utils.ProvisionDataFromRequest(r.Body, &profile)
+	if err != nil {
+		utils.WriteResponse(w, http.StatusBadRequest, "profile must be a JSON object")
+		return
+	}
+
+	if c.updateProfile(w, r, &profile) {
+		utils.WriteResponse(w, http.StatusOK, profile)
+	}
+}
+
+// UpdateProfileFromDashboard - sets the profile of a service instance from the dashboard form and returns to the dashboard
+func (c *Controller) UpdateProfileFromDashboard(w http.ResponseWriter, r *http.Request) {
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	var profile sharedModel.Profile
+	var err error
+	profile.Actions, err = sharedUtils.ParseProfile(r.FormValue("profile"))
+	if err != nil {
+		utils.WriteResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if c.updateProfile(w, r, &profile) {
+		http.Redirect(w, r, "/dashboard/"+utils.ExtractVarsFromRequest(r, "service_instance_guid"), http.StatusSeeOther)
+	}
+}
+
+// updateProfile - validates and stores the profile of the service instance of a request, writing the response when it cannot be stored
+func (c *Controller) updateProfile(w http.ResponseWriter, r *http.Request, profile *sharedModel.Profile) bool {
+	fmt.Println("Update Service Instance Profile...")
+
+	instanceID, ok := c.requestInstance(w, r)
+	if !ok {
+		return false
+	}
+	if reason := validateProfile(*profile); reason != "" {
+		utils.WriteResponse(w, http.StatusBadRequest, reason)
+		return false
+	}
+	if reason, ok := c.instanceAppActionConflict(w, instanceID, profileActions(*profile)); !ok || reason != "" {
+		if ok {
+			utils.WriteResponse(w, http.StatusBadRequest, reason)
+		}
+		return false
+	}
+
+	profile.ServiceInstanceID = instanceID
+	if profile.Actions == nil {
+		profile.Actions = []sharedModel.WeightedAction{}
+	}
+	err := sharedUtils.UpdateProfile(c.DB, *profile)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// GetHalt - returns the foundation wide kill switch
+func (c *Controller) GetHalt(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Chaos Halt...")
+
+	halt, err := sharedUtils.GetHalt(c.DB)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, halt)
+}
+
+// HaltChaos - halts chaos across the foundation, recording the operator and their reason
+func (c *Controller) HaltChaos(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Halting Chaos...")
+
+	operator, ok := c.authorizeOperator(w, r)
+	if !ok {
+		return
+	}
+
+	var halt sharedModel.Halt
+	err := utils.ProvisionDataFromRequest(r.Body, &halt)
+	if err != nil {
+		utils.WriteResponse(w, http.StatusBadRequest, "halt must be a JSON object")
+		return
+	}
+	if strings.TrimSpace(halt.Reason) == "" {
+		utils.WriteResponse(w, http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	c.setHalt(w, sharedModel.Halt{Halted: true, SetBy: operator, Reason: halt.Reason, SetAt: time.Now().UTC().Format(time.RFC3339)})
+}
+
+// ResumeChaos - lifts the foundation wide kill switch, recording the operator
+func (c *Controller) ResumeChaos(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Resuming Chaos...")
+
+	operator, ok := c.authorizeOperator(w, r)
+	if !ok {
+		return
+	}
+
+	c.setHalt(w, sharedModel.Halt{Halted: false, SetBy: operator, SetAt: time.Now().UTC().Format(time.RFC3339)})
+}
+
+// setHalt - stores the kill switch and writes it as the response
+func (c *Controller) setHalt(w http.ResponseWriter, halt sharedModel.Halt) {
+	err := sharedUtils.SetHalt(c.DB, halt)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, halt)
+}
+
+// GetCampaigns - lists every game day campaign, newest first
+func (c *Controller) GetCampaigns(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Campaigns...")
+
+	campaigns, err := sharedUtils.GetCampaigns(c.DB)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, campaigns)
+}
+
+// GetCampaign - returns a game day campaign, with its report once it has ended
+func (c *Controller) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Get Campaign...")
+
+	campaign, ok := c.requestCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusOK, campaign)
+}
+
+// AddCampaign - schedules a game day campaign, which the processor starts and ends at the times it is given
+func (c *Controller) AddCampaign(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Add Campaign...")
+
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+
+	var campaign sharedModel.Campaign
+	err := utils.ProvisionDataFromRequest(r.Body, &campaign)
+	if err != nil {
+		utils.WriteResponse(w, http.StatusBadRequest, "campaign must be a JSON object")
+		return
+	}
+	if reason := validateCampaign(&campaign, time.Now()); reason != "" {
+		utils.WriteResponse(w, http.StatusBadRequest, reason)
+		return
+	}
+	for _, instanceID := range campaign.ServiceInstanceIDs {
+		if reason, ok := c.instanceAppActionConflict(w, instanceID, campaign.Actions); !ok || reason != "" {
+			if ok {
+				utils.WriteResponse(w, http.StatusBadRequest, fmt.Sprintf("service instance %s: %s", instanceID, reason))
+			}
+			return
+		}
+	}
+
+	campaign.State = sharedModel.CampaignScheduled
+	campaign.Report = nil
+	campaign.ID, err = sharedUtils.AddCampaign(c.DB, campaign)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteResponse(w, http.StatusCreated, campaign)
+}
+
+// StopCampaign - ends a game day campaign early, or cancels one that has not started, by bringing its end forward to now.
+// The processor then ends and reports on it as on any other campaign.
+func (c *Controller) StopCampaign(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("Stop Campaign...")
+
+	if _, ok := c.authorizeOperator(w, r); !ok {
+		return
+	}
+	campaign, ok := c.requestCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	endAt := time.Now().UTC().Format(time.RFC3339)
+	stopped, err := sharedUtils.StopCampaign(c.DB, campaign.ID, endAt)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !stopped {
+		utils.WriteResponse(w, http.StatusConflict, "campaign has already ended")
+		return
+	}
+
+	campaign.EndAt = endAt
+	utils.WriteResponse(w, http.StatusOK, campaign)
+}
+
+// requestCampaign - loads the campaign of a request, writing the response when it does not exist
+func (c *Controller) requestCampaign(w http.ResponseWriter, r *http.Request) (sharedModel.Campaign, bool) {
+	campaignID, err := strconv.ParseInt(utils.ExtractVarsFromRequest(r, "campaign_id"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return sharedModel.Campaign{}, false
+	}
+
+	campaign, err := sharedUtils.GetCampaign(c.DB, campaignID)
+	if err == sql.ErrNoRows {
+		w.WriteHeader(http.StatusNotFound)
+		return sharedModel.Campaign{}, false
+	}
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return sharedModel.Campaign{}, false
+	}
+	return campaign, true
+}
+
+// authorizeOperator - checks a request carries the operator credentials, returning the operator, writing the response when it does not
+func (c *Controller) authorizeOperator(w http.ResponseWriter, r *http.Request) (string, bool) {
+	operatorUsername, err := GetConfigVariable(c, "OPERATOR_USERNAME", "OperatorUsername")
+	if err != nil {
+		utils.WriteResponse(w, http.StatusForbidden, "operator credentials are not configured")
+		return "", false
+	}
+	operatorPassword, err := GetConfigVariable(c, "OPERATOR_PASSWORD", "OperatorPassword")
+	if err != nil {
+		utils.WriteResponse(w, http.StatusForbidden, "operator credentials are not configured")
+		return "", false
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok ||
+		subtle.ConstantTimeCompare([]byte(username), []byte(operatorUsername)) != 1 ||
+		subtle.ConstantTimeCompare([]byte(password), []byte(operatorPassword)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="chaos-galago"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return "", false
+	}
+	return username, true
+}
+
+// changesGuards - determines if dashboard settings change the action or a safety guard of the saved settings. Unlike the
+// probability and frequency, these decide how much of an app a run can take down, so changing them needs the operator credentials.
+func changesGuards(saved sharedModel.InstanceSettings, settings sharedModel.InstanceSettings) bool {
+	return settings.Action != saved.Action || settings.ActionDelay != saved.ActionDelay || settings.Intensity != saved.Intensity ||
+		settings.KillCount != saved.KillCount || settings.KillPercent != saved.KillPercent || settings.MinHealthy != saved.MinHealthy ||
+		settings.MinInstances != saved.MinInstances || settings.MinHealthyPercent != saved.MinHealthyPercent ||
+		strings.Join(settings.HealthyStates, ",") != strings.Join(saved.HealthyStates, ",")
+}
+
+// requestInstance - loads the service instance of a request, writing the response when it cannot be used
+func (c *Controller) requestInstance(w http.ResponseWriter, r *http.Request) (string, bool) {
+	instanceID := utils.ExtractVarsFromRequest(r, "service_instance_guid")
+	instance, err := utils.GetServiceInstance(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return "", false
+	}
+	if instance == (sharedModel.ServiceInstance{}) {
+		w.WriteHeader(http.StatusGone)
+		return "", false
+	}
+	return instanceID, true
+}
+
+// instanceAppActionConflict - returns why the settings of a service instance stop it from taking any of actions, blank if
+// they do not, only loading the settings when an action disrupts every instance. It writes the response when they cannot be loaded.
+func (c *Controller) instanceAppActionConflict(w http.ResponseWriter, instanceID string, actions []string) (string, bool) {
+	if appAction(actions) == "" {
+		return "", true
+	}
+	settings, err := sharedUtils.GetInstanceSettings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return "", false
+	}
+	return appActionConflict(settings.MinHealthy, actions), true
+}
+
+// UpdateServiceInstance - updates a service instance
+func (c *Controller) UpdateServiceInstance(w http.ResponseWriter, r *http.Request) {
+	var valid = true
+	fmt.Println("Updating Service Instance...")
+
+	instanceID := utils.ExtractVarsFromRequest(r, "service_instance_guid")
+	instance, err := utils.GetServiceInstance(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if instance == (sharedModel.ServiceInstance{}) {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+
+	probability, _ := strconv.ParseFloat(r.FormValue("probability"), 64)
+	frequency, _ := strconv.Atoi(r.FormValue("frequency"))
+
+	settings, err := sharedUtils.GetInstanceSettings(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	saved := settings
+	profile, err := sharedUtils.GetProfile(c.DB, instanceID)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	settings.MTBFMinutes = 0
+	if mtbfMinutes := r.FormValue("mtbf_minutes"); mtbfMinutes != "" {
+		settings.MTBFMinutes, err = strconv.Atoi(mtbfMinutes)
+		if err != nil || settings.MTBFMinutes < 1 {
+			fmt.Printf("\nMean Time Between Failures: %v\n", mtbfMinutes)
+			valid = false
+		}
+	}
+
+	settings.AdaptiveStep = 0
+	if adaptiveStep := r.FormValue("adaptive_step"); adaptiveStep != "" {
+		settings.AdaptiveStep, err = strconv.Atoi(adaptiveStep)
+		if err != nil || !(settings.AdaptiveStep >= 1 && settings.AdaptiveStep <= 100) {
+			fmt.Printf("\nAdaptive Step: %v\n", adaptiveStep)
+			valid = false
+		}
+	}
+
+	settings.MaxRecoverySeconds = 60
+	if maxRecoverySeconds := r.FormValue("max_recovery_seconds"); maxRecoverySeconds != "" {
+		settings.MaxRecoverySeconds, err = strconv.Atoi(maxRecoverySeconds)
+		if err != nil || settings.MaxRecoverySeconds < 1 {
+			fmt.Printf("\nAdaptive Recovery Threshold: %v\n", maxRecoverySeconds)
+			valid = false
+		}
+	}
+
+	settings.Seed = 0
+	if seed := r.FormValue("seed"); seed != "" {
+		settings.Seed, err = strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			fmt.Printf("\nSeed: %v\n", seed)
+			valid = false
+		}
+	}
+
+	settings.Strategy = r.FormValue("strategy")
+	if settings.Strategy == "" {
+		settings.Strategy = sharedModel.StrategyRandom
+	}
+	if !sharedModel.IsStrategy(settings.Strategy) {
+		fmt.Printf("\nStrategy: %v\n", settings.Strategy)
+		valid = false
+	}
+
+	settings.StrategyIndex = 0
+	if strategyIndex := r.FormValue("strategy_index"); strategyIndex != "" {
+		settings.StrategyIndex, err = strconv.Atoi(strategyIndex)
+		if err != nil || settings.StrategyIndex < 0 {
+			fmt.Printf("\nStrategy Index: %v\n", strategyIndex)
+			valid = false
+		}
+	}
+
+	settings.KillCount = 1
+	if killCount := r.FormValue("kill_count"); killCount != "" {
+		settings.KillCount, err = strconv.Atoi(killCount)
+		if err != nil || settings.KillCount < 1 {
+			fmt.Printf("\nKill Count: %v\n", killCount)
+			valid = false
+		}
+	}
+
+	settings.KillPercent = 0
+	if killPercent := r.FormValue("kill_percent"); killPercent != "" {
+		settings.KillPercent, err = strconv.Atoi(killPercent)
+		if err != nil || !(settings.KillPercent >= 0 && settings.KillPercent <= 100) {
+			fmt.Printf("\nKill Percent: %v\n", killPercent)
+			valid = false
+		}
+	}
+
+	settings.MinHealthy = 1
+	if minHealthy := r.FormValue("min_healthy"); minHealthy != "" {
+		settings.MinHealthy, err = strconv.Atoi(minHealthy)
+		if err != nil || settings.MinHealthy < 0 {
+			fmt.Printf("\nMin Healthy: %v\n", minHealthy)
+			valid = false
+		}
+	}
+
+	settings.MinInstances = 1
+	if minInstances := r.FormValue("min_instances"); minInstances != "" {
+		settings.MinInstances, err = strconv.Atoi(minInstances)
+		if err != nil || settings.MinInstances < 0 {
+			fmt.Printf("\nMin Instances: %v\n", minInstances)
+			valid = false
+		}
+	}
+
+	settings.MinHealthyPercent = 100
+	if minHealthyPercent := r.FormValue("min_healthy_percent"); minHealthyPercent != "" {
+		settings.MinHealthyPercent, err = strconv.Atoi(minHealthyPercent)
+		if err != nil || !(settings.MinHealthyPercent >= 0 && settings.MinHealthyPercent <= 100) {
+			fmt.Printf("\nMin Healthy Percent: %v\n", minHealthyPercent)
+			valid = false
+		}
+	}
+
+	settings.HealthyStates = []string{"RUNNING"}
+	if healthyStates := r.FormValue("healthy_states"); strings.TrimSpace(healthyStates) != "" {
+		settings.HealthyStates = sharedUtils.ParseHealthyStates(healthyStates)
+		if len(settings.HealthyStates) != len(strings.Split(healthyStates, ",")) {
+			fmt.Printf("\nHealthy States: %v\n", healthyStates)
+			valid = false
+		}
+	}
+
+	settings.Action = r.FormValue("action")
+	if settings.Action == "" {
+		settings.Action = sharedModel.ActionKill
+	}
+	if !sharedModel.IsAction(settings.Action) {
+		fmt.Printf("\nAction: %v\n", settings.Action)
+		valid = false
+	}
+
+	settings.ActionDelay = 30
+	if actionDelay := r.FormValue("action_delay"); actionDelay != "" {
+		settings.ActionDelay, err = strconv.Atoi(actionDelay)
+		if err != nil || settings.ActionDelay < 0 {
+			fmt.Printf("\nAction Delay: %v\n", actionDelay)
+			valid = false
+		}
+	}
+
+	settings.Intensity = 50
+	if intensity := r.FormValue("intensity"); intensity != "" {
+		settings.Intensity, err = strconv.Atoi(intensity)
+		if err != nil || !(settings.Intensity >= 1 && settings.Intensity <= 100) {
+			fmt.Printf("\nIntensity: %v\n", intensity)
+			valid = false
+		}
+	}
+
+	if reason := appActionConflict(settings.MinHealthy, append([]string{settings.Action}, profileActions(profile)...)); reason != "" {
+		fmt.Printf("\nMin Healthy: %s\n", reason)
+		valid = false
+	}
+
+	if !(probability >= 0 && probability <= 1) {
+		fmt.Printf("\nProbability: %v\n", probability)
+		valid = false
+	}
+
+	if !(frequency >= 1 && frequency <= 60) {
+		fmt.Printf("\nFrequency: %v\n", frequency)
+		valid = false
+	}
+
+	if valid && changesGuards(saved, settings) {
+		if _, ok := c.authorizeOperator(w, r); !ok {
+			return
+		}
+	}
+
+	if valid {
+		err = utils.UpdateServiceInstance(c.DB, instanceID, probability, frequency)
+		if err != nil {
+			fmt.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		err = utils.UpdateInstanceSettings(c.DB, settings)
+		if err != nil {
+			fmt.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		response := fmt.Sprintf(`<html>
+	<head>
+		<link rel="stylesheet" href="/css/bootstrap.min.css">
+		<link rel="stylesheet" href="/css/bootstrap-theme.min.css">
+		<script src="/js/jquery-1.11.3.min.js"></script>
+		<script src="/js/bootstrap.min.js"></script>
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+		<title>Dashboard</title>
+	</head>
+	<body>
+		<div class="container">
+			<h1>New Service Instance Configuration</h1>
+			<p>Probability: %v</p>
+			<p>Frequency: %v</p>
+			<p>Mean Time Between Failures: %s</p>
+			<p>Expected Kills Per Day: %.1f</p>
+			<p>Adaptive Step: %s</p>
+			<p>Adaptive Recovery Threshold: %v seconds</p>
+			<p>Seed: %s</p>
+			<p>Victim Strategy: %s</p>
+			<p>Fixed Instance Index: %v</p>
+			<p>Instances Killed Per Run: %s</p>
+			<p>Minimum Healthy Instances: %v</p>
+			<p>Minimum Instances: %v</p>
+			<p>Minimum Healthy Percentage: %v</p>
+			<p>Healthy States: %s</p>
+			<p>Action: %s</p>
+			<p>Action Delay: %v seconds</p>
+			<p>Resource Exhaustion Intensity: %v</p>
+		</div>
+	</body>
+</html>`, probability, frequency, mtbfDescription(settings.MTBFMinutes), sharedUtils.ExpectedKillsPerDay(probability, frequency, settings.MTBFMinutes),
+			adaptiveDescription(settings.AdaptiveStep), settings.MaxRecoverySeconds, seedDescription(settings.Seed), settings.Strategy, settings.StrategyIndex,
+			killDescription(settings), settings.MinHealthy, settings.MinInstances, settings.MinHealthyPercent, strings.Join(settings.HealthyStates, ","),
+			settings.Action, settings.ActionDelay, settings.Intensity)
+		utils.WriteResponse(w, http.StatusAccepted, response)
+	} else {
+		response := fmt.Sprintf(`<html>
 	<head>
 		<link rel="stylesheet" href="/css/bootstrap.min.css">
 		<link rel="stylesheet" href="/css/bootstrap-theme.min.css">
@@ -412,9 +1740,386 @@ func (c *Controller) UpdateServiceInstance(w http.ResponseWriter, r *http.Reques
 			<h1>Invalid Configuration Request</h1>
 			<p>Probability must be between 0 and 1</p>
 			<p>Frequency must be between 1 and 60</p>
+			<p>Mean Time Between Failures must be a whole number of minutes of at least 1 or left blank</p>
+			<p>Adaptive Step must be between 1 and 100 percentage points or left blank</p>
+			<p>Adaptive Recovery Threshold must be a whole number of seconds of at least 1</p>
+			<p>Seed must be a whole number or left blank</p>
+			<p>Victim Strategy must be one of: %s</p>
+			<p>Fixed Instance Index must be a whole number of at least 0</p>
+			<p>Instances Killed Per Run must be a whole number of at least 1</p>
+			<p>Percentage Of Instances Killed Per Run must be between 0 and 100 or left blank</p>
+			<p>Minimum Healthy Instances must be a whole number of at least 0</p>
+			<p>Minimum Instances must be a whole number of at least 0</p>
+			<p>Minimum Healthy Percentage must be between 0 and 100</p>
+			<p>Healthy States must be a comma separated list of: %s</p>
+			<p>Action must be one of: %s</p>
+			<p>Action Delay must be a whole number of seconds of at least 0</p>
+			<p>Resource Exhaustion Intensity must be between 1 and 100</p>
+			<p>Minimum Healthy Instances must be 0 while the action or profile includes an app wide action: %s</p>
 		</div>
 	</body>
-</html>`
+</html>`, strings.Join(sharedModel.Strategies, ", "), strings.Join(sharedModel.InstanceStates, ", "), strings.Join(sharedModel.Actions, ", "),
+			strings.Join(sharedModel.AppActions, ", "))
 		utils.WriteResponse(w, http.StatusBadRequest, response)
 	}
 }
+
+// seedValue - returns the form value of a seed, blank when chaos is unseeded
+func seedValue(seed int64) string {
+	if seed == 0 {
+		return ""
+	}
+	return strconv.FormatInt(seed, 10)
+}
+
+// seedDescription - describes a seed for display
+func seedDescription(seed int64) string {
+	if seed == 0 {
+		return "random"
+	}
+	return strconv.FormatInt(seed, 10)
+}
+
+// mtbfDescription - describes a mean time between failures for display
+func mtbfDescription(mtbfMinutes int) string {
+	if mtbfMinutes == 0 {
+		return "unused"
+	}
+	return fmt.Sprintf("%d minutes", mtbfMinutes)
+}
+
+// adaptiveDescription - describes the step of adaptive chaos for display
+func adaptiveDescription(adaptiveStep int) string {
+	if adaptiveStep == 0 {
+		return "unused"
+	}
+	return fmt.Sprintf("%d percentage points", adaptiveStep)
+}
+
+// recoveryRows - returns the recovery table rows of the dashboard
+func recoveryRows(recoveryStats []sharedModel.RecoveryStats) string {
+	if len(recoveryStats) == 0 {
+		return "\n\t\t\t\t<tr><td colspan=\"6\">No instances have been killed yet</td></tr>"
+	}
+
+	var rows string
+	for _, stats := range recoveryStats {
+		rows += fmt.Sprintf("\n\t\t\t\t<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%.1f</td><td>%.1f</td></tr>",
+			stats.AppID, stats.Kills, stats.Recovered, stats.TimedOut, stats.MeanSeconds, stats.MaxSeconds)
+	}
+	return rows
+}
+
+// probabilityRows - returns the adaptive probability table rows of the dashboard
+func probabilityRows(changes []sharedModel.ProbabilityChange) string {
+	if len(changes) == 0 {
+		return "\n\t\t\t\t<tr><td colspan=\"5\">The probability has not been adapted yet</td></tr>"
+	}
+
+	var rows string
+	for _, change := range changes {
+		rows += fmt.Sprintf("\n\t\t\t\t<tr><td>%s</td><td>%s</td><td>%s</td><td>%v</td><td>%s</td></tr>",
+			change.ChangedAt, change.AppID, change.Kind, change.Probability, html.EscapeString(change.Reason))
+	}
+	return rows
+}
+
+// scheduledChangeRows - returns the scheduled changes table rows of the dashboard, each pending one with a form to cancel it
+func scheduledChangeRows(instanceID string, changes []sharedModel.ScheduledChange) string {
+	if len(changes) == 0 {
+		return "\n\t\t\t\t<tr><td colspan=\"5\">No changes have been scheduled</td></tr>"
+	}
+
+	var rows string
+	for _, change := range changes {
+		probability, frequency, state, cancel := "unchanged", "unchanged", change.State, ""
+		if change.Probability != nil {
+			probability = fmt.Sprintf("%v", *change.Probability)
+		}
+		if change.Frequency != nil {
+			frequency = strconv.Itoa(*change.Frequency)
+		}
+		if change.RevertsID != 0 {
+			probability = fmt.Sprintf("as before change %d", change.RevertsID)
+			frequency = probability
+		}
+		if change.AppliedAt != "" {
+			state += " at " + change.AppliedAt
+		}
+		if change.State == sharedModel.ChangePending {
+			cancel = fmt.Sprintf("<form action=\"/dashboard/%s/scheduled_changes/%d\" method=\"POST\"><button type=\"submit\" class=\"btn btn-default\">Cancel</button></form>",
+				instanceID, change.ID)
+		}
+		rows += fmt.Sprintf("\n\t\t\t\t<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>", change.ApplyAt, probability, frequency, state, cancel)
+	}
+	return rows
+}
+
+// pausedRows - returns the paused apps table rows of the dashboard, each with a form to resume chaos
+func pausedRows(instanceID string, paused []sharedModel.PausedBinding) string {
+	if len(paused) == 0 {
+		return "\n\t\t\t\t<tr><td colspan=\"4\">Chaos is not paused for any app</td></tr>"
+	}
+
+	var rows string
+	for _, pause := range paused {
+		rows += fmt.Sprintf("\n\t\t\t\t<tr><td>%s</td><td>%s</td><td>%s</td><td><form action=\"/dashboard/%s/paused/%s\" method=\"POST\"><button type=\"submit\" class=\"btn btn-default\">Resume</button></form></td></tr>",
+			pause.AppID, pause.PausedAt, html.EscapeString(pause.Reason), instanceID, pause.ServiceBindingID)
+	}
+	return rows
+}
+
+// routeRows - returns the bound routes table rows of the dashboard
+func routeRows(routeBindings []model.RouteBinding) string {
+	if len(routeBindings) == 0 {
+		return "\n\t\t\t\t<tr><td>No routes are bound to this service instance</td></tr>"
+	}
+
+	var rows string
+	for _, routeBinding := range routeBindings {
+		rows += fmt.Sprintf("\n\t\t\t\t<tr><td>%s</td></tr>", html.EscapeString(routeBinding.Route))
+	}
+	return rows
+}
+
+// haltBanner - returns the dashboard banner shown while chaos is halted across the foundation, blank otherwise
+func haltBanner(halt sharedModel.Halt) string {
+	if !halt.Halted {
+		return ""
+	}
+
+	setAt := ""
+	if halt.SetAt != "" {
+		setAt = " at " + html.EscapeString(halt.SetAt)
+	}
+	return fmt.Sprintf("\n\t\t\t<div class=\"alert alert-danger\" role=\"alert\">Chaos is halted across the foundation by %s%s: %s</div>",
+		html.EscapeString(halt.SetBy), setAt, html.EscapeString(halt.Reason))
+}
+
+// optionalValue - returns the form value of an optional setting, blank when it is unused
+func optionalValue(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+// killDescription - describes how many instances are killed per run for display
+func killDescription(settings sharedModel.InstanceSettings) string {
+	if settings.KillPercent > 0 {
+		return fmt.Sprintf("%d percent of instances", settings.KillPercent)
+	}
+	return strconv.Itoa(settings.KillCount)
+}
+
+// strategyOptions - returns the select options for the victim strategies, marking the current one selected
+func strategyOptions(current string) string {
+	var options string
+	for _, strategy := range sharedModel.Strategies {
+		selected := ""
+		if strategy == current {
+			selected = " selected"
+		}
+		options += fmt.Sprintf("\n\t\t\t\t\t\t<option value=\"%s\"%s>%s</option>", strategy, selected, strategy)
+	}
+	return options
+}
+
+// actionOptions - returns the select options for the chaos actions, marking the current one selected and keeping a current
+// plugin action, which is only listed once chosen
+func actionOptions(current string) string {
+	var options string
+	for _, action := range sharedModel.Actions {
+		selected := ""
+		if action == current {
+			selected = " selected"
+		}
+		options += fmt.Sprintf("\n\t\t\t\t\t\t<option value=\"%s\"%s>%s</option>", action, selected, action)
+	}
+	if sharedModel.IsPluginAction(current) {
+		options += fmt.Sprintf("\n\t\t\t\t\t\t<option value=\"%s\" selected>%s</option>", current, current)
+	}
+	return options
+}
+
+// validateProbe - returns why a probe is invalid, blank when it is valid
+func validateProbe(probe sharedModel.Probe) string {
+	probeURL, err := url.Parse(probe.URL)
+	if err != nil || !(probeURL.Scheme == "http" || probeURL.Scheme == "https") || probeURL.Host == "" {
+		return "url must be an http or https URL"
+	}
+	if !(probe.ExpectedStatus == 0 || (probe.ExpectedStatus >= 100 && probe.ExpectedStatus <= 599)) {
+		return "expected_status must be 0 or between 100 and 599"
+	}
+	if probe.MaxLatencyMs < 0 {
+		return "max_latency_ms must be 0 or greater"
+	}
+	if _, err = regexp.Compile(probe.BodyMatch); err != nil {
+		return "body_match must be a valid regular expression"
+	}
+	return ""
+}
+
+// validateRouteFaults - returns why route faults are invalid, blank when they are valid
+func validateRouteFaults(faults sharedModel.RouteFaults) string {
+	if faults.LatencyMs < 0 {
+		return "latency_ms must be 0 or greater"
+	}
+	if !(faults.LatencyPercent >= 0 && faults.LatencyPercent <= 100) {
+		return "latency_percent must be between 0 and 100"
+	}
+	if !(faults.ErrorStatus == 0 || (faults.ErrorStatus >= 400 && faults.ErrorStatus <= 599)) {
+		return "error_status must be 0 or between 400 and 599"
+	}
+	if !(faults.ErrorPercent >= 0 && faults.ErrorPercent <= 100) {
+		return "error_percent must be between 0 and 100"
+	}
+	if faults.ErrorPercent > 0 && faults.ErrorStatus == 0 {
+		return "error_status is required when error_percent is set"
+	}
+	if !(faults.DropPercent >= 0 && faults.DropPercent <= 100) {
+		return "drop_percent must be between 0 and 100"
+	}
+	if faults.BandwidthKBps < 0 {
+		return "bandwidth_kbps must be 0 or greater"
+	}
+	return ""
+}
+
+// validateProfile - returns why a profile is invalid, blank when it is valid
+func validateProfile(profile sharedModel.Profile) string {
+	seen := make(map[string]bool)
+	for _, weighted := range profile.Actions {
+		if !sharedModel.IsAction(weighted.Action) {
+			return fmt.Sprintf("action must be one of: %s, or %s followed by the name of a plugin; latency cannot be sampled, it is injected "+
+				"through the route faults of the service instance", strings.Join(sharedModel.Actions, ", "), sharedModel.ActionPluginPrefix)
+		}
+		if seen[weighted.Action] {
+			return fmt.Sprintf("%s is in the profile more than once", weighted.Action)
+		}
+		seen[weighted.Action] = true
+		if weighted.Weight < 1 {
+			return fmt.Sprintf("the weight of %s must be 1 or greater", weighted.Action)
+		}
+	}
+	return ""
+}
+
+// appActionConflict - returns why an app keeping a minimum number of healthy instances can never take one of actions, as
+// it disrupts every instance at once, blank if it can take them all
+func appActionConflict(minHealthy int, actions []string) string {
+	action := appAction(actions)
+	if minHealthy == 0 || action == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s takes every instance of the app out of service, so min_healthy must be set to 0 first", action)
+}
+
+// appAction - returns the first of actions disrupting every instance of an app, blank if none does
+func appAction(actions []string) string {
+	for _, action := range actions {
+		if sharedModel.IsAppAction(action) {
+			return action
+		}
+	}
+	return ""
+}
+
+// profileActions - returns the actions of a profile
+func profileActions(profile sharedModel.Profile) []string {
+	var actions []string
+	for _, weighted := range profile.Actions {
+		actions = append(actions, weighted.Action)
+	}
+	return actions
+}
+
+// validateCampaign - returns why a campaign is invalid, blank when it is valid, writing its times in UTC as the processor
+// compares them
+func validateCampaign(campaign *sharedModel.Campaign, now time.Time) string {
+	if strings.TrimSpace(campaign.Name) == "" {
+		return "name is required"
+	}
+	startAt, err := time.Parse(time.RFC3339, campaign.StartAt)
+	if err != nil {
+		return "start_at must be an RFC 3339 time"
+	}
+	endAt, err := time.Parse(time.RFC3339, campaign.EndAt)
+	if err != nil {
+		return "end_at must be an RFC 3339 time"
+	}
+	if !endAt.After(startAt) {
+		return "end_at must be after start_at"
+	}
+	if !endAt.After(now) {
+		return "end_at must be in the future"
+	}
+	if len(campaign.ServiceInstanceIDs) == 0 && len(campaign.SpaceIDs) == 0 {
+		return "service_instance_ids or space_guids must name at least one target"
+	}
+	for _, target := range append(append([]string{}, campaign.ServiceInstanceIDs...), campaign.SpaceIDs...) {
+		if strings.TrimSpace(target) == "" || strings.Contains(target, ",") {
+			return "targets must not be blank or contain commas"
+		}
+	}
+	if !(campaign.Probability > 0 && campaign.Probability <= 1) {
+		return "probability must be greater than 0 and at most 1"
+	}
+	if !(campaign.Frequency >= 1 && campaign.Frequency <= 60) {
+		return "frequency must be between 1 and 60"
+	}
+	for _, action := range campaign.Actions {
+		if !sharedModel.IsAction(action) {
+			return fmt.Sprintf("action must be one of: %s, or %s followed by the name of a plugin", strings.Join(sharedModel.Actions, ", "), sharedModel.ActionPluginPrefix)
+		}
+	}
+
+	campaign.StartAt = startAt.UTC().Format(time.RFC3339)
+	campaign.EndAt = endAt.UTC().Format(time.RFC3339)
+	return ""
+}
+
+// validateScheduledChange - returns why a scheduled change is invalid, blank when it is valid, writing its times in UTC as
+// the processor compares them
+func validateScheduledChange(request *model.ScheduleRequest, now time.Time) string {
+	applyAt, err := time.Parse(time.RFC3339, request.ApplyAt)
+	if err != nil {
+		return "apply_at must be an RFC 3339 time"
+	}
+	if !applyAt.After(now) {
+		return "apply_at must be in the future"
+	}
+	if request.Probability == nil && request.Frequency == nil {
+		return "probability or frequency must be given"
+	}
+	if request.Probability != nil && !(*request.Probability >= 0 && *request.Probability <= 1) {
+		return "probability must be between 0 and 1"
+	}
+	if request.Frequency != nil && !(*request.Frequency >= 1 && *request.Frequency <= 60) {
+		return "frequency must be between 1 and 60"
+	}
+	request.ApplyAt = applyAt.UTC().Format(time.RFC3339)
+
+	if request.RevertAt == "" {
+		return ""
+	}
+	revertAt, err := time.Parse(time.RFC3339, request.RevertAt)
+	if err != nil {
+		return "revert_at must be an RFC 3339 time"
+	}
+	if !revertAt.After(applyAt) {
+		return "revert_at must be after apply_at"
+	}
+	request.RevertAt = revertAt.UTC().Format(time.RFC3339)
+	return ""
+}
+
+// dashboardTime - returns a time entered on the dashboard, which is in UTC to the minute, as an RFC 3339 time, leaving
+// anything else as it is
+func dashboardTime(value string) string {
+	entered, err := time.Parse("2006-01-02T15:04", value)
+	if err != nil {
+		return value
+	}
+	return entered.Format(time.RFC3339)
+}
@@ -53,6 +53,71 @@ func CreateServer(dbConnFunc DBConn, controllerCreator ControllerCreator) (*Serv
 		fmt.Println(err)
 		return nil, err
 	}
+	err = sharedUtils.SetupInstanceSettingsDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	err = sharedUtils.SetupEventDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	err = sharedUtils.SetupProbeDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	err = sharedUtils.SetupPauseDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	err = sharedUtils.SetupHaltDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	err = utils.SetupRouteBindingDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	err = sharedUtils.SetupRouteFaultsDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	err = sharedUtils.SetupProfileDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	err = sharedUtils.SetupProbabilityChangeDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	err = sharedUtils.SetupCampaignDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	err = sharedUtils.SetupScheduledChangeDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	err = sharedUtils.SetupReplayDB(db)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
 
 	conf := config.GetConfig()
 	controller := controllerCreator(db, conf)
@@ -74,6 +139,32 @@ func (s *Server) Start() *mux.Router {
 	router.HandleFunc("/v2/service_instances/{service_instance_guid}/service_bindings/{service_binding_guid}", s.Controller.UnBind).Methods("DELETE")
 	router.HandleFunc("/dashboard/{service_instance_guid}", s.Controller.GetDashboard).Methods("GET")
 	router.HandleFunc("/dashboard/{service_instance_guid}", s.Controller.UpdateServiceInstance).Methods("POST")
+	router.HandleFunc("/dashboard/{service_instance_guid}/paused/{service_binding_guid}", s.Controller.ResumeBindingFromDashboard).Methods("POST")
+	router.HandleFunc("/dashboard/{service_instance_guid}/route_faults", s.Controller.UpdateRouteFaultsFromDashboard).Methods("POST")
+	router.HandleFunc("/dashboard/{service_instance_guid}/profile", s.Controller.UpdateProfileFromDashboard).Methods("POST")
+	router.HandleFunc("/dashboard/{service_instance_guid}/scheduled_changes", s.Controller.AddScheduledChangeFromDashboard).Methods("POST")
+	router.HandleFunc("/dashboard/{service_instance_guid}/scheduled_changes/{change_id}", s.Controller.CancelScheduledChangeFromDashboard).Methods("POST")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/history", s.Controller.GetHistory).Methods("GET")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/replays", s.Controller.ReplayEvent).Methods("POST")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/probes", s.Controller.GetProbes).Methods("GET")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/probes", s.Controller.AddProbe).Methods("POST")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/probes/{probe_id}", s.Controller.RemoveProbe).Methods("DELETE")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/paused", s.Controller.GetPausedBindings).Methods("GET")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/paused/{service_binding_guid}", s.Controller.ResumeBinding).Methods("DELETE")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/route_faults", s.Controller.GetRouteFaults).Methods("GET")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/route_faults", s.Controller.UpdateRouteFaults).Methods("PUT")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/profile", s.Controller.GetProfile).Methods("GET")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/profile", s.Controller.UpdateProfile).Methods("PUT")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/scheduled_changes", s.Controller.GetScheduledChanges).Methods("GET")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/scheduled_changes", s.Controller.AddScheduledChange).Methods("POST")
+	router.HandleFunc("/api/service_instances/{service_instance_guid}/scheduled_changes/{change_id}", s.Controller.CancelScheduledChange).Methods("DELETE")
+	router.HandleFunc("/api/halt", s.Controller.GetHalt).Methods("GET")
+	router.HandleFunc("/api/halt", s.Controller.HaltChaos).Methods("PUT")
+	router.HandleFunc("/api/halt", s.Controller.ResumeChaos).Methods("DELETE")
+	router.HandleFunc("/api/campaigns", s.Controller.GetCampaigns).Methods("GET")
+	router.HandleFunc("/api/campaigns", s.Controller.AddCampaign).Methods("POST")
+	router.HandleFunc("/api/campaigns/{campaign_id}", s.Controller.GetCampaign).Methods("GET")
+	router.HandleFunc("/api/campaigns/{campaign_id}", s.Controller.StopCampaign).Methods("DELETE")
 	router.PathPrefix("/").Handler(http.FileServer(http.Dir("./web_server/resources/")))
 
 	return router
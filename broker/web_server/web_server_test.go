@@ -3,16 +3,20 @@ package webServer_test
 import (
 	"bytes"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/FidelityInternational/chaos-galago/broker/config"
+	"github.com/FidelityInternational/chaos-galago/broker/model"
 	webs "github.com/FidelityInternational/chaos-galago/broker/web_server"
 	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
 	"github.com/gorilla/mux"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -50,6 +54,18 @@ func mockFailedBindingDBConn(driverName string, connectionString string) (*sql.D
 	return db, err
 }
 
+func mockFailedSettingsDBConn(driverName string, connectionString string) (*sql.DB, error) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+		os.Exit(1)
+	}
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS service_instances.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS instance_settings.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+	return db, err
+}
+
 func mockDBConn(driverName string, connectionString string) (*sql.DB, error) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -58,6 +74,25 @@ func mockDBConn(driverName string, connectionString string) (*sql.DB, error) {
 	}
 	mock.ExpectExec("CREATE TABLE IF NOT EXISTS service_instances.*").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectExec("CREATE TABLE IF NOT EXISTS service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS instance_settings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	for range []string{"strategy", "strategyIndex", "killCount", "killPercent", "minHealthy", "minInstances", "minHealthyPercent", "healthyStates", "action", "actionDelay", "intensity", "mtbfMinutes", "adaptiveStep", "maxRecoverySeconds"} {
+		mock.ExpectQuery("SELECT COUNT.* FROM information_schema.COLUMNS.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	}
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_events.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	for range []string{"recovery", "recoverySeconds", "hypothesis", "orgID", "spaceID", "beforeState", "afterState"} {
+		mock.ExpectQuery("SELECT COUNT.* FROM information_schema.COLUMNS.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	}
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_probes.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS paused_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_halt.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS route_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS route_faults.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_profiles.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS probability_changes.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS campaigns.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS campaign_apps.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS scheduled_changes.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_replays.*").WillReturnResult(sqlmock.NewResult(1, 1))
 	return db, err
 }
 
@@ -125,6 +160,13 @@ var _ = Describe("Server", func() {
 							Expect(server).To(BeAssignableToTypeOf(&webs.Server{}))
 						})
 					})
+					Context("and SetupInstanceSettingsDB raises an error", func() {
+						It("returns an error", func() {
+							_, err := webs.CreateServer(mockFailedSettingsDBConn, mockCreateController)
+							Expect(err).ToNot(BeNil())
+							Expect(err.Error()).To(MatchRegexp("An error has occured: Database Create Error"))
+						})
+					})
 					Context("and SetupBindingDB raises an error", func() {
 						It("returns an error", func() {
 							_, err := webs.CreateServer(mockFailedBindingDBConn, mockCreateController)
@@ -261,6 +303,14 @@ var _ = Describe("Contoller", func() {
 
 					Context("and the service instance can be deleted", func() {
 						BeforeEach(func() {
+							mock.ExpectExec("DELETE FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM chaos_probes WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM paused_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM probability_changes WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM scheduled_changes WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
 							mock.ExpectExec("DELETE FROM service_instances WHERE id=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
 						})
 
@@ -272,6 +322,14 @@ var _ = Describe("Contoller", func() {
 
 					Context("and the service instance cannot be deleted", func() {
 						BeforeEach(func() {
+							mock.ExpectExec("DELETE FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM chaos_probes WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM paused_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM probability_changes WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("DELETE FROM scheduled_changes WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
 							mock.ExpectExec("DELETE FROM service_instances WHERE id=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
 						})
 
@@ -345,6 +403,8 @@ var _ = Describe("Contoller", func() {
 				Context("and the service binding can be deleted", func() {
 					BeforeEach(func() {
 						mock.ExpectExec("DELETE FROM service_bindings WHERE id=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("DELETE FROM route_bindings WHERE id=").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 0))
+						mock.ExpectExec("DELETE FROM paused_bindings WHERE serviceInstanceID=(.+) AND serviceBindingID=").WithArgs("1", "1").WillReturnResult(sqlmock.NewResult(0, 0))
 					})
 
 					It("Returns a 200", func() {
@@ -353,6 +413,29 @@ var _ = Describe("Contoller", func() {
 					})
 				})
 
+				Context("and the paused binding cannot be deleted", func() {
+					BeforeEach(func() {
+						mock.ExpectExec("DELETE FROM service_bindings WHERE id=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("DELETE FROM route_bindings WHERE id=").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 0))
+						mock.ExpectExec("DELETE FROM paused_bindings").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					})
+
+					It("returns an error 500", func() {
+						Expect(mockRecorder.Code).To(Equal(500))
+					})
+				})
+
+				Context("and the route binding cannot be deleted", func() {
+					BeforeEach(func() {
+						mock.ExpectExec("DELETE FROM service_bindings WHERE id=").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("DELETE FROM route_bindings WHERE id=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					})
+
+					It("returns an error 500", func() {
+						Expect(mockRecorder.Code).To(Equal(500))
+					})
+				})
+
 				Context("and the service binding cannot be deleted", func() {
 					BeforeEach(func() {
 						mock.ExpectExec("DELETE FROM service_bindings WHERE id=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
@@ -391,10 +474,15 @@ var _ = Describe("Contoller", func() {
 
 	Describe("#GetDashboard", func() {
 		var (
-			response     string
-			controller   *webs.Controller
-			req          *http.Request
-			mockRecorder *httptest.ResponseRecorder
+			response               string
+			haltColumns            = []string{"halted", "setBy", "reason", "setAt"}
+			routeColumns           = []string{"id", "serviceInstanceID", "route"}
+			changeColumns          = []string{"id", "serviceInstanceID", "appID", "kind", "probability", "reason", "changedAt"}
+			scheduledChangeColumns = []string{"id", "serviceInstanceID", "applyAt", "probability", "frequency", "state", "appliedAt", "revertsID", "replacedProbability", "replacedFrequency"}
+			faultColumns           = []string{"latencyMs", "latencyPercent", "errorStatus", "errorPercent", "dropPercent", "bandwidthKBps"}
+			controller             *webs.Controller
+			req                    *http.Request
+			mockRecorder           *httptest.ResponseRecorder
 		)
 
 		BeforeEach(func() {
@@ -412,6 +500,8 @@ var _ = Describe("Contoller", func() {
 	<body>
 		<div class="container">
 			<h1>Change Service Instance Config</h1>
+			<p>Effective Probability: 0.15</p>
+			<p>Expected Kills Per Day: 43.2</p>
 			<form action="/dashboard/1" method="POST">
 				<fieldset class="form-group">
 					<label for "probability">Probability</label>
@@ -421,10 +511,177 @@ var _ = Describe("Contoller", func() {
 					<label for "frequency">Frequency</label>
 					<input type="number" min="1" max="60" class="form-control" id="frequency" name="frequency" placeholder="5">
 				</fieldset>
+				<fieldset class="form-group">
+					<label for "mtbf_minutes">Mean Time Between Failures (minutes)</label>
+					<input type="number" min="1" class="form-control" id="mtbf_minutes" name="mtbf_minutes" value="" placeholder="unused">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "adaptive_step">Adaptive Step (percentage points)</label>
+					<input type="number" step="1" min="1" max="100" class="form-control" id="adaptive_step" name="adaptive_step" value="5" placeholder="unused">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "max_recovery_seconds">Adaptive Recovery Threshold (seconds)</label>
+					<input type="number" step="1" min="1" class="form-control" id="max_recovery_seconds" name="max_recovery_seconds" value="90">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "seed">Seed</label>
+					<input type="number" step="1" class="form-control" id="seed" name="seed" value="42" placeholder="random">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "strategy">Victim Strategy</label>
+					<select class="form-control" id="strategy" name="strategy">
+						<option value="random">random</option>
+						<option value="oldest">oldest</option>
+						<option value="busiest-cpu">busiest-cpu</option>
+						<option value="busiest-memory">busiest-memory</option>
+						<option value="fixed" selected>fixed</option>
+						<option value="not-first">not-first</option>
+					</select>
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "strategy_index">Fixed Instance Index</label>
+					<input type="number" step="1" min="0" class="form-control" id="strategy_index" name="strategy_index" value="2">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "kill_count">Instances Killed Per Run</label>
+					<input type="number" step="1" min="1" class="form-control" id="kill_count" name="kill_count" value="2">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "kill_percent">Percentage Of Instances Killed Per Run</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="kill_percent" name="kill_percent" value="" placeholder="unused">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "min_healthy">Minimum Healthy Instances</label>
+					<input type="number" step="1" min="0" class="form-control" id="min_healthy" name="min_healthy" value="1">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "min_instances">Minimum Instances</label>
+					<input type="number" step="1" min="0" class="form-control" id="min_instances" name="min_instances" value="2">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "min_healthy_percent">Minimum Healthy Percentage</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="min_healthy_percent" name="min_healthy_percent" value="50">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "healthy_states">Healthy States</label>
+					<input type="text" class="form-control" id="healthy_states" name="healthy_states" value="RUNNING,STARTING" placeholder="RUNNING">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "action">Action</label>
+					<select class="form-control" id="action" name="action">
+						<option value="kill">kill</option>
+						<option value="restart">restart</option>
+						<option value="stop-start" selected>stop-start</option>
+						<option value="restage">restage</option>
+						<option value="scale">scale</option>
+						<option value="cell-outage">cell-outage</option>
+						<option value="cpu-burn">cpu-burn</option>
+						<option value="memory-pressure">memory-pressure</option>
+						<option value="disk-fill">disk-fill</option>
+						<option value="network-partition">network-partition</option>
+						<option value="route-unmap">route-unmap</option>
+						<option value="egress-blackhole">egress-blackhole</option>
+					</select>
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "action_delay">Action Delay (seconds)</label>
+					<input type="number" step="1" min="0" class="form-control" id="action_delay" name="action_delay" value="45">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "intensity">Resource Exhaustion Intensity (percent)</label>
+					<input type="number" step="1" min="1" max="100" class="form-control" id="intensity" name="intensity" value="80">
+				</fieldset>
 				<div class="form-group row">
 					<button type="submit" class="btn btn-primary">Submit</button>
 				</div>
 			</form>
+			<h2>Scheduled Changes</h2>
+			<table class="table">
+				<tr><th>Apply At (UTC)</th><th>Probability</th><th>Frequency</th><th>State</th><th></th></tr>
+				<tr><td>2014-11-21T17:00:00Z</td><td>as before change 4</td><td>as before change 4</td><td>pending</td><td><form action="/dashboard/1/scheduled_changes/5" method="POST"><button type="submit" class="btn btn-default">Cancel</button></form></td></tr>
+				<tr><td>2014-11-17T09:00:00Z</td><td>0.4</td><td>unchanged</td><td>applied at 2014-11-17T09:00:10Z</td><td></td></tr>
+			</table>
+			<form action="/dashboard/1/scheduled_changes" method="POST">
+				<fieldset class="form-group">
+					<label for "apply_at">Apply At (UTC)</label>
+					<input type="datetime-local" class="form-control" id="apply_at" name="apply_at">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "scheduled_probability">Probability</label>
+					<input type="number" step="0.01" min="0" max="1" class="form-control" id="scheduled_probability" name="probability" placeholder="unchanged">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "scheduled_frequency">Frequency</label>
+					<input type="number" min="1" max="60" class="form-control" id="scheduled_frequency" name="frequency" placeholder="unchanged">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "revert_at">Revert At (UTC)</label>
+					<input type="datetime-local" class="form-control" id="revert_at" name="revert_at">
+				</fieldset>
+				<div class="form-group row">
+					<button type="submit" class="btn btn-primary">Schedule Change</button>
+				</div>
+			</form>
+			<h2>Action Profile</h2>
+			<form action="/dashboard/1/profile" method="POST">
+				<fieldset class="form-group">
+					<label for "profile">Weighted Actions (action:weight, comma separated)</label>
+					<input type="text" class="form-control" id="profile" name="profile" value="kill:70,restart:30" placeholder="unused, e.g. kill:70,restart:20,network-partition:10">
+				</fieldset>
+				<div class="form-group row">
+					<button type="submit" class="btn btn-primary">Save Profile</button>
+				</div>
+			</form>
+			<h2>Recovery</h2>
+			<table class="table">
+				<tr><th>App</th><th>Kills</th><th>Recovered</th><th>Timed Out</th><th>MTTR (seconds)</th><th>Slowest Recovery (seconds)</th></tr>
+				<tr><td>app-1</td><td>3</td><td>2</td><td>1</td><td>15.5</td><td>20.0</td></tr>
+			</table>
+			<p><a href="/api/service_instances/1/history">Full history</a></p>
+			<h2>Adaptive Probability</h2>
+			<table class="table">
+				<tr><th>Changed At</th><th>App</th><th>Change</th><th>Probability</th><th>Reason</th></tr>
+				<tr><td>2014-11-12T10:31:20Z</td><td>app-1</td><td>raised</td><td>0.15</td><td>instance 0 recovered in 12 seconds</td></tr>
+			</table>
+			<h2>Paused Apps</h2>
+			<table class="table">
+				<tr><th>App</th><th>Paused At</th><th>Reason</th><th></th></tr>
+				<tr><td>app-1</td><td>2014-11-12T10:31:20Z</td><td>app crashed 3 times since the kill, the threshold is 3</td><td><form action="/dashboard/1/paused/2" method="POST"><button type="submit" class="btn btn-default">Resume</button></form></td></tr>
+			</table>
+			<h2>Route Faults</h2>
+			<table class="table">
+				<tr><th>Bound Route</th></tr>
+				<tr><td>www.example.com/shop</td></tr>
+			</table>
+			<form action="/dashboard/1/route_faults" method="POST">
+				<fieldset class="form-group">
+					<label for "latency_ms">Latency (milliseconds)</label>
+					<input type="number" step="1" min="0" class="form-control" id="latency_ms" name="latency_ms" value="250">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "latency_percent">Percentage Of Requests Delayed</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="latency_percent" name="latency_percent" value="10">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "error_status">Error Status Code</label>
+					<input type="number" step="1" min="400" max="599" class="form-control" id="error_status" name="error_status" value="503" placeholder="unused">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "error_percent">Percentage Of Requests Failed</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="error_percent" name="error_percent" value="5">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "drop_percent">Percentage Of Connections Dropped</label>
+					<input type="number" step="1" min="0" max="100" class="form-control" id="drop_percent" name="drop_percent" value="0">
+				</fieldset>
+				<fieldset class="form-group">
+					<label for "bandwidth_kbps">Bandwidth Limit (KB per second)</label>
+					<input type="number" step="1" min="0" class="form-control" id="bandwidth_kbps" name="bandwidth_kbps" value="" placeholder="unlimited">
+				</fieldset>
+				<div class="form-group row">
+					<button type="submit" class="btn btn-primary">Save Route Faults</button>
+				</div>
+			</form>
 		</div>
 	</body>
 </html>
@@ -442,11 +699,215 @@ var _ = Describe("Contoller", func() {
 					rows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
 						AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
 					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(rows)
+					settingsRows := sqlmock.NewRows(sharedUtils.InstanceSettingsColumns).AddRow("1", 42, "fixed", 2, 2, nil, 1, 2, 50, "RUNNING,STARTING", "stop-start", 45, 80, nil, 5, 90)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(settingsRows)
 				})
 
-				It("returns the form", func() {
-					Expect(mockRecorder.Code).To(Equal(200))
-					Expect(mockRecorder.Body.String()).To(Equal(response))
+				Context("and the recovery statistics can be fetched", func() {
+					BeforeEach(func() {
+						recoveryRows := sqlmock.NewRows([]string{"appID", "kills", "recovered", "timedOut", "mean", "max"}).AddRow("app-1", 3, 2, 1, 15.5, 20.0)
+						mock.ExpectQuery("^SELECT appID, COUNT(.+) FROM chaos_events").WillReturnRows(recoveryRows)
+					})
+
+					Context("and the paused apps can be fetched", func() {
+						BeforeEach(func() {
+							pausedRows := sqlmock.NewRows([]string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}).
+								AddRow("2", "1", "app-1", "2014-11-12T10:31:20Z", "app crashed 3 times since the kill, the threshold is 3")
+							mock.ExpectQuery("^SELECT (.+) FROM paused_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(pausedRows)
+						})
+
+						Context("and chaos is not halted", func() {
+							BeforeEach(func() {
+								mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1").WillReturnRows(sqlmock.NewRows(haltColumns))
+							})
+
+							Context("and the route faults can be fetched", func() {
+								BeforeEach(func() {
+									mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").
+										WillReturnRows(sqlmock.NewRows(routeColumns).AddRow("3", "1", "www.example.com/shop"))
+									mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").
+										WillReturnRows(sqlmock.NewRows(faultColumns).AddRow(250, 10, 503, 5, 0, 0))
+									mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").
+										WillReturnRows(sqlmock.NewRows([]string{"actions"}).AddRow("kill:70,restart:30"))
+									mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 20).
+										WillReturnRows(sqlmock.NewRows(changeColumns).AddRow(3, "1", "app-1", "raised", 0.15, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z"))
+									mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE serviceInstanceID=").WithArgs("1", 20).
+										WillReturnRows(sqlmock.NewRows(scheduledChangeColumns).
+											AddRow(5, "1", "2014-11-21T17:00:00Z", nil, nil, "pending", nil, 4, nil, nil).
+											AddRow(4, "1", "2014-11-17T09:00:00Z", 0.4, nil, "applied", "2014-11-17T09:00:10Z", 0, 0.2, nil))
+								})
+
+								It("returns the form", func() {
+									Expect(mockRecorder.Code).To(Equal(200))
+									Expect(mockRecorder.Body.String()).To(Equal(response))
+								})
+							})
+
+							Context("and no routes are bound", func() {
+								BeforeEach(func() {
+									mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(routeColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(faultColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"actions"}))
+									mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(changeColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+								})
+
+								It("says so in the route faults table", func() {
+									Expect(mockRecorder.Code).To(Equal(200))
+									Expect(mockRecorder.Body.String()).To(ContainSubstring(`<tr><td>No routes are bound to this service instance</td></tr>`))
+								})
+
+								It("says so in the scheduled changes table", func() {
+									Expect(mockRecorder.Body.String()).To(ContainSubstring(`<tr><td colspan="5">No changes have been scheduled</td></tr>`))
+								})
+							})
+
+							Context("and the scheduled changes cannot be fetched", func() {
+								BeforeEach(func() {
+									mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(routeColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(faultColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"actions"}))
+									mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(changeColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+								})
+
+								It("returns an error 500", func() {
+									Expect(mockRecorder.Code).To(Equal(500))
+								})
+							})
+
+							Context("and the probability changes cannot be fetched", func() {
+								BeforeEach(func() {
+									mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(routeColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(faultColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"actions"}))
+									mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+								})
+
+								It("returns an error 500", func() {
+									Expect(mockRecorder.Code).To(Equal(500))
+								})
+							})
+
+							Context("and the profile cannot be fetched", func() {
+								BeforeEach(func() {
+									mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(routeColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(faultColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+								})
+
+								It("returns an error 500", func() {
+									Expect(mockRecorder.Code).To(Equal(500))
+								})
+							})
+
+							Context("and the route faults cannot be fetched", func() {
+								BeforeEach(func() {
+									mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(routeColumns))
+									mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+								})
+
+								It("returns an error 500", func() {
+									Expect(mockRecorder.Code).To(Equal(500))
+								})
+							})
+						})
+
+						Context("and the bound routes cannot be fetched", func() {
+							BeforeEach(func() {
+								mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1").WillReturnRows(sqlmock.NewRows(haltColumns))
+								mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+							})
+
+							It("returns an error 500", func() {
+								Expect(mockRecorder.Code).To(Equal(500))
+							})
+						})
+
+						Context("and chaos is halted across the foundation", func() {
+							BeforeEach(func() {
+								mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1").
+									WillReturnRows(sqlmock.NewRows(haltColumns).AddRow(true, "operator", "<b>50% of cells</b> down", "2014-11-12T10:31:20Z"))
+								mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(routeColumns))
+								mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(faultColumns))
+								mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"actions"}))
+								mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(changeColumns))
+								mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+							})
+
+							It("shows who halted it, when and why in a banner", func() {
+								Expect(mockRecorder.Code).To(Equal(200))
+								Expect(mockRecorder.Body.String()).To(ContainSubstring(`<div class="container">
+			<div class="alert alert-danger" role="alert">Chaos is halted across the foundation by operator at 2014-11-12T10:31:20Z: &lt;b&gt;50% of cells&lt;/b&gt; down</div>
+			<h1>Change Service Instance Config</h1>`))
+							})
+						})
+
+						Context("and the halt cannot be fetched", func() {
+							BeforeEach(func() {
+								mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+							})
+
+							It("returns an error 500", func() {
+								Expect(mockRecorder.Code).To(Equal(500))
+							})
+						})
+					})
+
+					Context("and no apps are paused", func() {
+						BeforeEach(func() {
+							mock.ExpectQuery("^SELECT (.+) FROM paused_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}))
+							mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1").WillReturnRows(sqlmock.NewRows(haltColumns))
+							mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(routeColumns))
+							mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(faultColumns))
+							mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"actions"}))
+							mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(changeColumns))
+							mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+						})
+
+						It("says so in the paused apps table", func() {
+							Expect(mockRecorder.Code).To(Equal(200))
+							Expect(mockRecorder.Body.String()).To(ContainSubstring(`<tr><td colspan="4">Chaos is not paused for any app</td></tr>`))
+						})
+					})
+
+					Context("and the paused apps cannot be fetched", func() {
+						BeforeEach(func() {
+							mock.ExpectQuery("^SELECT (.+) FROM paused_bindings WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+						})
+
+						It("returns an error 500", func() {
+							Expect(mockRecorder.Code).To(Equal(500))
+						})
+					})
+				})
+
+				Context("and no instances have been killed", func() {
+					BeforeEach(func() {
+						mock.ExpectQuery("^SELECT appID, COUNT(.+) FROM chaos_events").WillReturnRows(sqlmock.NewRows([]string{"appID", "kills", "recovered", "timedOut", "mean", "max"}))
+						mock.ExpectQuery("^SELECT (.+) FROM paused_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}))
+						mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1").WillReturnRows(sqlmock.NewRows(haltColumns))
+						mock.ExpectQuery("^SELECT (.+) FROM route_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(routeColumns))
+						mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(faultColumns))
+						mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"actions"}))
+						mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(changeColumns))
+						mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE serviceInstanceID=").WithArgs("1", 20).WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+					})
+
+					It("says so in the recovery table", func() {
+						Expect(mockRecorder.Code).To(Equal(200))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring(`<tr><td colspan="6">No instances have been killed yet</td></tr>`))
+					})
+				})
+
+				Context("and the recovery statistics cannot be fetched", func() {
+					BeforeEach(func() {
+						mock.ExpectQuery("^SELECT appID, COUNT(.+) FROM chaos_events").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					})
+
+					It("returns an error 500", func() {
+						Expect(mockRecorder.Code).To(Equal(500))
+					})
 				})
 			})
 
@@ -486,92 +947,1942 @@ var _ = Describe("Contoller", func() {
 		})
 	})
 
-	Describe("#UpdateServiceInstance", func() {
+	Describe("#GetHistory", func() {
 		var (
-			response     string
 			controller   *webs.Controller
 			req          *http.Request
 			mockRecorder *httptest.ResponseRecorder
-			probability  = "0.4"
-			frequency    = "10"
+			query        string
 		)
 
 		BeforeEach(func() {
 			controller = webs.CreateController(db, conf)
 			mockRecorder = httptest.NewRecorder()
+			query = ""
+		})
+
+		JustBeforeEach(func() {
+			req, _ = http.NewRequest("GET", "http://example.com/api/service_instances/1/history"+query, nil)
+			Router(controller).ServeHTTP(mockRecorder, req)
+		})
+
+		Context("When the service instance cannot be fetched", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+			})
+
+			It("returns an error 500", func() {
+				Expect(mockRecorder.Code).To(Equal(500))
+			})
+		})
+
+		Context("When the service instance does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}))
+			})
+
+			It("returns a 410", func() {
+				Expect(mockRecorder.Code).To(Equal(410))
+			})
 		})
 
 		Context("When the service instance exists", func() {
-			JustBeforeEach(func() {
-				req, _ = http.NewRequest("POST", "http://example.com/dashboard/1", strings.NewReader(fmt.Sprintf("probability=%s&frequency=%s", probability, frequency)))
-				req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
-				Router(controller).ServeHTTP(mockRecorder, req)
+			BeforeEach(func() {
+				rows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+					AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(rows)
 			})
 
-			Context("and the service instance cannot be fetched", func() {
+			Context("and the history can be fetched", func() {
 				BeforeEach(func() {
-					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					recoveryRows := sqlmock.NewRows([]string{"appID", "kills", "recovered", "timedOut", "mean", "max"}).AddRow("app-1", 1, 1, 0, 12.5, 12.5)
+					mock.ExpectQuery("^SELECT appID, COUNT(.+) FROM chaos_events").WillReturnRows(recoveryRows)
+					eventRows := sqlmock.NewRows(sharedUtils.EventColumns).
+						AddRow(1, "app-1", "1", "2", "2014-11-12T10:31:20Z", 42, "kill", "0", "succeeded", "", "recovered", 12.5, "held", "org-1", "space-1", "STARTED with 2 instances (2 RUNNING)", "STARTED with 2 instances (1 STARTING, 1 RUNNING)")
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=").WithArgs("1", 100).WillReturnRows(eventRows)
 				})
 
-				It("returns an error 500", func() {
-					Expect(mockRecorder.Code).To(Equal(500))
+				It("returns the events and recovery statistics", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var history model.History
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &history)).To(BeNil())
+					Expect(history.Recovery).To(Equal([]sharedModel.RecoveryStats{{AppID: "app-1", Kills: 1, Recovered: 1, MeanSeconds: 12.5, MaxSeconds: 12.5}}))
+					Expect(history.Events).To(HaveLen(1))
+					Expect(history.Events[0].Recovery).To(Equal("recovered"))
+					Expect(history.Events[0].RecoverySeconds).To(Equal(12.5))
+					Expect(history.Events[0].Hypothesis).To(Equal("held"))
+					Expect(history.Events[0].Before).To(Equal("STARTED with 2 instances (2 RUNNING)"))
 				})
 			})
 
-			Context("when the service instance does not exist in the database", func() {
+			Context("and a limit is given", func() {
 				BeforeEach(func() {
-					rows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"})
-					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(rows)
+					query = "?limit=5"
+					mock.ExpectQuery("^SELECT appID, COUNT(.+) FROM chaos_events").WillReturnRows(sqlmock.NewRows([]string{"appID", "kills", "recovered", "timedOut", "mean", "max"}))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=").WithArgs("1", 5).WillReturnRows(sqlmock.NewRows(sharedUtils.EventColumns))
 				})
 
-				It("returns a 410", func() {
-					Expect(mockRecorder.Code).To(Equal(410))
+				It("returns at most that many events", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
 				})
 			})
 
-			Context("and the service instance can be fetched", func() {
+			Context("and the limit is invalid", func() {
 				BeforeEach(func() {
-					rows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
-						AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
-					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(rows)
+					query = "?limit=0"
 				})
-				Context("When probability is invalid", func() {
-					BeforeEach(func() {
-						response = `<html>
-	<head>
-		<link rel="stylesheet" href="/css/bootstrap.min.css">
-		<link rel="stylesheet" href="/css/bootstrap-theme.min.css">
-		<script src="/js/jquery-1.11.3.min.js"></script>
-		<script src="/js/bootstrap.min.js"></script>
-		<meta name="viewport" content="width=device-width, initial-scale=1">
-		<title>Dashboard</title>
-	</head>
-	<body>
-		<div class="container">
-			<h1>Invalid Configuration Request</h1>
-			<p>Probability must be between 0 and 1</p>
-			<p>Frequency must be between 1 and 60</p>
-		</div>
-	</body>
-</html>`
-						probability = "3"
-					})
 
-					AfterEach(func() {
-						probability = "0.4"
-					})
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+				})
+			})
 
-					It("returns an error page", func() {
-						Expect(mockRecorder.Code).To(Equal(400))
-						Expect(mockRecorder.Body.String()).To(Equal(response))
-					})
+			Context("and the events cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT appID, COUNT(.+) FROM chaos_events").WillReturnRows(sqlmock.NewRows([]string{"appID", "kills", "recovered", "timedOut", "mean", "max"}))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
 				})
 
-				Context("When frequency is invalid", func() {
-					BeforeEach(func() {
-						response = `<html>
-	<head>
-		<link rel="stylesheet" href="/css/bootstrap.min.css">
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+		})
+	})
+
+	Describe("#ReplayEvent", func() {
+		var (
+			password     string
+			body         string
+			controller   *webs.Controller
+			req          *http.Request
+			mockRecorder *httptest.ResponseRecorder
+			instanceRows sqlmock.Rows
+		)
+
+		BeforeEach(func() {
+			conf = &config.Config{OperatorUsername: "operator", OperatorPassword: "secret"}
+			password = "secret"
+			body = `{"event_id": 7}`
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+			instanceRows = sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+				AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
+		})
+
+		JustBeforeEach(func() {
+			req, _ = http.NewRequest("POST", "http://example.com/api/service_instances/1/replays", strings.NewReader(body))
+			req.SetBasicAuth("operator", password)
+			Router(controller).ServeHTTP(mockRecorder, req)
+		})
+
+		Context("When the operator credentials are wrong", func() {
+			BeforeEach(func() {
+				password = "guess"
+			})
+
+			It("returns a 401 without requesting the replay", func() {
+				Expect(mockRecorder.Code).To(Equal(401))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the event was recorded", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=\\? AND id=\\?$").WithArgs("1", 7).WillReturnRows(sqlmock.NewRows(sharedUtils.EventColumns).
+					AddRow(7, "app-1", "1", "2", "2014-11-12T10:31:20Z", -4237981, "kill", "3", "succeeded", "", "recovered", 12.5, "held", nil, nil, nil, nil))
+				mock.ExpectExec("INSERT INTO chaos_replays").WithArgs("2", "1", "app-1", 7, -4237981, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			})
+
+			It("requests a replay from the seed recorded with the event", func() {
+				Expect(mockRecorder.Code).To(Equal(202))
+				var replay sharedModel.Replay
+				Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &replay)).To(BeNil())
+				Expect(replay.ServiceBindingID).To(Equal("2"))
+				Expect(replay.EventID).To(Equal(int64(7)))
+				Expect(replay.Seed).To(Equal(int64(-4237981)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the service instance has no such event", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=").WithArgs("1", 7).WillReturnRows(sqlmock.NewRows(sharedUtils.EventColumns))
+			})
+
+			It("returns a 404", func() {
+				Expect(mockRecorder.Code).To(Equal(404))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the event recorded no seed", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=").WithArgs("1", 7).WillReturnRows(sqlmock.NewRows(sharedUtils.EventColumns).
+					AddRow(7, "app-1", "1", "2", "2014-11-12T10:31:20Z", 0, "kill", "3", "succeeded", "", "", 0, "", nil, nil, nil, nil))
+			})
+
+			It("returns a 400", func() {
+				Expect(mockRecorder.Code).To(Equal(400))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the body is not JSON", func() {
+			BeforeEach(func() {
+				body = "event 7"
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+			})
+
+			It("returns a 400", func() {
+				Expect(mockRecorder.Code).To(Equal(400))
+			})
+		})
+
+		Context("When the service instance does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}))
+			})
+
+			It("returns a 410", func() {
+				Expect(mockRecorder.Code).To(Equal(410))
+			})
+		})
+
+		Context("When the replay cannot be stored", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=").WithArgs("1", 7).WillReturnRows(sqlmock.NewRows(sharedUtils.EventColumns).
+					AddRow(7, "app-1", "1", "2", "2014-11-12T10:31:20Z", -4237981, "kill", "3", "succeeded", "", "", 0, "", nil, nil, nil, nil))
+				mock.ExpectExec("INSERT INTO chaos_replays").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+			})
+
+			It("returns an error 500", func() {
+				Expect(mockRecorder.Code).To(Equal(500))
+			})
+		})
+	})
+
+	Describe("Probes", func() {
+		var (
+			password     string
+			controller   *webs.Controller
+			req          *http.Request
+			mockRecorder *httptest.ResponseRecorder
+			instanceRows sqlmock.Rows
+			probeColumns = []string{"id", "serviceInstanceID", "url", "expectedStatus", "maxLatencyMs", "bodyMatch"}
+		)
+
+		BeforeEach(func() {
+			conf = &config.Config{OperatorUsername: "operator", OperatorPassword: "secret"}
+			password = "secret"
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+			instanceRows = sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+				AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
+		})
+
+		Describe("#GetProbes", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("GET", "http://example.com/api/service_instances/1/probes", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the probes can be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_probes WHERE serviceInstanceID=").WithArgs("1").
+						WillReturnRows(sqlmock.NewRows(probeColumns).AddRow(3, "1", "https://app.example.com/health", 200, 500, "UP"))
+				})
+
+				It("returns the probes", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var probes []sharedModel.Probe
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &probes)).To(BeNil())
+					Expect(probes).To(Equal([]sharedModel.Probe{{ID: 3, ServiceInstanceID: "1", URL: "https://app.example.com/health", ExpectedStatus: 200, MaxLatencyMs: 500, BodyMatch: "UP"}}))
+				})
+			})
+
+			Context("When the probes cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_probes WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the service instance does not exist", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}))
+				})
+
+				It("returns a 410", func() {
+					Expect(mockRecorder.Code).To(Equal(410))
+				})
+			})
+
+			Context("When the service instance cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+		})
+
+		Describe("#AddProbe", func() {
+			var body string
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/api/service_instances/1/probes", strings.NewReader(body))
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without adding the probe", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the probe is valid", func() {
+				BeforeEach(func() {
+					body = `{"url": "https://app.example.com/health", "expected_status": 200, "max_latency_ms": 500, "body_match": "UP"}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO chaos_probes").WithArgs("1", "https://app.example.com/health", 200, 500, "UP").WillReturnResult(sqlmock.NewResult(4, 1))
+				})
+
+				It("returns the created probe", func() {
+					Expect(mockRecorder.Code).To(Equal(201))
+					var probe sharedModel.Probe
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &probe)).To(BeNil())
+					Expect(probe).To(Equal(sharedModel.Probe{ID: 4, ServiceInstanceID: "1", URL: "https://app.example.com/health", ExpectedStatus: 200, MaxLatencyMs: 500, BodyMatch: "UP"}))
+				})
+			})
+
+			Context("When the probe cannot be saved", func() {
+				BeforeEach(func() {
+					body = `{"url": "https://app.example.com/health"}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO chaos_probes").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			invalidProbes := map[string]string{
+				"not JSON":             "probe must be a JSON object",
+				`{"url": "ftp://app"}`: "url must be an http or https URL",
+				`{"url": "/health"}`:   "url must be an http or https URL",
+				`{"url": "http://app", "expected_status": 42}`: "expected_status must be 0 or between 100 and 599",
+				`{"url": "http://app", "max_latency_ms": -1}`:  "max_latency_ms must be 0 or greater",
+				`{"url": "http://app", "body_match": "("}`:     "body_match must be a valid regular expression",
+			}
+			for invalidBody, reason := range invalidProbes {
+				invalidBody, reason := invalidBody, reason
+
+				Context("When the probe is "+invalidBody, func() {
+					BeforeEach(func() {
+						body = invalidBody
+						mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					})
+
+					It("returns a 400", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(Equal(reason))
+					})
+				})
+			}
+		})
+
+		Describe("#RemoveProbe", func() {
+			var probeID string
+
+			BeforeEach(func() {
+				probeID = "3"
+			})
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "http://example.com/api/service_instances/1/probes/"+probeID, nil)
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without removing the probe", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the probe exists", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("DELETE FROM chaos_probes WHERE serviceInstanceID=(.+) AND id=").WithArgs("1", 3).WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("returns a 200", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					Expect(mockRecorder.Body.String()).To(Equal("{}"))
+				})
+			})
+
+			Context("When the probe does not exist", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("DELETE FROM chaos_probes WHERE serviceInstanceID=(.+) AND id=").WithArgs("1", 3).WillReturnResult(sqlmock.NewResult(0, 0))
+				})
+
+				It("returns a 404", func() {
+					Expect(mockRecorder.Code).To(Equal(404))
+				})
+			})
+
+			Context("When the probe id is not a number", func() {
+				BeforeEach(func() {
+					probeID = "health"
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				})
+
+				It("returns a 404", func() {
+					Expect(mockRecorder.Code).To(Equal(404))
+				})
+			})
+
+			Context("When the probe cannot be deleted", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("DELETE FROM chaos_probes WHERE serviceInstanceID=(.+) AND id=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+		})
+	})
+
+	Describe("Paused bindings", func() {
+		var (
+			controller   *webs.Controller
+			req          *http.Request
+			mockRecorder *httptest.ResponseRecorder
+			instanceRows sqlmock.Rows
+			pauseColumns = []string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}
+		)
+
+		BeforeEach(func() {
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+			instanceRows = sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+				AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
+		})
+
+		Describe("#GetPausedBindings", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("GET", "http://example.com/api/service_instances/1/paused", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the paused bindings can be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM paused_bindings WHERE serviceInstanceID=").WithArgs("1").
+						WillReturnRows(sqlmock.NewRows(pauseColumns).AddRow("2", "1", "app-1", "2014-11-12T10:31:20Z", "instance 0 is crash looping"))
+				})
+
+				It("returns the paused bindings", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var paused []sharedModel.PausedBinding
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &paused)).To(BeNil())
+					Expect(paused).To(Equal([]sharedModel.PausedBinding{{ServiceBindingID: "2", ServiceInstanceID: "1", AppID: "app-1", PausedAt: "2014-11-12T10:31:20Z", Reason: "instance 0 is crash looping"}}))
+				})
+			})
+
+			Context("When the paused bindings cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM paused_bindings WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the service instance does not exist", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}))
+				})
+
+				It("returns a 410", func() {
+					Expect(mockRecorder.Code).To(Equal(410))
+				})
+			})
+		})
+
+		Describe("#ResumeBinding", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "http://example.com/api/service_instances/1/paused/2", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the binding is paused", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("DELETE FROM paused_bindings WHERE serviceInstanceID=(.+) AND serviceBindingID=").WithArgs("1", "2").WillReturnResult(sqlmock.NewResult(0, 1))
+				})
+
+				It("returns a 200", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					Expect(mockRecorder.Body.String()).To(Equal("{}"))
+				})
+			})
+
+			Context("When the binding is not paused", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("DELETE FROM paused_bindings").WithArgs("1", "2").WillReturnResult(sqlmock.NewResult(0, 0))
+				})
+
+				It("returns a 404", func() {
+					Expect(mockRecorder.Code).To(Equal(404))
+				})
+			})
+
+			Context("When the binding cannot be resumed", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("DELETE FROM paused_bindings").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the service instance cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+		})
+
+		Describe("#ResumeBindingFromDashboard", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/dashboard/1/paused/2", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the binding is paused", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("DELETE FROM paused_bindings").WithArgs("1", "2").WillReturnResult(sqlmock.NewResult(0, 1))
+				})
+
+				It("redirects to the dashboard", func() {
+					Expect(mockRecorder.Code).To(Equal(303))
+					Expect(mockRecorder.Header().Get("Location")).To(Equal("/dashboard/1"))
+				})
+			})
+
+			Context("When the binding is not paused", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("DELETE FROM paused_bindings").WithArgs("1", "2").WillReturnResult(sqlmock.NewResult(0, 0))
+				})
+
+				It("returns a 404", func() {
+					Expect(mockRecorder.Code).To(Equal(404))
+				})
+			})
+		})
+	})
+
+	Describe("Route faults", func() {
+		var (
+			password     string
+			controller   *webs.Controller
+			req          *http.Request
+			mockRecorder *httptest.ResponseRecorder
+			instanceRows sqlmock.Rows
+			faultColumns = []string{"latencyMs", "latencyPercent", "errorStatus", "errorPercent", "dropPercent", "bandwidthKBps"}
+		)
+
+		BeforeEach(func() {
+			conf = &config.Config{OperatorUsername: "operator", OperatorPassword: "secret"}
+			password = "secret"
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+			instanceRows = sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+				AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
+		})
+
+		Describe("#GetRouteFaults", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("GET", "http://example.com/api/service_instances/1/route_faults", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the route faults can be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").
+						WillReturnRows(sqlmock.NewRows(faultColumns).AddRow(250, 10, 503, 5, 1, 64))
+				})
+
+				It("returns the route faults", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var faults sharedModel.RouteFaults
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &faults)).To(BeNil())
+					Expect(faults).To(Equal(sharedModel.RouteFaults{ServiceInstanceID: "1", LatencyMs: 250, LatencyPercent: 10, ErrorStatus: 503, ErrorPercent: 5, DropPercent: 1, BandwidthKBps: 64}))
+				})
+			})
+
+			Context("When the route faults cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the service instance does not exist", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}))
+				})
+
+				It("returns a 410", func() {
+					Expect(mockRecorder.Code).To(Equal(410))
+				})
+			})
+		})
+
+		Describe("#UpdateRouteFaults", func() {
+			var body string
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "http://example.com/api/service_instances/1/route_faults", strings.NewReader(body))
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without setting the route faults", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the route faults are valid", func() {
+				BeforeEach(func() {
+					body = `{"latency_ms": 250, "latency_percent": 10, "error_status": 503, "error_percent": 5}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO route_faults").WithArgs("1", 250, 10, 503, 5, 0, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("returns the route faults", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var faults sharedModel.RouteFaults
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &faults)).To(BeNil())
+					Expect(faults).To(Equal(sharedModel.RouteFaults{ServiceInstanceID: "1", LatencyMs: 250, LatencyPercent: 10, ErrorStatus: 503, ErrorPercent: 5}))
+				})
+			})
+
+			Context("When the route faults cannot be saved", func() {
+				BeforeEach(func() {
+					body = `{}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO route_faults").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the route faults are not JSON", func() {
+				BeforeEach(func() {
+					body = "not JSON"
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("route faults must be a JSON object"))
+				})
+			})
+
+			invalidFaults := map[string]string{
+				`{"latency_ms": -1}`:       "latency_ms must be 0 or greater",
+				`{"latency_percent": 101}`: "latency_percent must be between 0 and 100",
+				`{"error_status": 200}`:    "error_status must be 0 or between 400 and 599",
+				`{"error_percent": 10}`:    "error_status is required when error_percent is set",
+				`{"drop_percent": -5}`:     "drop_percent must be between 0 and 100",
+				`{"bandwidth_kbps": -64}`:  "bandwidth_kbps must be 0 or greater",
+			}
+			for invalidBody, reason := range invalidFaults {
+				invalidBody, reason := invalidBody, reason
+
+				Context("When the route faults are "+invalidBody, func() {
+					BeforeEach(func() {
+						body = invalidBody
+						mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					})
+
+					It("returns a 400", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(Equal(reason))
+					})
+				})
+			}
+		})
+
+		Describe("#UpdateRouteFaultsFromDashboard", func() {
+			var form url.Values
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/dashboard/1/route_faults", strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without setting the route faults", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the route faults are valid", func() {
+				BeforeEach(func() {
+					form = url.Values{"latency_ms": {"250"}, "latency_percent": {"10"}, "drop_percent": {"1"}, "bandwidth_kbps": {""}}
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO route_faults").WithArgs("1", 250, 10, 0, 0, 1, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("redirects to the dashboard", func() {
+					Expect(mockRecorder.Code).To(Equal(303))
+					Expect(mockRecorder.Header().Get("Location")).To(Equal("/dashboard/1"))
+				})
+			})
+
+			Context("When a value is not a number", func() {
+				BeforeEach(func() {
+					form = url.Values{"latency_ms": {"slow"}}
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("latency_ms must be a whole number"))
+				})
+			})
+
+			Context("When the route faults are invalid", func() {
+				BeforeEach(func() {
+					form = url.Values{"drop_percent": {"150"}}
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("drop_percent must be between 0 and 100"))
+				})
+			})
+		})
+	})
+
+	Describe("Profile", func() {
+		var (
+			password       string
+			controller     *webs.Controller
+			req            *http.Request
+			mockRecorder   *httptest.ResponseRecorder
+			instanceRows   sqlmock.Rows
+			profileColumns = []string{"actions"}
+		)
+
+		BeforeEach(func() {
+			conf = &config.Config{OperatorUsername: "operator", OperatorPassword: "secret"}
+			password = "secret"
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+			instanceRows = sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+				AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
+		})
+
+		Describe("#GetProfile", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("GET", "http://example.com/api/service_instances/1/profile", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the profile can be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").
+						WillReturnRows(sqlmock.NewRows(profileColumns).AddRow("kill:70,restart:30"))
+				})
+
+				It("returns the profile", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var profile sharedModel.Profile
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &profile)).To(BeNil())
+					Expect(profile).To(Equal(sharedModel.Profile{ServiceInstanceID: "1", Actions: []sharedModel.WeightedAction{{Action: "kill", Weight: 70}, {Action: "restart", Weight: 30}}}))
+				})
+			})
+
+			Context("When the profile cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the service instance does not exist", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}))
+				})
+
+				It("returns a 410", func() {
+					Expect(mockRecorder.Code).To(Equal(410))
+				})
+			})
+		})
+
+		Describe("#UpdateProfile", func() {
+			var body string
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "http://example.com/api/service_instances/1/profile", strings.NewReader(body))
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without setting the profile", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the profile is valid", func() {
+				BeforeEach(func() {
+					body = `{"actions": [{"action": "kill", "weight": 70}, {"action": "network-partition", "weight": 30}]}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO chaos_profiles").WithArgs("1", "kill:70,network-partition:30").WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("returns the profile", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var profile sharedModel.Profile
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &profile)).To(BeNil())
+					Expect(profile.ServiceInstanceID).To(Equal("1"))
+					Expect(profile.Actions).To(HaveLen(2))
+				})
+			})
+
+			Context("When the profile has a plugin action", func() {
+				BeforeEach(func() {
+					body = `{"actions": [{"action": "kill", "weight": 9}, {"action": "plugin:feature-flag", "weight": 1}]}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO chaos_profiles").WithArgs("1", "kill:9,plugin:feature-flag:1").WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("returns the profile", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+				})
+			})
+
+			Context("When the profile is empty", func() {
+				BeforeEach(func() {
+					body = `{}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO chaos_profiles").WithArgs("1", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("clears the profile", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					Expect(mockRecorder.Body.String()).To(Equal(`{"service_instance_id":"1","actions":[]}`))
+				})
+			})
+
+			Context("When the profile cannot be saved", func() {
+				BeforeEach(func() {
+					body = `{}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO chaos_profiles").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the profile has an action disrupting every instance and healthy instances are kept", func() {
+				BeforeEach(func() {
+					body = `{"actions": [{"action": "kill", "weight": 70}, {"action": "stop-start", "weight": 30}]}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+				})
+
+				It("returns a 400 without setting the profile", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("stop-start takes every instance of the app out of service, so min_healthy must be set to 0 first"))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the profile is not JSON", func() {
+				BeforeEach(func() {
+					body = "not JSON"
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("profile must be a JSON object"))
+				})
+			})
+
+			invalidProfiles := map[string]string{
+				`{"actions": [{"action": "explode", "weight": 1}]}`:                               "action must be one of: " + strings.Join(sharedModel.Actions, ", ") + ", or plugin: followed by the name of a plugin; latency cannot be sampled, it is injected through the route faults of the service instance",
+				`{"actions": [{"action": "kill", "weight": 0}]}`:                                  "the weight of kill must be 1 or greater",
+				`{"actions": [{"action": "kill", "weight": 1}, {"action": "kill", "weight": 2}]}`: "kill is in the profile more than once",
+			}
+			for invalidBody, reason := range invalidProfiles {
+				invalidBody, reason := invalidBody, reason
+
+				Context("When the profile is "+invalidBody, func() {
+					BeforeEach(func() {
+						body = invalidBody
+						mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					})
+
+					It("returns a 400", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(Equal(reason))
+					})
+				})
+			}
+		})
+
+		Describe("#UpdateProfileFromDashboard", func() {
+			var form url.Values
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/dashboard/1/profile", strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without setting the profile", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the profile is valid", func() {
+				BeforeEach(func() {
+					form = url.Values{"profile": {"kill:70, restart:30"}}
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").
+						WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns).AddRow("1", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+					mock.ExpectExec("INSERT INTO chaos_profiles").WithArgs("1", "kill:70,restart:30").WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("redirects to the dashboard", func() {
+					Expect(mockRecorder.Code).To(Equal(303))
+					Expect(mockRecorder.Header().Get("Location")).To(Equal("/dashboard/1"))
+				})
+			})
+
+			Context("When the profile cannot be parsed", func() {
+				BeforeEach(func() {
+					form = url.Values{"profile": {"kill"}}
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("kill is not an action:weight pair"))
+				})
+			})
+
+			Context("When the profile is invalid", func() {
+				BeforeEach(func() {
+					form = url.Values{"profile": {"kill:0"}}
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("the weight of kill must be 1 or greater"))
+				})
+			})
+		})
+	})
+
+	Describe("Scheduled changes", func() {
+		var (
+			password               string
+			controller             *webs.Controller
+			req                    *http.Request
+			mockRecorder           *httptest.ResponseRecorder
+			instanceRows           sqlmock.Rows
+			scheduledChangeColumns = []string{"id", "serviceInstanceID", "applyAt", "probability", "frequency", "state", "appliedAt", "revertsID", "replacedProbability", "replacedFrequency"}
+		)
+
+		BeforeEach(func() {
+			conf = &config.Config{OperatorUsername: "operator", OperatorPassword: "secret"}
+			password = "secret"
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+			instanceRows = sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+				AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
+		})
+
+		Describe("#GetScheduledChanges", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("GET", "http://example.com/api/service_instances/1/scheduled_changes", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the scheduled changes can be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE serviceInstanceID=").WithArgs("1", 100).
+						WillReturnRows(sqlmock.NewRows(scheduledChangeColumns).AddRow(4, "1", "2114-11-17T09:00:00Z", 0.4, nil, "pending", nil, 0, nil, nil))
+				})
+
+				It("returns the changes", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					Expect(mockRecorder.Body.String()).To(Equal(`[{"id":4,"service_instance_id":"1","apply_at":"2114-11-17T09:00:00Z","probability":0.4,"state":"pending"}]`))
+				})
+			})
+
+			Context("When the scheduled changes cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+		})
+
+		Describe("#AddScheduledChange", func() {
+			var body string
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/api/service_instances/1/scheduled_changes", strings.NewReader(body))
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without scheduling the change", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the change is valid", func() {
+				BeforeEach(func() {
+					body = `{"apply_at":"2114-11-17T10:00:00+01:00","probability":0.4,"state":"applied"}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO scheduled_changes").WithArgs("1", "2114-11-17T09:00:00Z", 0.4, nil, "pending", 0).WillReturnResult(sqlmock.NewResult(4, 1))
+				})
+
+				It("schedules the change in UTC", func() {
+					Expect(mockRecorder.Code).To(Equal(201))
+					var changes []sharedModel.ScheduledChange
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &changes)).To(BeNil())
+					Expect(changes).To(HaveLen(1))
+					Expect(changes[0].ID).To(Equal(int64(4)))
+					Expect(changes[0].State).To(Equal(sharedModel.ChangePending))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the change is to be reverted", func() {
+				BeforeEach(func() {
+					body = `{"apply_at":"2114-11-17T09:00:00Z","probability":0.4,"frequency":2,"revert_at":"2114-11-21T17:00:00Z"}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO scheduled_changes").WithArgs("1", "2114-11-17T09:00:00Z", 0.4, 2, "pending", 0).WillReturnResult(sqlmock.NewResult(4, 1))
+					mock.ExpectExec("INSERT INTO scheduled_changes").WithArgs("1", "2114-11-21T17:00:00Z", nil, nil, "pending", 4).WillReturnResult(sqlmock.NewResult(5, 1))
+				})
+
+				It("also schedules a revert of the change", func() {
+					Expect(mockRecorder.Code).To(Equal(201))
+					var changes []sharedModel.ScheduledChange
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &changes)).To(BeNil())
+					Expect(changes).To(HaveLen(2))
+					Expect(changes[1].ID).To(Equal(int64(5)))
+					Expect(changes[1].RevertsID).To(Equal(int64(4)))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the change is in the past", func() {
+				BeforeEach(func() {
+					body = `{"apply_at":"2014-11-17T09:00:00Z","probability":0.4}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("apply_at must be in the future"))
+				})
+			})
+
+			Context("When the change changes nothing", func() {
+				BeforeEach(func() {
+					body = `{"apply_at":"2114-11-17T09:00:00Z"}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("probability or frequency must be given"))
+				})
+			})
+
+			Context("When the revert is before the change", func() {
+				BeforeEach(func() {
+					body = `{"apply_at":"2114-11-17T09:00:00Z","frequency":2,"revert_at":"2114-11-17T08:00:00Z"}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("revert_at must be after apply_at"))
+				})
+			})
+
+			Context("When the body is not a JSON object", func() {
+				BeforeEach(func() {
+					body = "monday"
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("scheduled change must be a JSON object"))
+				})
+			})
+
+			Context("When the service instance does not exist", func() {
+				BeforeEach(func() {
+					body = `{"apply_at":"2114-11-17T09:00:00Z","probability":0.4}`
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").
+						WillReturnRows(sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}))
+				})
+
+				It("returns a 410", func() {
+					Expect(mockRecorder.Code).To(Equal(410))
+				})
+			})
+		})
+
+		Describe("#AddScheduledChangeFromDashboard", func() {
+			var form url.Values
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/dashboard/1/scheduled_changes", strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without scheduling the change", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the change is valid", func() {
+				BeforeEach(func() {
+					form = url.Values{"apply_at": {"2114-11-17T09:00"}, "probability": {"0.4"}, "frequency": {""}, "revert_at": {""}}
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("INSERT INTO scheduled_changes").WithArgs("1", "2114-11-17T09:00:00Z", 0.4, nil, "pending", 0).WillReturnResult(sqlmock.NewResult(4, 1))
+				})
+
+				It("redirects to the dashboard", func() {
+					Expect(mockRecorder.Code).To(Equal(303))
+					Expect(mockRecorder.Header().Get("Location")).To(Equal("/dashboard/1"))
+				})
+			})
+
+			Context("When a value is not a number", func() {
+				BeforeEach(func() {
+					form = url.Values{"apply_at": {"2114-11-17T09:00"}, "frequency": {"often"}}
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("frequency must be a whole number"))
+				})
+			})
+		})
+
+		Describe("#CancelScheduledChange", func() {
+			var changeID string
+
+			BeforeEach(func() {
+				changeID = "4"
+			})
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "http://example.com/api/service_instances/1/scheduled_changes/"+changeID, nil)
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without cancelling the change", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the change is pending", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("UPDATE scheduled_changes SET state=").WithArgs("cancelled", "1", 4, "pending").WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec("UPDATE scheduled_changes SET state=\\? WHERE serviceInstanceID=\\? AND revertsID=").WithArgs("cancelled", "1", 4, "pending").WillReturnResult(sqlmock.NewResult(0, 1))
+				})
+
+				It("returns a 200", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					Expect(mockRecorder.Body.String()).To(Equal("{}"))
+				})
+			})
+
+			Context("When the change is not pending", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("UPDATE scheduled_changes SET state=").WithArgs("cancelled", "1", 4, "pending").WillReturnResult(sqlmock.NewResult(0, 0))
+				})
+
+				It("returns a 404", func() {
+					Expect(mockRecorder.Code).To(Equal(404))
+				})
+			})
+
+			Context("When the change id is not a number", func() {
+				BeforeEach(func() {
+					changeID = "monday"
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+				})
+
+				It("returns a 404", func() {
+					Expect(mockRecorder.Code).To(Equal(404))
+				})
+			})
+
+			Context("When the change cannot be cancelled", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("UPDATE scheduled_changes").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+		})
+
+		Describe("#CancelScheduledChangeFromDashboard", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/dashboard/1/scheduled_changes/4", nil)
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without cancelling the change", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the change is pending", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(instanceRows)
+					mock.ExpectExec("UPDATE scheduled_changes SET state=").WithArgs("cancelled", "1", 4, "pending").WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec("UPDATE scheduled_changes SET state=\\? WHERE serviceInstanceID=\\? AND revertsID=").WithArgs("cancelled", "1", 4, "pending").WillReturnResult(sqlmock.NewResult(0, 1))
+				})
+
+				It("redirects to the dashboard", func() {
+					Expect(mockRecorder.Code).To(Equal(303))
+					Expect(mockRecorder.Header().Get("Location")).To(Equal("/dashboard/1"))
+				})
+			})
+		})
+	})
+
+	Describe("Halt", func() {
+		var (
+			controller   *webs.Controller
+			req          *http.Request
+			mockRecorder *httptest.ResponseRecorder
+			haltColumns  = []string{"halted", "setBy", "reason", "setAt"}
+		)
+
+		BeforeEach(func() {
+			conf = &config.Config{OperatorUsername: "operator", OperatorPassword: "secret"}
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+		})
+
+		Describe("#GetHalt", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("GET", "http://example.com/api/halt", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When chaos is halted", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1").
+						WillReturnRows(sqlmock.NewRows(haltColumns).AddRow(true, "operator", "change freeze", "2014-11-12T10:31:20Z"))
+				})
+
+				It("returns the halt", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var halt sharedModel.Halt
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &halt)).To(BeNil())
+					Expect(halt).To(Equal(sharedModel.Halt{Halted: true, SetBy: "operator", Reason: "change freeze", SetAt: "2014-11-12T10:31:20Z"}))
+				})
+			})
+
+			Context("When the halt cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+		})
+
+		Describe("#HaltChaos", func() {
+			var (
+				body     string
+				username string
+				password string
+			)
+
+			BeforeEach(func() {
+				body = `{"reason":"50% of cells are down"}`
+				username = "operator"
+				password = "secret"
+			})
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "http://example.com/api/halt", strings.NewReader(body))
+				req.SetBasicAuth(username, password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator gives a reason", func() {
+				BeforeEach(func() {
+					mock.ExpectExec("INSERT INTO chaos_halt").WithArgs(true, "operator", "50% of cells are down", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("halts chaos, recording who halted it and why", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var halt sharedModel.Halt
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &halt)).To(BeNil())
+					Expect(halt.Halted).To(BeTrue())
+					Expect(halt.SetBy).To(Equal("operator"))
+					Expect(halt.Reason).To(Equal("50% of cells are down"))
+					Expect(halt.SetAt).ToNot(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the halt cannot be stored", func() {
+				BeforeEach(func() {
+					mock.ExpectExec("INSERT INTO chaos_halt").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When no reason is given", func() {
+				BeforeEach(func() {
+					body = `{"reason":" "}`
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("reason is required"))
+				})
+			})
+
+			Context("When the body is not JSON", func() {
+				BeforeEach(func() {
+					body = "halt"
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+				})
+			})
+
+			Context("When the credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without halting chaos", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mockRecorder.Header().Get("WWW-Authenticate")).To(Equal(`Basic realm="chaos-galago"`))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When no operator credentials are configured", func() {
+				BeforeEach(func() {
+					conf.OperatorUsername = ""
+					conf.OperatorPassword = ""
+				})
+
+				It("returns a 403", func() {
+					Expect(mockRecorder.Code).To(Equal(403))
+				})
+			})
+		})
+
+		Describe("#ResumeChaos", func() {
+			var password string
+
+			BeforeEach(func() {
+				password = "secret"
+			})
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "http://example.com/api/halt", nil)
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the operator is authorized", func() {
+				BeforeEach(func() {
+					mock.ExpectExec("INSERT INTO chaos_halt").WithArgs(false, "operator", "", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("lifts the halt, recording who lifted it", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var halt sharedModel.Halt
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &halt)).To(BeNil())
+					Expect(halt.Halted).To(BeFalse())
+					Expect(halt.SetBy).To(Equal("operator"))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+				})
+			})
+		})
+	})
+
+	Describe("Campaigns", func() {
+		var (
+			controller   *webs.Controller
+			req          *http.Request
+			mockRecorder *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			conf = &config.Config{OperatorUsername: "operator", OperatorPassword: "secret"}
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+		})
+
+		Describe("#GetCampaigns", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("GET", "http://example.com/api/campaigns", nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the campaigns can be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns ORDER BY id DESC").WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns).
+						AddRow(5, "game day", "2114-11-12T10:00:00Z", "2114-11-12T12:00:00Z", "1", "", 1.0, 5, "kill", "scheduled", nil).
+						AddRow(4, "dry run", "2014-11-12T10:00:00Z", "2014-11-12T12:00:00Z", "", "space-1", 0.5, 10, "", "ended", `{"runs":3}`))
+				})
+
+				It("returns them newest first, with the reports of those that ended", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var campaigns []sharedModel.Campaign
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &campaigns)).To(BeNil())
+					Expect(campaigns).To(HaveLen(2))
+					Expect(campaigns[0].Name).To(Equal("game day"))
+					Expect(campaigns[0].Report).To(BeNil())
+					Expect(campaigns[1].SpaceIDs).To(Equal([]string{"space-1"}))
+					Expect(campaigns[1].Report.Runs).To(Equal(3))
+				})
+			})
+
+			Context("When the campaigns cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+		})
+
+		Describe("#GetCampaign", func() {
+			var campaignID string
+
+			BeforeEach(func() {
+				campaignID = "4"
+			})
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("GET", "http://example.com/api/campaigns/"+campaignID, nil)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the campaign exists", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE id=").WithArgs(4).WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns).
+						AddRow(4, "game day", "2014-11-12T10:00:00Z", "2014-11-12T12:00:00Z", "1", "", 1.0, 5, "kill", "ended", `{"runs":3,"timed_out":1}`))
+				})
+
+				It("returns the campaign and its report", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var campaign sharedModel.Campaign
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &campaign)).To(BeNil())
+					Expect(campaign.State).To(Equal(sharedModel.CampaignEnded))
+					Expect(*campaign.Report).To(Equal(sharedModel.CampaignReport{Runs: 3, TimedOut: 1}))
+				})
+			})
+
+			Context("When the campaign does not exist", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE id=").WithArgs(4).WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns))
+				})
+
+				It("returns a 404", func() {
+					Expect(mockRecorder.Code).To(Equal(404))
+				})
+			})
+
+			Context("When the campaign id is not a number", func() {
+				BeforeEach(func() {
+					campaignID = "game-day"
+				})
+
+				It("returns a 404", func() {
+					Expect(mockRecorder.Code).To(Equal(404))
+				})
+			})
+		})
+
+		Describe("#AddCampaign", func() {
+			var (
+				body     string
+				password string
+			)
+
+			BeforeEach(func() {
+				body = `{"name":"game day","start_at":"2114-11-12T11:00:00+01:00","end_at":"2114-11-12T13:00:00+01:00","service_instance_ids":["1"],` +
+					`"space_guids":["space-1"],"probability":1,"frequency":5,"actions":["kill","stop-start"],"state":"ended"}`
+				password = "secret"
+			})
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/api/campaigns", strings.NewReader(body))
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the campaign is valid", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").
+						WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns).AddRow("1", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+					mock.ExpectExec("INSERT INTO campaigns").WithArgs("game day", "2114-11-12T10:00:00Z", "2114-11-12T12:00:00Z", "1", "space-1", 1.0, 5,
+						"kill,stop-start", "scheduled").WillReturnResult(sqlmock.NewResult(4, 1))
+				})
+
+				It("schedules the campaign in UTC", func() {
+					Expect(mockRecorder.Code).To(Equal(201))
+					var campaign sharedModel.Campaign
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &campaign)).To(BeNil())
+					Expect(campaign.ID).To(Equal(int64(4)))
+					Expect(campaign.State).To(Equal(sharedModel.CampaignScheduled))
+					Expect(campaign.StartAt).To(Equal("2114-11-12T10:00:00Z"))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the campaign cannot be stored", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").
+						WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns).AddRow("1", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+					mock.ExpectExec("INSERT INTO campaigns").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the campaign has an action disrupting every instance of a target keeping healthy instances", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+				})
+
+				It("returns a 400 without scheduling the campaign", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("service instance 1: stop-start takes every instance of the app out of service, so min_healthy must be set to 0 first"))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the settings of a target cannot be read", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("When the campaign ends before it starts", func() {
+				BeforeEach(func() {
+					body = `{"name":"game day","start_at":"2114-11-12T13:00:00Z","end_at":"2114-11-12T11:00:00Z","service_instance_ids":["1"],"probability":1,"frequency":5}`
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("end_at must be after start_at"))
+				})
+			})
+
+			Context("When the campaign has already ended", func() {
+				BeforeEach(func() {
+					body = `{"name":"game day","start_at":"2014-11-12T11:00:00Z","end_at":"2014-11-12T13:00:00Z","service_instance_ids":["1"],"probability":1,"frequency":5}`
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("end_at must be in the future"))
+				})
+			})
+
+			Context("When the campaign has no targets", func() {
+				BeforeEach(func() {
+					body = `{"name":"game day","start_at":"2114-11-12T11:00:00Z","end_at":"2114-11-12T13:00:00Z","probability":1,"frequency":5}`
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("service_instance_ids or space_guids must name at least one target"))
+				})
+			})
+
+			Context("When the campaign has an unknown action", func() {
+				BeforeEach(func() {
+					body = `{"name":"game day","start_at":"2114-11-12T11:00:00Z","end_at":"2114-11-12T13:00:00Z","service_instance_ids":["1"],` +
+						`"probability":1,"frequency":5,"actions":["meteor"]}`
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(HavePrefix("action must be one of: kill"))
+				})
+			})
+
+			Context("When the start is not a time", func() {
+				BeforeEach(func() {
+					body = `{"name":"game day","start_at":"tomorrow","end_at":"2114-11-12T13:00:00Z","service_instance_ids":["1"],"probability":1,"frequency":5}`
+				})
+
+				It("returns a 400", func() {
+					Expect(mockRecorder.Code).To(Equal(400))
+					Expect(mockRecorder.Body.String()).To(Equal("start_at must be an RFC 3339 time"))
+				})
+			})
+
+			Context("When the credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401 without scheduling the campaign", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Describe("#StopCampaign", func() {
+			var password string
+
+			BeforeEach(func() {
+				password = "secret"
+			})
+
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "http://example.com/api/campaigns/4", nil)
+				req.SetBasicAuth("operator", password)
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("When the campaign has not ended", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE id=").WithArgs(4).WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns).
+						AddRow(4, "game day", "2014-11-12T10:00:00Z", "2114-11-12T12:00:00Z", "1", "", 1.0, 5, "kill", "active", nil))
+					mock.ExpectExec("UPDATE campaigns SET endAt=").WithArgs(sqlmock.AnyArg(), 4, "ended", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				})
+
+				It("brings its end forward to now", func() {
+					Expect(mockRecorder.Code).To(Equal(200))
+					var campaign sharedModel.Campaign
+					Expect(json.Unmarshal(mockRecorder.Body.Bytes(), &campaign)).To(BeNil())
+					Expect(campaign.EndAt).ToNot(Equal("2114-11-12T12:00:00Z"))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("When the campaign has already ended", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE id=").WithArgs(4).WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns).
+						AddRow(4, "game day", "2014-11-12T10:00:00Z", "2014-11-12T12:00:00Z", "1", "", 1.0, 5, "kill", "ended", nil))
+					mock.ExpectExec("UPDATE campaigns SET endAt=").WillReturnResult(sqlmock.NewResult(0, 0))
+				})
+
+				It("returns a 409", func() {
+					Expect(mockRecorder.Code).To(Equal(409))
+					Expect(mockRecorder.Body.String()).To(Equal("campaign has already ended"))
+				})
+			})
+
+			Context("When the credentials are wrong", func() {
+				BeforeEach(func() {
+					password = "guess"
+				})
+
+				It("returns a 401", func() {
+					Expect(mockRecorder.Code).To(Equal(401))
+				})
+			})
+		})
+	})
+
+	Describe("#UpdateServiceInstance", func() {
+		var (
+			response     string
+			controller   *webs.Controller
+			req          *http.Request
+			mockRecorder *httptest.ResponseRecorder
+			probability  = "0.4"
+			frequency    = "10"
+			mtbf         = ""
+			adaptiveStep = ""
+			maxRecovery  = ""
+			seed         = ""
+			strategy     = ""
+			index        = ""
+			killCount    = ""
+			killPercent  = ""
+			minHealthy   = ""
+			minInstances = ""
+			minPercent   = ""
+			states       = ""
+			action       = ""
+			actionDelay  = ""
+			intensity    = ""
+			password     string
+			profileRows  sqlmock.Rows
+		)
+
+		BeforeEach(func() {
+			conf = &config.Config{OperatorUsername: "operator", OperatorPassword: "secret"}
+			password = "secret"
+			controller = webs.CreateController(db, conf)
+			mockRecorder = httptest.NewRecorder()
+			profileRows = sqlmock.NewRows([]string{"actions"})
+		})
+
+		Context("When the service instance exists", func() {
+			JustBeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://example.com/dashboard/1", strings.NewReader(fmt.Sprintf("probability=%s&frequency=%s&mtbf_minutes=%s&adaptive_step=%s&max_recovery_seconds=%s&seed=%s&strategy=%s"+
+					"&strategy_index=%s&kill_count=%s&kill_percent=%s&min_healthy=%s&min_instances=%s&min_healthy_percent=%s&healthy_states=%s&action=%s"+
+					"&action_delay=%s&intensity=%s",
+					probability, frequency, mtbf, adaptiveStep, maxRecovery, seed, strategy, index, killCount, killPercent, minHealthy, minInstances, minPercent, states, action, actionDelay, intensity)))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+				if password != "" {
+					req.SetBasicAuth("operator", password)
+				}
+				Router(controller).ServeHTTP(mockRecorder, req)
+			})
+
+			Context("and the service instance cannot be fetched", func() {
+				BeforeEach(func() {
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				})
+
+				It("returns an error 500", func() {
+					Expect(mockRecorder.Code).To(Equal(500))
+				})
+			})
+
+			Context("when the service instance does not exist in the database", func() {
+				BeforeEach(func() {
+					rows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"})
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(rows)
+				})
+
+				It("returns a 410", func() {
+					Expect(mockRecorder.Code).To(Equal(410))
+				})
+			})
+
+			Context("and the service instance can be fetched", func() {
+				BeforeEach(func() {
+					rows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+						AddRow("1", "https://example.com/dashboard/1", "1", 0.2, 5)
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances WHERE id=").WithArgs("1").WillReturnRows(rows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(profileRows)
+				})
+				Context("When probability is invalid", func() {
+					BeforeEach(func() {
+						response = `<html>
+	<head>
+		<link rel="stylesheet" href="/css/bootstrap.min.css">
+		<link rel="stylesheet" href="/css/bootstrap-theme.min.css">
+		<script src="/js/jquery-1.11.3.min.js"></script>
+		<script src="/js/bootstrap.min.js"></script>
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+		<title>Dashboard</title>
+	</head>
+	<body>
+		<div class="container">
+			<h1>Invalid Configuration Request</h1>
+			<p>Probability must be between 0 and 1</p>
+			<p>Frequency must be between 1 and 60</p>
+			<p>Mean Time Between Failures must be a whole number of minutes of at least 1 or left blank</p>
+			<p>Adaptive Step must be between 1 and 100 percentage points or left blank</p>
+			<p>Adaptive Recovery Threshold must be a whole number of seconds of at least 1</p>
+			<p>Seed must be a whole number or left blank</p>
+			<p>Victim Strategy must be one of: random, oldest, busiest-cpu, busiest-memory, fixed, not-first</p>
+			<p>Fixed Instance Index must be a whole number of at least 0</p>
+			<p>Instances Killed Per Run must be a whole number of at least 1</p>
+			<p>Percentage Of Instances Killed Per Run must be between 0 and 100 or left blank</p>
+			<p>Minimum Healthy Instances must be a whole number of at least 0</p>
+			<p>Minimum Instances must be a whole number of at least 0</p>
+			<p>Minimum Healthy Percentage must be between 0 and 100</p>
+			<p>Healthy States must be a comma separated list of: RUNNING, STARTING, CRASHED, DOWN, FLAPPING, UNKNOWN</p>
+			<p>Action must be one of: kill, restart, stop-start, restage, scale, cell-outage, cpu-burn, memory-pressure, disk-fill, network-partition, route-unmap, egress-blackhole</p>
+			<p>Action Delay must be a whole number of seconds of at least 0</p>
+			<p>Resource Exhaustion Intensity must be between 1 and 100</p>
+			<p>Minimum Healthy Instances must be 0 while the action or profile includes an app wide action: restart, stop-start, restage, egress-blackhole</p>
+		</div>
+	</body>
+</html>`
+						probability = "3"
+					})
+
+					AfterEach(func() {
+						probability = "0.4"
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(Equal(response))
+					})
+				})
+
+				Context("When frequency is invalid", func() {
+					BeforeEach(func() {
+						response = `<html>
+	<head>
+		<link rel="stylesheet" href="/css/bootstrap.min.css">
 		<link rel="stylesheet" href="/css/bootstrap-theme.min.css">
 		<script src="/js/jquery-1.11.3.min.js"></script>
 		<script src="/js/bootstrap.min.js"></script>
@@ -583,6 +2894,22 @@ var _ = Describe("Contoller", func() {
 			<h1>Invalid Configuration Request</h1>
 			<p>Probability must be between 0 and 1</p>
 			<p>Frequency must be between 1 and 60</p>
+			<p>Mean Time Between Failures must be a whole number of minutes of at least 1 or left blank</p>
+			<p>Adaptive Step must be between 1 and 100 percentage points or left blank</p>
+			<p>Adaptive Recovery Threshold must be a whole number of seconds of at least 1</p>
+			<p>Seed must be a whole number or left blank</p>
+			<p>Victim Strategy must be one of: random, oldest, busiest-cpu, busiest-memory, fixed, not-first</p>
+			<p>Fixed Instance Index must be a whole number of at least 0</p>
+			<p>Instances Killed Per Run must be a whole number of at least 1</p>
+			<p>Percentage Of Instances Killed Per Run must be between 0 and 100 or left blank</p>
+			<p>Minimum Healthy Instances must be a whole number of at least 0</p>
+			<p>Minimum Instances must be a whole number of at least 0</p>
+			<p>Minimum Healthy Percentage must be between 0 and 100</p>
+			<p>Healthy States must be a comma separated list of: RUNNING, STARTING, CRASHED, DOWN, FLAPPING, UNKNOWN</p>
+			<p>Action must be one of: kill, restart, stop-start, restage, scale, cell-outage, cpu-burn, memory-pressure, disk-fill, network-partition, route-unmap, egress-blackhole</p>
+			<p>Action Delay must be a whole number of seconds of at least 0</p>
+			<p>Resource Exhaustion Intensity must be between 1 and 100</p>
+			<p>Minimum Healthy Instances must be 0 while the action or profile includes an app wide action: restart, stop-start, restage, egress-blackhole</p>
 		</div>
 	</body>
 </html>`
@@ -590,12 +2917,491 @@ var _ = Describe("Contoller", func() {
 					})
 
 					AfterEach(func() {
-						frequency = "10"
+						frequency = "10"
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(Equal(response))
+					})
+				})
+
+				Context("When seed is invalid", func() {
+					BeforeEach(func() {
+						seed = "not_a_number"
+					})
+
+					AfterEach(func() {
+						seed = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Seed must be a whole number or left blank"))
+					})
+				})
+
+				Context("When a seed is provided", func() {
+					BeforeEach(func() {
+						seed = "42"
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 42, "random", 0, 1, 0, 1, 1, 100, "RUNNING", "kill", 30, 50, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					AfterEach(func() {
+						seed = ""
+					})
+
+					It("saves the seed", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Seed: 42</p>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When strategy is invalid", func() {
+					BeforeEach(func() {
+						strategy = "youngest"
+					})
+
+					AfterEach(func() {
+						strategy = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Victim Strategy must be one of"))
+					})
+				})
+
+				Context("When the fixed instance index is invalid", func() {
+					BeforeEach(func() {
+						index = "-1"
+					})
+
+					AfterEach(func() {
+						index = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Fixed Instance Index must be a whole number of at least 0"))
+					})
+				})
+
+				Context("When a strategy is provided", func() {
+					BeforeEach(func() {
+						strategy = "fixed"
+						index = "3"
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "fixed", 3, 1, 0, 1, 1, 100, "RUNNING", "kill", 30, 50, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					AfterEach(func() {
+						strategy = ""
+						index = ""
+					})
+
+					It("saves the strategy", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Victim Strategy: fixed</p>"))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Fixed Instance Index: 3</p>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When the kill count is invalid", func() {
+					BeforeEach(func() {
+						killCount = "0"
+					})
+
+					AfterEach(func() {
+						killCount = ""
 					})
 
 					It("returns an error page", func() {
 						Expect(mockRecorder.Code).To(Equal(400))
-						Expect(mockRecorder.Body.String()).To(Equal(response))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Instances Killed Per Run must be a whole number of at least 1"))
+					})
+				})
+
+				Context("When the kill percentage is invalid", func() {
+					BeforeEach(func() {
+						killPercent = "101"
+					})
+
+					AfterEach(func() {
+						killPercent = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Percentage Of Instances Killed Per Run must be between 0 and 100 or left blank"))
+					})
+				})
+
+				Context("When the minimum healthy instances is invalid", func() {
+					BeforeEach(func() {
+						minHealthy = "-1"
+					})
+
+					AfterEach(func() {
+						minHealthy = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Minimum Healthy Instances must be a whole number of at least 0"))
+					})
+				})
+
+				Context("When the action or a safety guard is changed without the operator credentials", func() {
+					BeforeEach(func() {
+						password = ""
+						action = "scale"
+						minHealthy = "0"
+					})
+
+					AfterEach(func() {
+						action = ""
+						minHealthy = ""
+					})
+
+					It("returns a 401 without saving the settings", func() {
+						Expect(mockRecorder.Code).To(Equal(401))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When only the probability and frequency are changed without the operator credentials", func() {
+					BeforeEach(func() {
+						password = ""
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 1, 1, 100, "RUNNING", "kill", 30, 50, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					It("saves the settings", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When kill settings are provided", func() {
+					BeforeEach(func() {
+						killCount = "2"
+						killPercent = "25"
+						minHealthy = "1"
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 2, 25, 1, 1, 100, "RUNNING", "kill", 30, 50, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					AfterEach(func() {
+						killCount = ""
+						killPercent = ""
+						minHealthy = ""
+					})
+
+					It("saves the kill settings", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Instances Killed Per Run: 25 percent of instances</p>"))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Minimum Healthy Instances: 1</p>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When the minimum instances is invalid", func() {
+					BeforeEach(func() {
+						minInstances = "-1"
+					})
+
+					AfterEach(func() {
+						minInstances = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Minimum Instances must be a whole number of at least 0"))
+					})
+				})
+
+				Context("When the minimum healthy percentage is invalid", func() {
+					BeforeEach(func() {
+						minPercent = "150"
+					})
+
+					AfterEach(func() {
+						minPercent = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Minimum Healthy Percentage must be between 0 and 100"))
+					})
+				})
+
+				Context("When a healthy state is unknown", func() {
+					BeforeEach(func() {
+						states = "RUNNING,SLEEPING"
+					})
+
+					AfterEach(func() {
+						states = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Healthy States must be a comma separated list of"))
+					})
+				})
+
+				Context("When the action is unknown", func() {
+					BeforeEach(func() {
+						action = "explode"
+					})
+
+					AfterEach(func() {
+						action = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Action must be one of: kill, restart, stop-start, restage, scale, cell-outage, cpu-burn, memory-pressure, disk-fill, network-partition, route-unmap, egress-blackhole"))
+					})
+				})
+
+				Context("When the action delay is invalid", func() {
+					BeforeEach(func() {
+						actionDelay = "-5"
+					})
+
+					AfterEach(func() {
+						actionDelay = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Action Delay must be a whole number of seconds of at least 0"))
+					})
+				})
+
+				Context("When the intensity is invalid", func() {
+					BeforeEach(func() {
+						intensity = "0"
+					})
+
+					AfterEach(func() {
+						intensity = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Resource Exhaustion Intensity must be between 1 and 100"))
+					})
+				})
+
+				Context("When the mean time between failures is invalid", func() {
+					BeforeEach(func() {
+						mtbf = "0"
+					})
+
+					AfterEach(func() {
+						mtbf = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Mean Time Between Failures must be a whole number of minutes of at least 1 or left blank"))
+					})
+				})
+
+				Context("When a mean time between failures is set", func() {
+					BeforeEach(func() {
+						mtbf = "240"
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 1, 1, 100, "RUNNING", "kill", 30, 50, 240, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					AfterEach(func() {
+						mtbf = ""
+					})
+
+					It("saves it and shows the kills per day it is expected to cause", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Mean Time Between Failures: 240 minutes</p>"))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Expected Kills Per Day: 6.0</p>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When the adaptive step is invalid", func() {
+					BeforeEach(func() {
+						adaptiveStep = "101"
+					})
+
+					AfterEach(func() {
+						adaptiveStep = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Adaptive Step must be between 1 and 100 percentage points or left blank"))
+					})
+				})
+
+				Context("When the adaptive recovery threshold is invalid", func() {
+					BeforeEach(func() {
+						maxRecovery = "0"
+					})
+
+					AfterEach(func() {
+						maxRecovery = ""
+					})
+
+					It("returns an error page", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("Adaptive Recovery Threshold must be a whole number of seconds of at least 1"))
+					})
+				})
+
+				Context("When chaos is made adaptive", func() {
+					BeforeEach(func() {
+						adaptiveStep = "5"
+						maxRecovery = "90"
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 1, 1, 100, "RUNNING", "kill", 30, 50, 0, 5, 90).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					AfterEach(func() {
+						adaptiveStep = ""
+						maxRecovery = ""
+					})
+
+					It("saves the step and the threshold", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Adaptive Step: 5 percentage points</p>"))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Adaptive Recovery Threshold: 90 seconds</p>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When a resource exhaustion action is chosen", func() {
+					BeforeEach(func() {
+						action = "memory-pressure"
+						intensity = "90"
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 1, 1, 100, "RUNNING", "memory-pressure", 30, 90, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					AfterEach(func() {
+						action = ""
+						intensity = ""
+					})
+
+					It("saves the intensity", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Resource Exhaustion Intensity: 90</p>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When an app wide action is chosen", func() {
+					BeforeEach(func() {
+						action = "scale"
+						actionDelay = "90"
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 1, 1, 100, "RUNNING", "scale", 90, 50, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					AfterEach(func() {
+						action = ""
+						actionDelay = ""
+					})
+
+					It("saves the action", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Action: scale</p>"))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Action Delay: 90 seconds</p>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When an action disrupting every instance is chosen", func() {
+					BeforeEach(func() {
+						action = "restart"
+					})
+
+					AfterEach(func() {
+						action = ""
+						minHealthy = ""
+					})
+
+					Context("and healthy instances are kept", func() {
+						It("returns an error page without saving the settings", func() {
+							Expect(mockRecorder.Code).To(Equal(400))
+							Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Minimum Healthy Instances must be 0 while the action or profile includes an app wide action"))
+							Expect(mock.ExpectationsWereMet()).To(BeNil())
+						})
+					})
+
+					Context("and no healthy instance is kept", func() {
+						BeforeEach(func() {
+							minHealthy = "0"
+							mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 0, 1, 100, "RUNNING", "restart", 30, 50, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+						})
+
+						It("saves the action", func() {
+							Expect(mockRecorder.Code).To(Equal(202))
+							Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Action: restart</p>"))
+							Expect(mock.ExpectationsWereMet()).To(BeNil())
+						})
+					})
+				})
+
+				Context("When the profile includes an action disrupting every instance and healthy instances are kept", func() {
+					BeforeEach(func() {
+						profileRows.AddRow("kill:70,restage:30")
+					})
+
+					It("returns an error page without saving the settings", func() {
+						Expect(mockRecorder.Code).To(Equal(400))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<h1>Invalid Configuration Request</h1>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When health settings are provided", func() {
+					BeforeEach(func() {
+						minInstances = "2"
+						minPercent = "50"
+						states = "running, starting"
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 1, 2, 50, "RUNNING,STARTING", "kill", 30, 50, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
+					})
+
+					AfterEach(func() {
+						minInstances = ""
+						minPercent = ""
+						states = ""
+					})
+
+					It("saves the health settings", func() {
+						Expect(mockRecorder.Code).To(Equal(202))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Minimum Instances: 2</p>"))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Minimum Healthy Percentage: 50</p>"))
+						Expect(mockRecorder.Body.String()).To(ContainSubstring("<p>Healthy States: RUNNING,STARTING</p>"))
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+
+				Context("When the settings cannot be saved", func() {
+					BeforeEach(func() {
+						mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+						mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 1, 1, 100, "RUNNING", "kill", 30, 50, 0, 0, 60).WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					})
+
+					It("returns an error 500", func() {
+						Expect(mockRecorder.Code).To(Equal(500))
 					})
 				})
 
@@ -613,6 +3419,7 @@ var _ = Describe("Contoller", func() {
 					Context("and the service instance can be updated", func() {
 						BeforeEach(func() {
 							mock.ExpectExec("UPDATE service_instances.*").WithArgs(0.4, 10, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+							mock.ExpectExec("INSERT INTO instance_settings.*").WithArgs("1", 0, "random", 0, 1, 0, 1, 1, 100, "RUNNING", "kill", 30, 50, 0, 0, 60).WillReturnResult(sqlmock.NewResult(1, 1))
 							response = `<html>
 	<head>
 		<link rel="stylesheet" href="/css/bootstrap.min.css">
@@ -627,6 +3434,21 @@ var _ = Describe("Contoller", func() {
 			<h1>New Service Instance Configuration</h1>
 			<p>Probability: 0.4</p>
 			<p>Frequency: 10</p>
+			<p>Mean Time Between Failures: unused</p>
+			<p>Expected Kills Per Day: 57.6</p>
+			<p>Adaptive Step: unused</p>
+			<p>Adaptive Recovery Threshold: 60 seconds</p>
+			<p>Seed: random</p>
+			<p>Victim Strategy: random</p>
+			<p>Fixed Instance Index: 0</p>
+			<p>Instances Killed Per Run: 1</p>
+			<p>Minimum Healthy Instances: 1</p>
+			<p>Minimum Instances: 1</p>
+			<p>Minimum Healthy Percentage: 100</p>
+			<p>Healthy States: RUNNING</p>
+			<p>Action: kill</p>
+			<p>Action Delay: 30 seconds</p>
+			<p>Resource Exhaustion Intensity: 50</p>
 		</div>
 	</body>
 </html>`
@@ -956,6 +3778,50 @@ var _ = Describe("Contoller", func() {
 						})
 					})
 
+					Context("and a route is being bound", func() {
+						BeforeEach(func() {
+							reqJSON := `{
+  "plan_id":       "plan-guid-here",
+  "service_id":    "service-guid-here",
+  "bind_resource": {"route": "www.example.com/shop"}
+ }`
+							req, _ = http.NewRequest("PUT", "http://example.com/v2/service_instances/test/service_bindings/1", bytes.NewReader([]byte(reqJSON)))
+						})
+
+						Context("and the route service URL is configured", func() {
+							BeforeEach(func() {
+								conf.RouteServiceURL = "https://chaos-galago-route-service.example.com/"
+							})
+
+							Context("and the route binding can be added", func() {
+								BeforeEach(func() {
+									mock.ExpectExec("INSERT INTO route_bindings").WithArgs("1", "test", "www.example.com/shop").WillReturnResult(sqlmock.NewResult(1, 1))
+								})
+
+								It("returns the route service URL of the service instance", func() {
+									Expect(mockRecorder.Code).To(Equal(201))
+									Expect(mockRecorder.Body.String()).To(Equal(`{"credentials":{"probability":0.2,"frequency":5},"route_service_url":"https://chaos-galago-route-service.example.com/test"}`))
+								})
+							})
+
+							Context("and the route binding cannot be added", func() {
+								BeforeEach(func() {
+									mock.ExpectExec("INSERT INTO route_bindings").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+								})
+
+								It("returns an error 500", func() {
+									Expect(mockRecorder.Code).To(Equal(500))
+								})
+							})
+						})
+
+						Context("and the route service URL is not configured", func() {
+							It("returns an error 500", func() {
+								Expect(mockRecorder.Code).To(Equal(500))
+							})
+						})
+					})
+
 					Context("and the binding appID is nil", func() {
 						BeforeEach(func() {
 							reqJSON := `{
@@ -24,6 +24,7 @@ if [[ $# -gt 0 && $1 != "--managed-db" ]]; then
     echo "updating chaos-galago-db..."
     cf unbind-service chaos-galago-broker chaos-galago-db
     cf unbind-service chaos-galago-processor chaos-galago-db
+    cf unbind-service chaos-galago-route-service chaos-galago-db
     cf uups chaos-galago-db -p "{\"database\":\"$DB_NAME\",\"host\":\"$DB_HOST\",\"port\":\"$DB_PORT\",\"username\":\"$DB_USERNAME\",\"password\":\"$DB_PASSWORD\"}"
   fi
 fi
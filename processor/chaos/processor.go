@@ -0,0 +1,1518 @@
+package chaos
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/config"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	"github.com/cloudfoundry-community/go-cfclient"
+	"golang.org/x/net/context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CFClient - the Cloud Foundry API calls made by the processor
+type CFClient interface {
+	GetAppInstances(guid string) (map[string]cfclient.AppInstance, error)
+	GetAppStats(guid string) (map[string]model.AppStats, error)
+	KillAppInstance(guid string, index string) error
+	CountAppCrashes(guid string, since time.Time) (int, error)
+	CountAppDeploys(guid string, since time.Time) (int, error)
+	GetAppLocation(guid string) (model.AppLocation, error)
+	CountSpaceApps(spaceGUID string) (int, error)
+	GetAppState(guid string) (model.AppState, error)
+	UpdateAppState(guid string, state string) error
+	ScaleApp(guid string, instances int) error
+	RestageApp(guid string) error
+	RunTask(guid string, task model.Task) (model.Task, error)
+	GetTask(guid string) (model.Task, error)
+	CancelTask(guid string) error
+	ListPolicies(guid string) ([]model.Policy, error)
+	CreatePolicies(policies []model.Policy) error
+	DeletePolicies(policies []model.Policy) error
+	ListRouteMappings(guid string) ([]model.RouteMapping, error)
+	MapRoute(mapping model.RouteMapping) error
+	UnmapRoute(guid string) error
+	ListSecGroups() ([]cfclient.SecGroup, error)
+	BindSecGroup(guid string, spaceGUID string) error
+	UnbindSecGroup(guid string, spaceGUID string) error
+}
+
+// Processor struct
+type Processor struct {
+	DB       *sql.DB
+	CFClient CFClient
+	Conf     *config.Config
+	stop     chan struct{}
+	stopOnce sync.Once
+	watchers sync.WaitGroup
+	probes   *http.Client
+	alerts   *http.Client
+	calls    sync.Mutex
+	pending  map[string]bool
+	Plugins  map[string]model.Plugin
+}
+
+// CreateProcessor - returns a populated processor object
+func CreateProcessor(db *sql.DB, cfClient CFClient, conf *config.Config) *Processor {
+	return &Processor{
+		DB:       db,
+		CFClient: cfClient,
+		Conf:     conf,
+		stop:     make(chan struct{}),
+		probes:   &http.Client{Timeout: conf.ProbeTimeout},
+		alerts:   &http.Client{Timeout: conf.AppTimeout},
+		pending:  make(map[string]bool),
+		Plugins:  make(map[string]model.Plugin),
+	}
+}
+
+// minOwnerTimeout - the least time an owner goes unseen before its rows are claimed, so ticks that run long are not
+// mistaken for an instance that has gone away
+const minOwnerTimeout = 5 * time.Minute
+
+// Run - processes services straight away and then on every tick, returning once the processor is stopped
+func (p *Processor) Run(ticks <-chan time.Time) {
+	for {
+		logError(utils.RecordOwnerSeen(p.DB, p.Conf.InstanceIndex, utils.TimeNow()))
+		p.ProcessServices()
+		select {
+		case <-p.stop:
+			p.Wait()
+			return
+		case <-ticks:
+		}
+	}
+}
+
+// Stop - stops scheduling chaos, actions already in flight are left to finish and recovery is no longer watched
+func (p *Processor) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+// Wait - waits for the recovery of killed app instances to stop being watched
+func (p *Processor) Wait() {
+	p.watchers.Wait()
+}
+
+// ClaimOrphans - records this processor as seen and claims the unresolved intents and snapshots of instances not seen for
+// longer than the recovery timeout, so those an instance left behind when the processor was scaled down are restored too
+func (p *Processor) ClaimOrphans() error {
+	err := utils.RecordOwnerSeen(p.DB, p.Conf.InstanceIndex, utils.TimeNow())
+	if err != nil {
+		return err
+	}
+
+	timeout := p.Conf.RecoveryTimeout
+	if timeout < minOwnerTimeout {
+		timeout = minOwnerTimeout
+	}
+	claimed, err := utils.ClaimOrphans(p.DB, p.Conf.InstanceIndex, time.Now().Add(-timeout))
+	if claimed > 0 {
+		fmt.Printf("Claimed %d intents and snapshots of processor instances not seen for %s\n", claimed, timeout)
+	}
+	return err
+}
+
+// RecoverIntents - records intents this processor decided on but never confirmed as aborted. Intents recorded as unknown
+// because their call timed out are aborted too, as the call cannot return once the processor has stopped, and their events
+// keep the unknown outcome with a reason saying it will never be known.
+func (p *Processor) RecoverIntents() error {
+	intents, err := utils.GetUnresolvedIntents(p.DB, p.Conf.InstanceIndex)
+	if err != nil {
+		return err
+	}
+
+	for _, intent := range intents {
+		if intent.State == model.IntentUnknown {
+			fmt.Printf("Intent %s to %s app: %s at index: %s timed out and never returned, recording as aborted\n", intent.ID, intent.Action, intent.AppID, intent.InstanceIndex)
+			if intent.EventID != 0 {
+				err = sharedUtils.UpdateEventOutcome(p.DB, intent.EventID, sharedModel.OutcomeUnknown, "the processor stopped before the timed out call returned, so whether it landed will never be known")
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			fmt.Printf("Intent %s to %s app: %s at index: %s was never confirmed, recording as aborted\n", intent.ID, intent.Action, intent.AppID, intent.InstanceIndex)
+		}
+		err = utils.UpdateIntentState(p.DB, intent.ID, model.IntentAborted)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessServices - processes chaos for every bound app on a bounded pool of workers
+func (p *Processor) ProcessServices() {
+	select {
+	case <-p.stop:
+		return
+	default:
+	}
+
+	p.applyScheduledChanges()
+	campaigns := p.runCampaigns()
+
+	halt, err := sharedUtils.GetHalt(p.DB)
+	if logError(err) {
+		return
+	}
+	if halt.Halted {
+		fmt.Printf("%s, skipping\n", haltReason(halt))
+		return
+	}
+
+	services := utils.GetBoundApps(p.DB)
+
+	RunPool(p.stop, p.Conf.Workers, services, func(service model.Service) {
+		ctx, cancel := context.WithTimeout(context.Background(), p.Conf.AppTimeout)
+		defer cancel()
+		p.ProcessService(ctx, p.joinCampaign(ctx, service, campaigns))
+	})
+}
+
+// applyScheduledChanges - applies the scheduled changes to service instances whose time has come, oldest first, before the
+// bound apps are loaded so that they take effect on this run
+func (p *Processor) applyScheduledChanges() {
+	runAt := utils.TimeNow()
+	changes, err := sharedUtils.ReadDueScheduledChanges(p.DB, runAt)
+	if logError(err) {
+		return
+	}
+
+	for _, change := range changes {
+		applied, err := sharedUtils.ApplyScheduledChange(p.DB, change, runAt)
+		if logError(err) || !applied {
+			continue
+		}
+		fmt.Printf("Applied the change to service instance %s scheduled for %s\n", change.ServiceInstanceID, change.ApplyAt)
+	}
+}
+
+// runCampaigns - starts the campaigns whose start has come and reports on those that have ended, returning the campaigns in
+// progress. Campaigns keep running while chaos is halted, so that they still end on time.
+func (p *Processor) runCampaigns() []sharedModel.Campaign {
+	campaigns, err := sharedUtils.ReadOpenCampaigns(p.DB)
+	if logError(err) {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	runAt := utils.TimeNow()
+	var inProgress []sharedModel.Campaign
+	for _, campaign := range campaigns {
+		switch {
+		case campaign.EndAt <= runAt:
+			if utils.CampaignReportDue(campaign, p.Conf.RecoveryTimeout, now) {
+				p.endCampaign(campaign, runAt)
+			}
+		case campaign.StartAt > runAt:
+		default:
+			if campaign.State == sharedModel.CampaignScheduled {
+				started, err := sharedUtils.StartCampaign(p.DB, campaign.ID)
+				if logError(err) {
+					continue
+				}
+				if started {
+					fmt.Printf("Campaign %s started, running until %s\n", campaign.Name, campaign.EndAt)
+				}
+			}
+			inProgress = append(inProgress, campaign)
+		}
+	}
+	return inProgress
+}
+
+// endCampaign - ends a campaign, recording a report on the chaos run against the apps that took part
+func (p *Processor) endCampaign(campaign sharedModel.Campaign, endedAt string) {
+	events, err := sharedUtils.GetCampaignEvents(p.DB, campaign)
+	if logError(err) {
+		return
+	}
+	report := sharedUtils.BuildCampaignReport(events, endedAt)
+	ended, err := sharedUtils.EndCampaign(p.DB, campaign.ID, report)
+	if logError(err) || !ended {
+		return
+	}
+	fmt.Printf("Campaign %s ended: %d runs against %d apps, %d succeeded, %d failed, %d skipped, %d instances recovered and %d timed out\n",
+		campaign.Name, report.Runs, report.Apps, report.Succeeded, report.Failed, report.Skipped, report.Recovered, report.TimedOut)
+}
+
+// joinCampaign - applies the first campaign in progress targeting a bound app, through its service instance or its space,
+// to the chaos of the app
+func (p *Processor) joinCampaign(ctx context.Context, service model.Service, campaigns []sharedModel.Campaign) model.Service {
+	if service.Paused {
+		return service
+	}
+
+	var location *model.AppLocation
+	for _, campaign := range campaigns {
+		targeted := campaign.TargetsInstance(service.ServiceInstanceID)
+		if !targeted && len(campaign.SpaceIDs) > 0 {
+			if location == nil {
+				location = &model.AppLocation{}
+				var fetched model.AppLocation
+				err := callWithContext(ctx, func() error {
+					var err error
+					fetched, err = p.CFClient.GetAppLocation(service.AppID)
+					return err
+				})
+				// an abandoned call may still write fetched, so it is only read once the call returned, leaving the space
+				// of an app whose location could not be fetched untargeted
+				if !logError(err) {
+					*location = fetched
+				}
+			}
+			targeted = campaign.TargetsSpace(location.SpaceID)
+		}
+		if !targeted {
+			continue
+		}
+
+		err := sharedUtils.JoinCampaign(p.DB, campaign.ID, service.ServiceInstanceID, service.AppID)
+		logError(err)
+		fmt.Printf("App %s takes part in campaign %s\n", service.AppID, campaign.Name)
+		return utils.ApplyCampaign(service, campaign)
+	}
+	return service
+}
+
+// ProcessService - processes chaos for a single bound app, abandoning Cloud Foundry calls once ctx is done
+func (p *Processor) ProcessService(ctx context.Context, service model.Service) {
+	if service.Paused {
+		fmt.Printf("Chaos is paused for %s, skipping\n", service.AppID)
+		return
+	}
+	replaying := service.Replay.EventID != 0
+	if replaying {
+		fmt.Printf("Replaying event %d against %s\n", service.Replay.EventID, service.AppID)
+	} else if service.Settings.UsesMTBF() {
+		if !p.failureDue(service) {
+			fmt.Printf("No failure of %s is due, skipping\n", service.AppID)
+			return
+		}
+	} else if !utils.ShouldProcess(service.Frequency, service.LastProcessed) {
+		fmt.Printf("Skipping processing chaos for %s\n", service.AppID)
+		return
+	}
+
+	fmt.Printf("Processing chaos for %s\n", service.AppID)
+	runAt := utils.TimeNow()
+	err := utils.UpdateLastProcessed(p.DB, service.AppID, runAt)
+	if logError(err) {
+		return
+	}
+	if replaying {
+		claimed, err := sharedUtils.ClaimReplay(p.DB, service.ServiceBindingID, service.Replay.EventID)
+		if logError(err) || !claimed {
+			return
+		}
+	}
+
+	event := sharedModel.Event{
+		AppID:             service.AppID,
+		ServiceInstanceID: service.ServiceInstanceID,
+		ServiceBindingID:  service.ServiceBindingID,
+		CreatedAt:         runAt,
+		Seed:              utils.RunSeed(service.Settings.Seed, service.AppID, runAt),
+		Action:            service.Settings.Action,
+	}
+	if replaying {
+		event.Seed = service.Replay.Seed
+	}
+	rng := utils.NewRand(event.Seed)
+
+	// a replay still draws whether to run, so that the draws after it are those of the recorded run
+	probability := sharedUtils.EffectiveProbability(service.Settings, service.Probability, service.LastChange)
+	if !service.Settings.UsesMTBF() && !utils.ShouldRun(rng, probability) && !replaying {
+		fmt.Printf("Not running chaos for %s\n", service.AppID)
+		err = utils.UpdateLastProcessed(p.DB, service.AppID, utils.TimeNow())
+		logError(err)
+		return
+	}
+	if len(service.Profile) > 0 {
+		event.Action = utils.SampleAction(rng, service.Profile, service.Settings.Action)
+		service.Settings.Action = event.Action
+		fmt.Printf("Profile of %s chose %s\n", service.AppID, event.Action)
+	}
+
+	if p.awaitingCall(service.AppID) {
+		reason := "an earlier action on the app timed out and has not returned"
+		fmt.Printf("App %s: %s, skipping\n", service.AppID, reason)
+		p.recordEvent(event, sharedModel.OutcomeSkipped, reason)
+		return
+	}
+
+	reason, err := p.deploying(ctx, service.AppID)
+	if logError(err) {
+		p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+		return
+	}
+	if reason != "" {
+		fmt.Printf("App %s: %s, skipping\n", service.AppID, reason)
+		p.recordEvent(event, sharedModel.OutcomeSkipped, reason)
+		return
+	}
+
+	fmt.Printf("Running chaos for %s with seed %d\n", service.AppID, event.Seed)
+	var appInstances map[string]cfclient.AppInstance
+	err = callWithContext(ctx, func() error {
+		var err error
+		appInstances, err = p.CFClient.GetAppInstances(service.AppID)
+		return err
+	})
+	if logError(err) {
+		p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+		return
+	}
+
+	healthy, reason := utils.CheckHealth(service.Settings, appInstances)
+	if !healthy {
+		fmt.Printf("App %s is unhealthy: %s, skipping\n", service.AppID, reason)
+		p.recordEvent(event, sharedModel.OutcomeSkipped, reason)
+		return
+	}
+
+	fmt.Printf("App %s is Healthy\n", service.AppID)
+	victims := utils.VictimCount(service.Settings, len(appInstances), utils.HealthyCount(service.Settings, appInstances))
+	switch {
+	case sharedModel.KeepsInstances(event.Action):
+	case sharedModel.IsAppAction(event.Action):
+		if service.Settings.MinHealthy > 0 {
+			reason := fmt.Sprintf("%s would leave fewer than %d healthy instances", event.Action, service.Settings.MinHealthy)
+			fmt.Printf("App %s: %s, skipping\n", service.AppID, reason)
+			p.recordEvent(event, sharedModel.OutcomeSkipped, reason)
+			return
+		}
+	case victims == 0:
+		reason := fmt.Sprintf("killing would leave fewer than %d healthy instances", service.Settings.MinHealthy)
+		fmt.Printf("App %s: %s, skipping\n", service.AppID, reason)
+		p.recordEvent(event, sharedModel.OutcomeSkipped, reason)
+		return
+	}
+
+	if sharedModel.IsPluginAction(event.Action) {
+		if outcome, reason := p.validatePlugin(event, service.Settings); reason != "" {
+			fmt.Printf("App %s: %s\n", service.AppID, reason)
+			p.recordEvent(event, outcome, reason)
+			return
+		}
+	}
+
+	if len(service.Probes) > 0 {
+		err = utils.RunProbes(p.probes, service.Probes)
+		if err != nil {
+			reason := fmt.Sprintf("steady state not met: %s", err.Error())
+			fmt.Printf("App %s: %s, skipping\n", service.AppID, reason)
+			p.recordEvent(event, sharedModel.OutcomeSkipped, reason)
+			return
+		}
+	}
+
+	if p.Conf.Budget.NeedsLocation() {
+		var location model.AppLocation
+		err = callWithContext(ctx, func() error {
+			var err error
+			location, err = p.CFClient.GetAppLocation(service.AppID)
+			return err
+		})
+		if logError(err) {
+			p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+			return
+		}
+		event.OrgID = location.OrgID
+		event.SpaceID = location.SpaceID
+	}
+
+	var eventIDs []int64
+	switch event.Action {
+	case sharedModel.ActionKill:
+		eventIDs = p.killVictims(ctx, service, event, rng, appInstances, victims)
+	case sharedModel.ActionCell:
+		if eventID := p.killCell(ctx, service, event, rng, appInstances); eventID != 0 {
+			eventIDs = append(eventIDs, eventID)
+		}
+	default:
+		if !sharedModel.KeepsInstances(event.Action) {
+			event.InstanceIndex = utils.ActionIndexes(event.Action, appInstances, victims)
+		}
+		if eventID, _ := p.actIfAllowed(ctx, event, service); eventID != 0 {
+			eventIDs = append(eventIDs, eventID)
+		}
+	}
+	hypothesisFailure := ""
+	if len(service.Probes) > 0 && len(eventIDs) > 0 {
+		hypothesisFailure = p.verifyHypothesis(service, eventIDs)
+	}
+	if service.Settings.Adaptive() && len(eventIDs) > 0 {
+		switch {
+		case hypothesisFailure != "":
+			p.adapt(service, event.CreatedAt, sharedModel.ProbabilityBackedOff, hypothesisFailure)
+		case !p.watchesRecovery(event.Action):
+			p.adapt(service, event.CreatedAt, sharedModel.ProbabilityRaised, fmt.Sprintf("%s succeeded without breaking the steady state", event.Action))
+		}
+	}
+	err = utils.UpdateLastProcessed(p.DB, service.AppID, utils.TimeNow())
+	logError(err)
+}
+
+// failureDue - determines if the next failure of an app run as a Poisson process is due, drawing the failure after it. A
+// first schedule, or one drawn for another mean time between failures, is never due and neither is a failure another
+// processor instance claimed first.
+func (p *Processor) failureDue(service model.Service) bool {
+	schedule, err := utils.GetSchedule(p.DB, service.AppID)
+	if logError(err) {
+		return false
+	}
+	mtbf := service.Settings.MTBFMinutes
+	now := time.Now().UTC()
+	due := utils.FailureDue(schedule, mtbf, now)
+	if !due && schedule.AppID != "" && schedule.MTBFMinutes == mtbf {
+		return false
+	}
+
+	rng := utils.NewRand(utils.RunSeed(service.Settings.Seed, service.AppID+"/schedule", utils.TimeNow()))
+	claimed, err := utils.ClaimSchedule(p.DB, schedule, utils.NextSchedule(rng, service.AppID, mtbf, now))
+	if logError(err) {
+		return false
+	}
+	return due && claimed
+}
+
+// killVictims - kills the instances of an app chosen by its victim strategy, one at a time, returning the ids of the events recorded
+func (p *Processor) killVictims(ctx context.Context, service model.Service, event sharedModel.Event, rng *rand.Rand,
+	appInstances map[string]cfclient.AppInstance, victims int) []int64 {
+	var appStats map[string]model.AppStats
+	if utils.StrategyNeedsStats(service.Settings.Strategy) {
+		err := callWithContext(ctx, func() error {
+			var err error
+			appStats, err = p.CFClient.GetAppStats(service.AppID)
+			return err
+		})
+		if logError(err) {
+			p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+			return nil
+		}
+	}
+
+	ranked, err := utils.RankInstances(service.Settings, rng, utils.HealthyIndexes(service.Settings, appInstances), appStats)
+	if err != nil {
+		fmt.Printf("No victim for %s with strategy %s: %s\n", service.AppID, service.Settings.Strategy, err.Error())
+		p.recordEvent(event, sharedModel.OutcomeSkipped, err.Error())
+		return nil
+	}
+	if len(ranked) > victims {
+		ranked = ranked[:victims]
+	}
+
+	var eventIDs []int64
+	for _, index := range ranked {
+		event.InstanceIndex = strconv.Itoa(index)
+		eventID, acted := p.actIfAllowed(ctx, event, service)
+		if eventID != 0 {
+			eventIDs = append(eventIDs, eventID)
+		}
+		if !acted {
+			break
+		}
+	}
+	return eventIDs
+}
+
+// killCell - simulates the outage of a Diego cell by killing every healthy instance of an app on one cell chosen at random,
+// returning the id of the event recorded, 0 if none was
+func (p *Processor) killCell(ctx context.Context, service model.Service, event sharedModel.Event, rng *rand.Rand,
+	appInstances map[string]cfclient.AppInstance) int64 {
+	var appStats map[string]model.AppStats
+	err := callWithContext(ctx, func() error {
+		var err error
+		appStats, err = p.CFClient.GetAppStats(service.AppID)
+		return err
+	})
+	if logError(err) {
+		p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+		return 0
+	}
+
+	healthy := utils.HealthyIndexes(service.Settings, appInstances)
+	host, indexes, err := utils.ChooseCell(rng, healthy, appStats, len(healthy)-service.Settings.MinHealthy)
+	if err != nil {
+		fmt.Printf("No cell to take out for %s: %s\n", service.AppID, err.Error())
+		p.recordEvent(event, sharedModel.OutcomeSkipped, err.Error())
+		return 0
+	}
+
+	fmt.Printf("Simulating an outage of cell %s for %s\n", host, service.AppID)
+	event.InstanceIndex = utils.JoinIndexes(indexes)
+	eventID, _ := p.actIfAllowed(ctx, event, service)
+	return eventID
+}
+
+// verifyHypothesis - runs the probes of an app after chaos, recording whether its steady state held on every event. It returns
+// why the hypothesis failed, blank if it held.
+func (p *Processor) verifyHypothesis(service model.Service, eventIDs []int64) string {
+	hypothesis := sharedModel.HypothesisHeld
+	reason := ""
+	err := utils.RunProbes(p.probes, service.Probes)
+	if err != nil {
+		hypothesis = sharedModel.HypothesisFailed
+		reason = fmt.Sprintf("steady state hypothesis failed: %s", err.Error())
+	}
+	fmt.Printf("Steady state hypothesis for %s %s\n", service.AppID, hypothesis)
+
+	for _, eventID := range eventIDs {
+		err = sharedUtils.UpdateEventHypothesis(p.DB, eventID, hypothesis, reason)
+		logError(err)
+	}
+	return reason
+}
+
+// actIfAllowed - takes the chaos action of an event unless chaos has been halted or the action would exceed a configured budget.
+func (p *Processor) actIfAllowed(ctx context.Context, event sharedModel.Event, service model.Service) (int64, bool) {
+	halt, err := sharedUtils.GetHalt(p.DB)
+	if logError(err) {
+		p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+		return 0, false
+	}
+	if halt.Halted {
+		reason := haltReason(halt)
+		fmt.Printf("App %s: %s, skipping\n", event.AppID, reason)
+		p.recordEvent(event, sharedModel.OutcomeSkipped, reason)
+		return 0, false
+	}
+
+	intent := model.Intent{
+		ID:            utils.NewID(),
+		AppID:         event.AppID,
+		InstanceIndex: event.InstanceIndex,
+		Owner:         p.Conf.InstanceIndex,
+		State:         model.IntentPending,
+		CreatedAt:     utils.TimeNow(),
+		UpdatedAt:     utils.TimeNow(),
+		Action:        event.Action,
+		OrgID:         event.OrgID,
+		SpaceID:       event.SpaceID,
+	}
+	if p.Conf.Budget.Enabled() {
+		if !p.reserveBudget(event, intent) {
+			return 0, false
+		}
+	} else if logError(utils.RecordIntent(p.DB, intent)) {
+		return 0, false
+	}
+	return p.act(ctx, event, service, intent)
+}
+
+// reserveBudget - records the intent of an event unless its action would exceed a configured budget, recording the event
+// as skipped or failed when it does not. Budgets are checked and the intent recorded under a lock shared by every processor
+// instance, which is released before the action is taken as the pending intent counts against the budgets until then.
+func (p *Processor) reserveBudget(event sharedModel.Event, intent model.Intent) bool {
+	unlock, err := utils.LockBudget(p.DB, p.Conf.AppTimeout)
+	if logError(err) {
+		p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+		return false
+	}
+	defer unlock()
+
+	exhausted, err := utils.BudgetExhausted(p.DB, p.Conf.Budget, event, time.Now(), p.Conf.RecoveryTimeout)
+	if logError(err) {
+		p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+		return false
+	}
+	if exhausted != "" {
+		reason := fmt.Sprintf("budget exhausted: %s", exhausted)
+		fmt.Printf("App %s: %s, skipping\n", event.AppID, reason)
+		p.recordEvent(event, sharedModel.OutcomeSkipped, reason)
+		return false
+	}
+	err = utils.RecordIntent(p.DB, intent)
+	return !logError(err)
+}
+
+// act - takes the chaos action of an event whose intent has been recorded, recording the state of the app before and after,
+// the event and the outcome of the intent. It returns the id of the event, 0 if none was recorded, and false if chaos should stop.
+func (p *Processor) act(ctx context.Context, event sharedModel.Event, service model.Service, intent model.Intent) (int64, bool) {
+	appState, before, err := p.snapshot(ctx, event.AppID)
+	if logError(err) {
+		p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+		err = utils.UpdateIntentState(p.DB, intent.ID, model.IntentFailed)
+		logError(err)
+		return 0, false
+	}
+	event.Before = before
+
+	fmt.Printf("About to %s app: %s at index: %s\n", event.Action, event.AppID, event.InstanceIndex)
+	actedAt := time.Now()
+	err = p.perform(ctx, event, appState, service.Settings)
+	if restoredAfterDelay(event.Action) {
+		actedAt = time.Now()
+	}
+	event.After = p.snapshotAfter(event.AppID)
+	if abandoned, ok := err.(*abandonedCall); ok {
+		select {
+		case <-abandoned.done:
+			err = abandoned.callErr
+		default:
+			p.awaitAbandoned(event, intent.ID, abandoned)
+			return 0, false
+		}
+	}
+	intentState := model.IntentCompleted
+	acted := !logError(err)
+	var eventID int64
+	if acted {
+		eventID = p.recordEvent(event, sharedModel.OutcomeSucceeded, "")
+		if eventID != 0 && p.watchesRecovery(event.Action) {
+			p.watchRecovery(service, event, eventID, actedAt)
+		}
+	} else {
+		intentState = model.IntentFailed
+		p.recordEvent(event, sharedModel.OutcomeFailed, err.Error())
+	}
+	err = utils.UpdateIntentState(p.DB, intent.ID, intentState)
+	logError(err)
+	return eventID, acted
+}
+
+// awaitAbandoned - records the outcome of an action whose call timed out as unknown, as it may still land, and records its
+// real outcome in the background once the call returns. Chaos is not run against the app again until then.
+func (p *Processor) awaitAbandoned(event sharedModel.Event, intentID string, abandoned *abandonedCall) {
+	eventID := p.recordEvent(event, sharedModel.OutcomeUnknown, abandoned.Error())
+	err := utils.RecordIntentUnknown(p.DB, intentID, eventID)
+	logError(err)
+
+	p.calls.Lock()
+	p.pending[event.AppID] = true
+	p.calls.Unlock()
+	p.watchers.Add(1)
+	go func() {
+		defer p.watchers.Done()
+		select {
+		case <-abandoned.done:
+		case <-p.stop:
+			return
+		}
+		p.calls.Lock()
+		delete(p.pending, event.AppID)
+		p.calls.Unlock()
+
+		outcome, reason, intentState := sharedModel.OutcomeSucceeded, "", model.IntentCompleted
+		if abandoned.callErr != nil {
+			outcome, reason, intentState = sharedModel.OutcomeFailed, abandoned.callErr.Error(), model.IntentFailed
+		}
+		fmt.Printf("The %s action on app: %s returned after timing out, recording it as %s\n", event.Action, event.AppID, outcome)
+		if eventID != 0 {
+			err := sharedUtils.UpdateEventOutcome(p.DB, eventID, outcome, reason)
+			logError(err)
+		}
+		err := utils.UpdateIntentState(p.DB, intentID, intentState)
+		logError(err)
+	}()
+}
+
+// awaitingCall - determines if an action on an app timed out and its call has not yet returned
+func (p *Processor) awaitingCall(appID string) bool {
+	p.calls.Lock()
+	defer p.calls.Unlock()
+	return p.pending[appID]
+}
+
+// perform - carries out the chaos action of an event against the Cloud Foundry API, or through its plugin.
+// Stopped or scaled down apps are restored after the action delay, or straight away if stopping or scaling failed or, once
+// the call returns, timed out, resource exhaustion tasks still running after it are cancelled, removed network policies,
+// routes and security groups are created again and plugin actions are rolled back.
+func (p *Processor) perform(ctx context.Context, event sharedModel.Event, appState model.AppState, settings sharedModel.InstanceSettings) error {
+	delay := actionDelay(settings)
+	switch event.Action {
+	case sharedModel.ActionRestart:
+		return callWithContext(ctx, func() error {
+			return p.restartApp(event.AppID)
+		})
+	case sharedModel.ActionRestage:
+		return callWithContext(ctx, func() error {
+			return p.CFClient.RestageApp(event.AppID)
+		})
+	case sharedModel.ActionStopStart, sharedModel.ActionScale:
+		return p.disruptApp(ctx, event, appState, delay)
+	case sharedModel.ActionCPU, sharedModel.ActionMemory, sharedModel.ActionDisk:
+		var task model.Task
+		err := callWithContext(ctx, func() error {
+			var err error
+			task, err = p.CFClient.RunTask(event.AppID, utils.ResourceTask(event.Action, appState, settings.Intensity, delay))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Started task %s on app: %s\n", task.GUID, event.AppID)
+		return p.restore(event, nil, delay, fmt.Sprintf("task %s could not be cancelled", task.GUID), func() error {
+			return p.cancelTask(task.GUID)
+		})
+	case sharedModel.ActionPartition:
+		return p.partition(ctx, event, delay)
+	case sharedModel.ActionUnmap:
+		return p.unmapRoute(ctx, event, delay)
+	case sharedModel.ActionBlackhole:
+		return p.blackhole(ctx, event, delay)
+	case sharedModel.ActionCell:
+		return callWithContext(ctx, func() error {
+			for _, index := range strings.Split(event.InstanceIndex, ",") {
+				err := p.CFClient.KillAppInstance(event.AppID, index)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	default:
+		if sharedModel.IsPluginAction(event.Action) {
+			return p.runPlugin(event, settings, delay)
+		}
+		return callWithContext(ctx, func() error {
+			return p.CFClient.KillAppInstance(event.AppID, event.InstanceIndex)
+		})
+	}
+}
+
+// restore - undoes the action of an event once the delay has passed, or straight away if the action failed or the processor
+// is stopped, pausing chaos for the binding if it cannot be undone. An action that timed out is only undone once its call
+// returns, so that it cannot land after the undo, or else left to be undone at startup if the processor is stopped first.
+// It returns the error of the action, once known, or else of undoing it.
+func (p *Processor) restore(event sharedModel.Event, actionErr error, delay time.Duration, failure string, undo func() error) error {
+	if abandoned, ok := actionErr.(*abandonedCall); ok {
+		fmt.Printf("Waiting for the %s action on app: %s to return before undoing it\n", event.Action, event.AppID)
+		select {
+		case <-abandoned.done:
+			actionErr = abandoned.callErr
+		case <-p.stop:
+			return actionErr
+		}
+	} else if actionErr == nil {
+		wait := time.NewTimer(delay)
+		select {
+		case <-wait.C:
+		case <-p.stop:
+			wait.Stop()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Conf.AppTimeout)
+	defer cancel()
+	err := callWithContext(ctx, undo)
+	if err != nil {
+		err = fmt.Errorf("%s: %s", failure, err.Error())
+		p.pause(event, err.Error())
+	}
+	if actionErr != nil {
+		return actionErr
+	}
+	return err
+}
+
+// disruptApp - stops an app, or scales it down by the instances of an event, for the action delay. Its state and instance
+// count are saved before so that a processor restarted in the meantime still restores them.
+func (p *Processor) disruptApp(ctx context.Context, event sharedModel.Event, appState model.AppState, delay time.Duration) error {
+	snapshot := model.StateSnapshot{ID: utils.NewID(), AppID: event.AppID, Action: event.Action, State: appState.State,
+		Instances: appState.Instances, Owner: p.Conf.InstanceIndex, CreatedAt: utils.TimeNow()}
+	err := utils.SaveStateSnapshot(p.DB, snapshot)
+	if err != nil {
+		return err
+	}
+
+	failure := "app could not be started"
+	err = callWithContext(ctx, func() error {
+		if event.Action == sharedModel.ActionScale {
+			return p.CFClient.ScaleApp(event.AppID, appState.Instances-len(strings.Split(event.InstanceIndex, ",")))
+		}
+		return p.CFClient.UpdateAppState(event.AppID, "STOPPED")
+	})
+	if event.Action == sharedModel.ActionScale {
+		failure = fmt.Sprintf("app could not be scaled back to %d instances", appState.Instances)
+	}
+	return p.restore(event, err, delay, failure, func() error {
+		return p.restoreAppState(snapshot)
+	})
+}
+
+// restoreAppState - scales an app back to the instance count of its snapshot, or returns it to the snapshot's state
+func (p *Processor) restoreAppState(snapshot model.StateSnapshot) error {
+	var err error
+	if snapshot.Action == sharedModel.ActionScale {
+		err = p.CFClient.ScaleApp(snapshot.AppID, snapshot.Instances)
+	} else {
+		err = p.CFClient.UpdateAppState(snapshot.AppID, snapshot.State)
+	}
+	if err != nil {
+		return err
+	}
+	return utils.DeleteStateSnapshot(p.DB, snapshot.ID)
+}
+
+// RestoreAppStates - starts again or scales back the apps this processor stopped or scaled down but never restored
+func (p *Processor) RestoreAppStates() error {
+	snapshots, err := utils.GetStateSnapshots(p.DB, p.Conf.InstanceIndex)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		fmt.Printf("App: %s was never restored after the %s action, restoring it to %s with %d instances\n", snapshot.AppID,
+			snapshot.Action, snapshot.State, snapshot.Instances)
+		err = p.restoreAppState(snapshot)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partition - removes the network policies between an app and one of its peers for the action delay. The policies are
+// saved before they are removed so that a processor restarted in the meantime still restores them.
+func (p *Processor) partition(ctx context.Context, event sharedModel.Event, delay time.Duration) error {
+	var policies []model.Policy
+	err := callWithContext(ctx, func() error {
+		var err error
+		policies, err = p.CFClient.ListPolicies(event.AppID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	peer, policies, err := utils.ChoosePeer(utils.NewRand(event.Seed), event.AppID, policies)
+	if err != nil {
+		return err
+	}
+
+	snapshot := model.PolicySnapshot{ID: utils.NewID(), AppID: event.AppID, Owner: p.Conf.InstanceIndex, Policies: policies, CreatedAt: utils.TimeNow()}
+	err = utils.SavePolicySnapshot(p.DB, snapshot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removing %d network policies between app: %s and app: %s\n", len(policies), event.AppID, peer)
+	err = callWithContext(ctx, func() error {
+		return p.CFClient.DeletePolicies(policies)
+	})
+	return p.restore(event, err, delay, fmt.Sprintf("network policies with %s could not be restored", peer), func() error {
+		err := p.CFClient.CreatePolicies(policies)
+		if err != nil {
+			return err
+		}
+		return utils.DeletePolicySnapshot(p.DB, snapshot.ID)
+	})
+}
+
+// RestorePolicies - creates again the network policies this processor removed but never restored
+func (p *Processor) RestorePolicies() error {
+	snapshots, err := utils.GetPolicySnapshots(p.DB, p.Conf.InstanceIndex)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		fmt.Printf("Network policies of app: %s were never restored, restoring %d policies\n", snapshot.AppID, len(snapshot.Policies))
+		err = p.CFClient.CreatePolicies(snapshot.Policies)
+		if err != nil {
+			return err
+		}
+		err = utils.DeletePolicySnapshot(p.DB, snapshot.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmapRoute - unmaps one of the routes of an app for the action delay. The mapping is saved before it is removed so that a
+// processor restarted in the meantime still maps the route again.
+func (p *Processor) unmapRoute(ctx context.Context, event sharedModel.Event, delay time.Duration) error {
+	var mappings []model.RouteMapping
+	err := callWithContext(ctx, func() error {
+		var err error
+		mappings, err = p.CFClient.ListRouteMappings(event.AppID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	mapping, err := utils.ChooseRoute(utils.NewRand(event.Seed), mappings)
+	if err != nil {
+		return err
+	}
+
+	snapshot := model.MappingSnapshot{ID: utils.NewID(), Owner: p.Conf.InstanceIndex, Mapping: mapping, CreatedAt: utils.TimeNow()}
+	err = utils.SaveMappingSnapshot(p.DB, snapshot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Unmapping route: %s from app: %s\n", mapping.RouteID, event.AppID)
+	err = callWithContext(ctx, func() error {
+		return p.CFClient.UnmapRoute(mapping.GUID)
+	})
+	return p.restore(event, err, delay, fmt.Sprintf("route %s could not be mapped again", mapping.RouteID), func() error {
+		err := p.CFClient.MapRoute(mapping)
+		if err != nil {
+			return err
+		}
+		return utils.DeleteMappingSnapshot(p.DB, snapshot.ID)
+	})
+}
+
+// RestoreRoutes - maps again the routes this processor unmapped but never mapped back
+func (p *Processor) RestoreRoutes() error {
+	snapshots, err := utils.GetMappingSnapshots(p.DB, p.Conf.InstanceIndex)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		fmt.Printf("Route: %s of app: %s was never mapped again, mapping it\n", snapshot.Mapping.RouteID, snapshot.Mapping.AppID)
+		err = p.CFClient.MapRoute(snapshot.Mapping)
+		if err != nil {
+			return err
+		}
+		err = utils.DeleteMappingSnapshot(p.DB, snapshot.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blackhole - cuts off the egress of an app by replacing the running security groups bound to its space with the blackhole
+// group and restarting it, for the action delay up to the maximum blackhole duration. As security groups apply to the whole
+// space, it refuses spaces holding other apps. The groups are saved before they are unbound so that a processor restarted in
+// the meantime still binds them again, and the time taken to blackhole the app counts towards the maximum duration.
+func (p *Processor) blackhole(ctx context.Context, event sharedModel.Event, delay time.Duration) error {
+	if p.Conf.BlackholeGroup == "" {
+		return fmt.Errorf("no egress blackhole security group is configured")
+	}
+	var (
+		location  model.AppLocation
+		spaceApps int
+		secGroups []cfclient.SecGroup
+	)
+	err := callWithContext(ctx, func() error {
+		var err error
+		location, err = p.CFClient.GetAppLocation(event.AppID)
+		if err != nil {
+			return err
+		}
+		spaceApps, err = p.CFClient.CountSpaceApps(location.SpaceID)
+		if err != nil {
+			return err
+		}
+		secGroups, err = p.CFClient.ListSecGroups()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if spaceApps > 1 {
+		return fmt.Errorf("space %s holds %d other apps whose egress would be blackholed too", location.SpaceID, spaceApps-1)
+	}
+	blackhole, bound, err := utils.BlackholeGroups(secGroups, p.Conf.BlackholeGroup, location.SpaceID)
+	if err != nil {
+		return err
+	}
+
+	snapshot := model.SecGroupSnapshot{ID: utils.NewID(), AppID: event.AppID, SpaceID: location.SpaceID, BlackholeID: blackhole, Groups: bound,
+		Owner: p.Conf.InstanceIndex, CreatedAt: utils.TimeNow()}
+	err = utils.SaveSecGroupSnapshot(p.DB, snapshot)
+	if err != nil {
+		return err
+	}
+
+	if p.Conf.MaxBlackhole > 0 && delay > p.Conf.MaxBlackhole {
+		delay = p.Conf.MaxBlackhole
+	}
+	fmt.Printf("Blackholing egress of space: %s, unbinding %d security groups and restarting app: %s\n", location.SpaceID, len(bound), event.AppID)
+	started := time.Now()
+	err = callWithContext(ctx, func() error {
+		err := p.CFClient.BindSecGroup(blackhole, location.SpaceID)
+		if err != nil {
+			return err
+		}
+		for _, guid := range bound {
+			err = p.CFClient.UnbindSecGroup(guid, location.SpaceID)
+			if err != nil {
+				return err
+			}
+		}
+		return p.restartApp(event.AppID)
+	})
+	if delay -= time.Since(started); delay < 0 {
+		delay = 0
+	}
+	return p.restore(event, err, delay, fmt.Sprintf("security groups of space %s could not be restored", location.SpaceID), func() error {
+		return p.restoreSecGroups(snapshot)
+	})
+}
+
+// restoreSecGroups - binds the security groups of a snapshot to its space again, unbinds the blackhole group and restarts
+// the app so that its containers pick the groups up
+func (p *Processor) restoreSecGroups(snapshot model.SecGroupSnapshot) error {
+	for _, guid := range snapshot.Groups {
+		err := p.CFClient.BindSecGroup(guid, snapshot.SpaceID)
+		if err != nil {
+			return err
+		}
+	}
+	err := p.CFClient.UnbindSecGroup(snapshot.BlackholeID, snapshot.SpaceID)
+	if err != nil {
+		return err
+	}
+	err = p.restartApp(snapshot.AppID)
+	if err != nil {
+		return err
+	}
+	return utils.DeleteSecGroupSnapshot(p.DB, snapshot.ID)
+}
+
+// RestoreSecGroups - binds again the security groups this processor unbound but never restored
+func (p *Processor) RestoreSecGroups() error {
+	snapshots, err := utils.GetSecGroupSnapshots(p.DB, p.Conf.InstanceIndex)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		fmt.Printf("Security groups of space: %s were never restored, restoring %d groups\n", snapshot.SpaceID, len(snapshot.Groups))
+		err = p.restoreSecGroups(snapshot)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restartApp - stops and starts an app
+func (p *Processor) restartApp(appID string) error {
+	err := p.CFClient.UpdateAppState(appID, "STOPPED")
+	if err != nil {
+		return err
+	}
+	return p.CFClient.UpdateAppState(appID, "STARTED")
+}
+
+// cancelTask - cancels a task unless it has already finished
+func (p *Processor) cancelTask(guid string) error {
+	task, err := p.CFClient.GetTask(guid)
+	if err != nil {
+		return err
+	}
+	if utils.TaskFinished(task) {
+		fmt.Printf("Task %s %s\n", guid, strings.ToLower(task.State))
+		return nil
+	}
+	fmt.Printf("Cancelling task %s\n", guid)
+	return p.CFClient.CancelTask(guid)
+}
+
+// deploying - returns why chaos must wait for the deploy of an app, while it is staging or until the deploy cooldown has
+// passed since it was pushed, updated or restaged by anyone but the processor, blank if it need not
+func (p *Processor) deploying(ctx context.Context, appID string) (string, error) {
+	var reason string
+	now := time.Now()
+	err := callWithContext(ctx, func() error {
+		appState, err := p.CFClient.GetAppState(appID)
+		if err != nil {
+			return err
+		}
+		if reason = utils.DeployingReason(appState, p.Conf.DeployCooldown, now); reason != "" || p.Conf.DeployCooldown == 0 {
+			return nil
+		}
+		deploys, err := p.CFClient.CountAppDeploys(appID, now.Add(-p.Conf.DeployCooldown))
+		if err != nil {
+			return err
+		}
+		if deploys > 0 {
+			reason = fmt.Sprintf("app was updated or restaged %d times within the deploy cooldown of %s", deploys, p.Conf.DeployCooldown)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return reason, nil
+}
+
+// snapshotAfter - describes the state of an app after an action with a timeout of its own, as the action may have used up the
+// context of its run, blank if it cannot be fetched
+func (p *Processor) snapshotAfter(appID string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), p.Conf.AppTimeout)
+	defer cancel()
+	_, after, err := p.snapshot(ctx, appID)
+	logError(err)
+	return after
+}
+
+// snapshot - returns the state of an app and a description of it and its instances, which a stopped app does not have
+func (p *Processor) snapshot(ctx context.Context, appID string) (model.AppState, string, error) {
+	var (
+		appState     model.AppState
+		appInstances map[string]cfclient.AppInstance
+	)
+	err := callWithContext(ctx, func() error {
+		var err error
+		appState, err = p.CFClient.GetAppState(appID)
+		if err != nil {
+			return err
+		}
+		appInstances, _ = p.CFClient.GetAppInstances(appID)
+		return nil
+	})
+	if err != nil {
+		return model.AppState{}, "", err
+	}
+	return appState, utils.DescribeState(appState, appInstances), nil
+}
+
+// watchesRecovery - determines if the recovery of an app from an action is watched
+func (p *Processor) watchesRecovery(action string) bool {
+	return p.Conf.RecoveryTimeout > 0 && !sharedModel.KeepsInstances(action)
+}
+
+// watchRecovery - polls a killed app instance in the background until it is running again or the recovery timeout passes,
+// pausing chaos for the binding if the app does not recover or crash loops. Adaptive chaos is raised after a recovery within
+// the threshold of the service instance and backed off when the app does not recover or crash loops.
+func (p *Processor) watchRecovery(service model.Service, event sharedModel.Event, eventID int64, killedAt time.Time) {
+	p.watchers.Add(1)
+	go func() {
+		defer p.watchers.Done()
+		recovery, recoverySeconds, watched := p.awaitRecovery(event.AppID, event.InstanceIndex, killedAt)
+		if !watched {
+			return
+		}
+		fmt.Printf("App instance: %s at index: %s %s after %.1f seconds\n", event.AppID, event.InstanceIndex, recovery, recoverySeconds)
+		err := sharedUtils.UpdateEventRecovery(p.DB, eventID, recovery, recoverySeconds)
+		logError(err)
+
+		reason := fmt.Sprintf("instance %s did not recover within %.0f seconds", event.InstanceIndex, p.Conf.RecoveryTimeout.Seconds())
+		if strings.Contains(event.InstanceIndex, ",") {
+			reason = fmt.Sprintf("instances %s did not recover within %.0f seconds", event.InstanceIndex, p.Conf.RecoveryTimeout.Seconds())
+		}
+		if recovery == sharedModel.RecoveryRecovered {
+			reason = p.crashLoopReason(event.AppID, killedAt)
+		}
+		if reason != "" {
+			p.pause(event, reason)
+		}
+		if service.Settings.Adaptive() {
+			p.adaptToRecovery(service, event, recoverySeconds, reason)
+		}
+	}()
+}
+
+// adaptToRecovery - raises or backs off adaptive chaos after the recovery of an app from an event, holding it when the app
+// recovered more slowly than the threshold of the service instance
+func (p *Processor) adaptToRecovery(service model.Service, event sharedModel.Event, recoverySeconds float64, failure string) {
+	if failure != "" {
+		p.adapt(service, event.CreatedAt, sharedModel.ProbabilityBackedOff, failure)
+		return
+	}
+	recovered := fmt.Sprintf("instance %s recovered in %.0f seconds", event.InstanceIndex, recoverySeconds)
+	if strings.Contains(event.InstanceIndex, ",") {
+		recovered = fmt.Sprintf("instances %s recovered in %.0f seconds", event.InstanceIndex, recoverySeconds)
+	}
+	if recoverySeconds > float64(service.Settings.MaxRecoverySeconds) {
+		fmt.Printf("Holding chaos for %s: %s, the threshold is %d\n", event.AppID, recovered, service.Settings.MaxRecoverySeconds)
+		return
+	}
+	p.adapt(service, event.CreatedAt, sharedModel.ProbabilityRaised, recovered)
+}
+
+// adapt - raises or backs off the effective probability of a service instance running adaptive chaos after a run that started at
+// ranAt. A run changes it at most once, except that backing off overrides a raise, so that the victims of one run do not compound.
+// The last change is read and the next recorded under a lock shared by every processor instance.
+func (p *Processor) adapt(service model.Service, ranAt string, kind string, reason string) {
+	unlock, err := utils.LockAdaptive(p.DB, p.Conf.AppTimeout)
+	if logError(err) {
+		return
+	}
+	defer unlock()
+
+	changes, err := sharedUtils.GetProbabilityChanges(p.DB, service.ServiceInstanceID, 1)
+	if logError(err) {
+		return
+	}
+	var last sharedModel.ProbabilityChange
+	if len(changes) > 0 {
+		last = changes[0]
+	}
+	if last.ChangedAt >= ranAt && (kind == sharedModel.ProbabilityRaised || last.Kind == sharedModel.ProbabilityBackedOff) {
+		return
+	}
+
+	current := sharedUtils.EffectiveProbability(service.Settings, service.Probability, last)
+	probability := sharedUtils.RaiseProbability(service.Settings, service.Probability, current)
+	if kind == sharedModel.ProbabilityBackedOff {
+		probability = sharedUtils.BackOffProbability(service.Settings, service.Probability, current)
+	}
+	if probability == current {
+		return
+	}
+	err = sharedUtils.RecordProbabilityChange(p.DB, sharedModel.ProbabilityChange{ServiceInstanceID: service.ServiceInstanceID, AppID: service.AppID,
+		Kind: kind, Probability: probability, Reason: reason, ChangedAt: utils.TimeNow()})
+	if !logError(err) {
+		fmt.Printf("Chaos for service instance %s %s to a probability of %v: %s\n", service.ServiceInstanceID, kind, probability, reason)
+	}
+}
+
+// crashLoopReason - returns why an app is crash looping since a kill, blank if it is not
+func (p *Processor) crashLoopReason(appID string, killedAt time.Time) string {
+	ctx, cancel := context.WithTimeout(context.Background(), p.Conf.AppTimeout)
+	defer cancel()
+
+	var appInstances map[string]cfclient.AppInstance
+	err := callWithContext(ctx, func() error {
+		var err error
+		appInstances, err = p.CFClient.GetAppInstances(appID)
+		return err
+	})
+	if !logError(err) {
+		for _, index := range utils.InstanceIndexes(appInstances) {
+			if appInstances[strconv.Itoa(index)].State == "FLAPPING" {
+				return fmt.Sprintf("instance %d is crash looping", index)
+			}
+		}
+	}
+
+	var crashes int
+	err = callWithContext(ctx, func() error {
+		var err error
+		crashes, err = p.CFClient.CountAppCrashes(appID, killedAt)
+		return err
+	})
+	if logError(err) {
+		return ""
+	}
+	if crashes >= p.Conf.CrashThreshold {
+		return fmt.Sprintf("app crashed %d times since the kill, the threshold is %d", crashes, p.Conf.CrashThreshold)
+	}
+	return ""
+}
+
+// pause - pauses chaos for the binding of an event and alerts the configured webhook
+func (p *Processor) pause(event sharedModel.Event, reason string) {
+	pause := sharedModel.PausedBinding{
+		ServiceBindingID:  event.ServiceBindingID,
+		ServiceInstanceID: event.ServiceInstanceID,
+		AppID:             event.AppID,
+		PausedAt:          utils.TimeNow(),
+		Reason:            reason,
+	}
+	fmt.Printf("Pausing chaos for %s: %s\n", event.AppID, reason)
+	err := sharedUtils.PauseBinding(p.DB, pause)
+	logError(err)
+
+	if p.Conf.AlertURL != "" {
+		err = utils.SendAlert(p.alerts, p.Conf.AlertURL, pause)
+		logError(err)
+	}
+}
+
+// awaitRecovery - returns how the app instances at a comma separated list of indexes recovered, taking the slowest,
+// or false if the processor was stopped first
+func (p *Processor) awaitRecovery(appID string, indexes string, killedAt time.Time) (string, float64, bool) {
+	timeout := time.NewTimer(p.Conf.RecoveryTimeout)
+	defer timeout.Stop()
+	poll := time.NewTicker(p.Conf.RecoveryPoll)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return "", 0, false
+		case <-timeout.C:
+			return sharedModel.RecoveryTimedOut, p.Conf.RecoveryTimeout.Seconds(), true
+		case <-poll.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.Conf.AppTimeout)
+			var appStats map[string]model.AppStats
+			err := callWithContext(ctx, func() error {
+				var err error
+				appStats, err = p.CFClient.GetAppStats(appID)
+				return err
+			})
+			cancel()
+			if err != nil {
+				continue
+			}
+			if recoverySeconds, recovered := slowestRecovery(appStats, indexes, time.Since(killedAt)); recovered {
+				return sharedModel.RecoveryRecovered, recoverySeconds, true
+			}
+		}
+	}
+}
+
+// slowestRecovery - returns the longest time the app instances at a comma separated list of indexes took to run again,
+// or false if any is not yet running again
+func slowestRecovery(appStats map[string]model.AppStats, indexes string, sinceKill time.Duration) (float64, bool) {
+	var slowest float64
+	for _, index := range strings.Split(indexes, ",") {
+		recoverySeconds, recovered := utils.RecoveredAfter(appStats[index], sinceKill)
+		if !recovered {
+			return 0, false
+		}
+		if recoverySeconds > slowest {
+			slowest = recoverySeconds
+		}
+	}
+	return slowest, true
+}
+
+// recordEvent - records the outcome of a run, returning the id of the event or 0 if it could not be recorded
+func (p *Processor) recordEvent(event sharedModel.Event, outcome string, reason string) int64 {
+	event.Outcome = outcome
+	event.Reason = reason
+	id, err := sharedUtils.RecordEvent(p.DB, event)
+	logError(err)
+	return id
+}
+
+// validatePlugin - asks the plugin taking the action of an event whether it can act on the app, returning the outcome and the
+// reason to record when it cannot, a blank reason when it can
+func (p *Processor) validatePlugin(event sharedModel.Event, settings sharedModel.InstanceSettings) (string, string) {
+	plugin, ok := p.Plugins[sharedModel.PluginName(event.Action)]
+	if !ok {
+		return sharedModel.OutcomeFailed, fmt.Sprintf("no plugin named %s is installed", sharedModel.PluginName(event.Action))
+	}
+	response, err := utils.CallPlugin(plugin, pluginRequest(model.PluginValidate, event, settings), p.Conf.PluginTimeout)
+	if err != nil {
+		return sharedModel.OutcomeFailed, fmt.Sprintf("plugin %s could not validate the app: %s", plugin.Name, err.Error())
+	}
+	if !response.Valid {
+		return sharedModel.OutcomeSkipped, fmt.Sprintf("plugin %s cannot act on the app: %s", plugin.Name, response.Reason)
+	}
+	return "", ""
+}
+
+// runPlugin - runs the action of a plugin against an app and rolls it back after the action delay, handing the plugin the
+// state it answered with. The state is saved in between so that a processor restarted in the meantime still rolls the action
+// back, and a plugin that fails to run is asked to roll back straight away in case it acted in part.
+func (p *Processor) runPlugin(event sharedModel.Event, settings sharedModel.InstanceSettings, delay time.Duration) error {
+	plugin, ok := p.Plugins[sharedModel.PluginName(event.Action)]
+	if !ok {
+		return fmt.Errorf("no plugin named %s is installed", sharedModel.PluginName(event.Action))
+	}
+
+	fmt.Printf("Running plugin %s against app: %s\n", plugin.Name, event.AppID)
+	response, err := utils.CallPlugin(plugin, pluginRequest(model.PluginRun, event, settings), p.Conf.PluginTimeout)
+	snapshot := model.PluginSnapshot{ID: utils.NewID(), Plugin: plugin.Name, AppID: event.AppID, State: response.State, Owner: p.Conf.InstanceIndex, CreatedAt: utils.TimeNow()}
+	if err == nil {
+		err = utils.SavePluginSnapshot(p.DB, snapshot)
+	}
+
+	rollback := pluginRequest(model.PluginRollback, event, settings)
+	rollback.State = response.State
+	return p.restore(event, err, delay, fmt.Sprintf("plugin %s could not roll back", plugin.Name), func() error {
+		_, err := utils.CallPlugin(plugin, rollback, p.Conf.PluginTimeout)
+		if err != nil {
+			return err
+		}
+		return utils.DeletePluginSnapshot(p.DB, snapshot.ID)
+	})
+}
+
+// RollbackPlugins - rolls back the plugin actions this processor ran but never rolled back
+func (p *Processor) RollbackPlugins() error {
+	snapshots, err := utils.GetPluginSnapshots(p.DB, p.Conf.InstanceIndex)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		plugin, ok := p.Plugins[snapshot.Plugin]
+		if !ok {
+			return fmt.Errorf("plugin %s is no longer installed, its action on app %s cannot be rolled back", snapshot.Plugin, snapshot.AppID)
+		}
+		fmt.Printf("Plugin %s never rolled back its action on app: %s, rolling back\n", snapshot.Plugin, snapshot.AppID)
+		_, err = utils.CallPlugin(plugin, model.PluginRequest{Command: model.PluginRollback, AppID: snapshot.AppID, State: snapshot.State}, p.Conf.PluginTimeout)
+		if err != nil {
+			return err
+		}
+		err = utils.DeletePluginSnapshot(p.DB, snapshot.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pluginRequest - returns the request sending a command to the plugin taking the action of an event
+func pluginRequest(command string, event sharedModel.Event, settings sharedModel.InstanceSettings) model.PluginRequest {
+	return model.PluginRequest{
+		Command:           command,
+		AppID:             event.AppID,
+		ServiceInstanceID: event.ServiceInstanceID,
+		ServiceBindingID:  event.ServiceBindingID,
+		Seed:              event.Seed,
+		Intensity:         settings.Intensity,
+		DelaySeconds:      settings.ActionDelay,
+	}
+}
+
+// actionDelay - returns how long an app is left stopped or scaled down, or has its resources exhausted, by an action
+func actionDelay(settings sharedModel.InstanceSettings) time.Duration {
+	return time.Duration(settings.ActionDelay) * time.Second
+}
+
+// restoredAfterDelay - determines if an action keeps its victims down until the app is restored after the action delay,
+// so that their recovery is measured from the restore rather than from the action
+func restoredAfterDelay(action string) bool {
+	return action == sharedModel.ActionStopStart || action == sharedModel.ActionScale
+}
+
+// haltReason - describes a halt as the reason chaos was skipped
+func haltReason(halt sharedModel.Halt) string {
+	return fmt.Sprintf("chaos halted by %s: %s", halt.SetBy, halt.Reason)
+}
+
+// abandonedCall - the error of a Cloud Foundry call given up on because its context was done first. The call keeps running
+// and may still land, done is closed once it returns with its error in callErr.
+type abandonedCall struct {
+	err     error
+	done    chan struct{}
+	callErr error
+}
+
+func (a *abandonedCall) Error() string {
+	return a.err.Error()
+}
+
+// callWithContext - runs a blocking Cloud Foundry call, returning an abandonedCall with the context error if ctx is done first
+func callWithContext(ctx context.Context, call func() error) error {
+	abandoned := &abandonedCall{done: make(chan struct{})}
+	go func() {
+		abandoned.callErr = call()
+		close(abandoned.done)
+	}()
+
+	select {
+	case <-abandoned.done:
+		return abandoned.callErr
+	case <-ctx.Done():
+		abandoned.err = ctx.Err()
+		return abandoned
+	}
+}
+
+func logError(err error) bool {
+	if err != nil {
+		fmt.Println("An error has occured")
+		fmt.Println(err.Error())
+		return true
+	}
+	return false
+}
@@ -0,0 +1,2338 @@
+package chaos_test
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/chaos"
+	"github.com/FidelityInternational/chaos-galago/processor/config"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	"github.com/cloudfoundry-community/go-cfclient"
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	probeColumns           = []string{"id", "serviceInstanceID", "url", "expectedStatus", "maxLatencyMs", "bodyMatch"}
+	pauseColumns           = []string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}
+	haltColumns            = []string{"halted", "setBy", "reason", "setAt"}
+	changeColumns          = []string{"id", "serviceInstanceID", "appID", "kind", "probability", "reason", "changedAt"}
+	replayColumns          = []string{"serviceBindingID", "serviceInstanceID", "appID", "eventID", "seed", "requestedAt"}
+	scheduledChangeColumns = []string{"id", "serviceInstanceID", "applyAt", "probability", "frequency", "state", "appliedAt", "revertsID", "replacedProbability", "replacedFrequency"}
+)
+
+// below - matches recovery seconds under a bound
+type below float64
+
+func (b below) Match(v driver.Value) bool {
+	seconds, ok := v.(float64)
+	return ok && seconds < float64(b)
+}
+
+// recordedSeed - matches any seed, keeping the last one recorded
+type recordedSeed struct {
+	seed *int64
+}
+
+func (r recordedSeed) Match(v driver.Value) bool {
+	seed, ok := v.(int64)
+	if ok {
+		*r.seed = seed
+	}
+	return ok
+}
+
+// installPlugin - writes a plugin named feature-flag answering validate and run as given, failing to roll back when
+// rollbackFails, and logging every request it reads to the returned file
+func installPlugin(processor *chaos.Processor, dir string, validate string, run string, rollbackFails bool) string {
+	requests := filepath.Join(dir, "requests")
+	rollback := `echo '{}'`
+	if rollbackFails {
+		rollback = `echo 'flag service unavailable' >&2; exit 1`
+	}
+	script := fmt.Sprintf(`#!/bin/sh
+request=$(cat)
+echo "$request" >> %s
+case "$request" in
+*'"command":"validate"'*) %s ;;
+*'"command":"run"'*) %s ;;
+*) %s ;;
+esac
+`, requests, validate, run, rollback)
+	path := filepath.Join(dir, "feature-flag")
+	Expect(ioutil.WriteFile(path, []byte(script), 0755)).To(Succeed())
+	processor.Plugins["feature-flag"] = model.Plugin{Name: "feature-flag", Path: path}
+	processor.Conf.PluginTimeout = 5 * time.Second
+	return requests
+}
+
+// pluginRequests - reads the requests a plugin installed by installPlugin has logged
+func pluginRequests(requests string) []model.PluginRequest {
+	var logged []model.PluginRequest
+	contents, _ := ioutil.ReadFile(requests)
+	for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+		var request model.PluginRequest
+		Expect(json.Unmarshal([]byte(line), &request)).To(Succeed())
+		logged = append(logged, request)
+	}
+	return logged
+}
+
+// pluginCommands - returns the commands of logged plugin requests
+func pluginCommands(requests []model.PluginRequest) []string {
+	var commands []string
+	for _, request := range requests {
+		commands = append(commands, request.Command)
+	}
+	return commands
+}
+
+type fakeCFClient struct {
+	mutex        sync.Mutex
+	instances    map[string]cfclient.AppInstance
+	instancesErr error
+	stats        map[string]model.AppStats
+	statsErr     error
+	crashes      int
+	crashesErr   error
+	deploys      int
+	deploysErr   error
+	location     model.AppLocation
+	locationErr  error
+	locationWait time.Duration
+	spaceApps    int
+	appState     model.AppState
+	appStateErr  error
+	appStateOK   int
+	actionErr    error
+	restoreErr   error
+	taskState    string
+	taskErr      error
+	tasks        []model.Task
+	cancelled    []string
+	policies     []model.Policy
+	policiesErr  error
+	mappings     []model.RouteMapping
+	secGroups    []cfclient.SecGroup
+	delay        time.Duration
+	actionDelay  time.Duration
+	killed       []string
+	actions      []string
+}
+
+func (f *fakeCFClient) GetAppInstances(guid string) (map[string]cfclient.AppInstance, error) {
+	time.Sleep(f.delay)
+	return f.instances, f.instancesErr
+}
+
+func (f *fakeCFClient) GetAppStats(guid string) (map[string]model.AppStats, error) {
+	return f.stats, f.statsErr
+}
+
+func (f *fakeCFClient) KillAppInstance(guid string, index string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.killed = append(f.killed, fmt.Sprintf("%s/%s", guid, index))
+	return nil
+}
+
+func (f *fakeCFClient) CountAppCrashes(guid string, since time.Time) (int, error) {
+	return f.crashes, f.crashesErr
+}
+
+func (f *fakeCFClient) CountAppDeploys(guid string, since time.Time) (int, error) {
+	return f.deploys, f.deploysErr
+}
+
+func (f *fakeCFClient) GetAppLocation(guid string) (model.AppLocation, error) {
+	time.Sleep(f.locationWait)
+	return f.location, f.locationErr
+}
+
+func (f *fakeCFClient) CountSpaceApps(spaceGUID string) (int, error) {
+	return f.spaceApps, nil
+}
+
+// GetAppState - returns appStateErr once appStateOK calls have succeeded
+func (f *fakeCFClient) GetAppState(guid string) (model.AppState, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.appStateOK > 0 {
+		f.appStateOK--
+		return f.appState, nil
+	}
+	return f.appState, f.appStateErr
+}
+
+func (f *fakeCFClient) UpdateAppState(guid string, state string) error {
+	return f.act(fmt.Sprintf("%s/%s", guid, state), state == "STOPPED")
+}
+
+func (f *fakeCFClient) ScaleApp(guid string, instances int) error {
+	return f.act(fmt.Sprintf("%s/scale/%d", guid, instances), instances < f.appState.Instances)
+}
+
+func (f *fakeCFClient) RestageApp(guid string) error {
+	return f.act(fmt.Sprintf("%s/restage", guid), true)
+}
+
+func (f *fakeCFClient) RunTask(guid string, task model.Task) (model.Task, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.taskErr != nil {
+		return model.Task{}, f.taskErr
+	}
+	task.GUID = fmt.Sprintf("task-%d", len(f.tasks)+1)
+	task.State = "RUNNING"
+	f.tasks = append(f.tasks, task)
+	return task, nil
+}
+
+func (f *fakeCFClient) GetTask(guid string) (model.Task, error) {
+	return model.Task{GUID: guid, State: f.taskState}, nil
+}
+
+func (f *fakeCFClient) CancelTask(guid string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.cancelled = append(f.cancelled, guid)
+	return f.restoreErr
+}
+
+func (f *fakeCFClient) Tasks() []model.Task {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.tasks
+}
+
+func (f *fakeCFClient) Cancelled() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.cancelled
+}
+
+func (f *fakeCFClient) ListPolicies(guid string) ([]model.Policy, error) {
+	return f.policies, f.policiesErr
+}
+
+func (f *fakeCFClient) CreatePolicies(policies []model.Policy) error {
+	return f.act(fmt.Sprintf("policies/create/%d", len(policies)), false)
+}
+
+func (f *fakeCFClient) DeletePolicies(policies []model.Policy) error {
+	return f.act(fmt.Sprintf("policies/delete/%d", len(policies)), true)
+}
+
+func (f *fakeCFClient) ListRouteMappings(guid string) ([]model.RouteMapping, error) {
+	return f.mappings, nil
+}
+
+func (f *fakeCFClient) MapRoute(mapping model.RouteMapping) error {
+	return f.act(fmt.Sprintf("%s/map/%s", mapping.AppID, mapping.RouteID), false)
+}
+
+func (f *fakeCFClient) UnmapRoute(guid string) error {
+	return f.act(fmt.Sprintf("unmap/%s", guid), true)
+}
+
+func (f *fakeCFClient) ListSecGroups() ([]cfclient.SecGroup, error) {
+	return f.secGroups, nil
+}
+
+func (f *fakeCFClient) BindSecGroup(guid string, spaceGUID string) error {
+	return f.act(fmt.Sprintf("%s/bind/%s", spaceGUID, guid), guid == "blackhole")
+}
+
+func (f *fakeCFClient) UnbindSecGroup(guid string, spaceGUID string) error {
+	return f.act(fmt.Sprintf("%s/unbind/%s", spaceGUID, guid), guid != "blackhole")
+}
+
+// act - records an app wide action, failing with actionErr when it disrupts the app and restoreErr when it restores it
+func (f *fakeCFClient) act(action string, disrupts bool) error {
+	if disrupts {
+		time.Sleep(f.actionDelay)
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.actions = append(f.actions, action)
+	if disrupts {
+		return f.actionErr
+	}
+	return f.restoreErr
+}
+
+func (f *fakeCFClient) Actions() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.actions
+}
+
+func (f *fakeCFClient) Killed() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.killed
+}
+
+var _ = Describe("Processor", func() {
+	var (
+		mock      sqlmock.Sqlmock
+		cfClient  *fakeCFClient
+		processor *chaos.Processor
+		service   model.Service
+	)
+
+	BeforeEach(func() {
+		db, sqlMock, err := sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		mock = sqlMock
+		cfClient = &fakeCFClient{
+			instances: map[string]cfclient.AppInstance{
+				"0": {State: "RUNNING"},
+				"1": {State: "RUNNING"},
+			},
+			appState: model.AppState{State: "STARTED", Instances: 2},
+		}
+		processor = chaos.CreateProcessor(db, cfClient, &config.Config{Workers: 2, AppTimeout: 50 * time.Millisecond, InstanceIndex: "0"})
+		service = model.Service{AppID: "app-1", Probability: 1, Frequency: 5, ServiceInstanceID: "1", ServiceBindingID: "2", Settings: sharedModel.NewInstanceSettings("1")}
+	})
+
+	expectLock := func(name string) {
+		mock.ExpectBegin()
+		mock.ExpectExec("^SET SESSION innodb_lock_wait_timeout = \\?$").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("^SELECT name FROM chaos_locks WHERE name=\\? FOR UPDATE$").WithArgs(name).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(name))
+	}
+
+	AfterEach(func() {
+		processor.DB.Close()
+	})
+
+	Describe("#ProcessService", func() {
+		Context("When the app was processed within its frequency", func() {
+			It("does nothing", func() {
+				service.LastProcessed = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When chaos is paused for the binding", func() {
+			It("does nothing", func() {
+				service.Paused = true
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When a recorded run is replayed", func() {
+			expectKills := func(seed interface{}) {
+				for i := 0; i < 3; i++ {
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), seed, "kill", sqlmock.AnyArg(), "succeeded", "", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			BeforeEach(func() {
+				for i := 2; i < 10; i++ {
+					cfClient.instances[strconv.Itoa(i)] = cfclient.AppInstance{State: "RUNNING"}
+				}
+				service.Settings.Seed = 42
+				service.Settings.KillCount = 3
+			})
+
+			It("kills the instances the recorded run killed, whatever the configured seed and the last run", func() {
+				var seed int64
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				expectKills(recordedSeed{&seed})
+				processor.ProcessService(context.Background(), service)
+				recorded := cfClient.Killed()
+				Expect(recorded).To(HaveLen(3))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+
+				cfClient.killed = nil
+				service.Settings.Seed = 7
+				service.LastProcessed = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+				service.Replay = sharedModel.Replay{ServiceBindingID: "2", ServiceInstanceID: "1", AppID: "app-1", EventID: 1, Seed: seed}
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("^DELETE FROM chaos_replays WHERE serviceBindingID=\\? AND eventID=\\?$").WithArgs("2", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectKills(seed)
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(Equal(recorded))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and another processor instance made the replay first", func() {
+				It("does nothing", func() {
+					service.Replay = sharedModel.Replay{ServiceBindingID: "2", ServiceInstanceID: "1", AppID: "app-1", EventID: 1, Seed: 42}
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("^DELETE FROM chaos_replays").WithArgs("2", 1).WillReturnResult(sqlmock.NewResult(0, 0))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When chaos runs as a Poisson process", func() {
+			var scheduleColumns = []string{"appID", "mtbfMinutes", "nextFailureAt"}
+
+			BeforeEach(func() {
+				service.Settings.MTBFMinutes = 240
+				service.Probability = 0
+				service.LastProcessed = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+			})
+
+			Context("and the next failure is due", func() {
+				It("draws the failure after it and kills an app instance", func() {
+					mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").WithArgs("app-1").
+						WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow("app-1", 240, "2014-11-12T10:31:20Z"))
+					mock.ExpectExec("UPDATE chaos_schedules SET .*").WithArgs(240, sqlmock.AnyArg(), "app-1", 240, "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(HaveLen(1))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				Context("and another processor claimed it first", func() {
+					It("does nothing", func() {
+						mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").
+							WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow("app-1", 240, "2014-11-12T10:31:20Z"))
+						mock.ExpectExec("UPDATE chaos_schedules SET .*").WillReturnResult(sqlmock.NewResult(0, 0))
+						processor.ProcessService(context.Background(), service)
+						Expect(cfClient.Killed()).To(BeEmpty())
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+					})
+				})
+			})
+
+			Context("and the next failure is not due", func() {
+				It("does nothing", func() {
+					mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").
+						WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow("app-1", 240, time.Now().UTC().Add(time.Hour).Format("2006-01-02T15:04:05Z")))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and no failure has been drawn", func() {
+				It("draws the first one without acting", func() {
+					mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").WillReturnRows(sqlmock.NewRows(scheduleColumns))
+					mock.ExpectExec("INSERT IGNORE INTO chaos_schedules .*").WithArgs("app-1", 240, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the mean time between failures has changed", func() {
+				It("draws a failure for the new one without acting", func() {
+					mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").
+						WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow("app-1", 60, "2014-11-12T10:31:20Z"))
+					mock.ExpectExec("UPDATE chaos_schedules SET .*").WithArgs(240, sqlmock.AnyArg(), "app-1", 60, "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(0, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the schedule cannot be read", func() {
+				It("does nothing", func() {
+					mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When chaos should run and the app is healthy", func() {
+			It("records an intent and kills an app instance", func() {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", sqlmock.AnyArg(), "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "succeeded", "", "", "",
+					"STARTED with 2 instances (2 RUNNING)", "STARTED with 2 instances (2 RUNNING)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(HaveLen(1))
+				Expect(cfClient.Killed()[0]).To(MatchRegexp("^app-1/[01]$"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and chaos is adaptive", func() {
+				BeforeEach(func() {
+					service.Settings.AdaptiveStep = 5
+					service.LastChange = sharedModel.ProbabilityChange{ID: 3, Probability: 1}
+				})
+
+				It("raises the probability by a step once the action succeeds", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnResult(sqlmock.NewResult(7, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					expectLock("adaptive")
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 1).
+						WillReturnRows(sqlmock.NewRows(changeColumns).AddRow(3, "1", "app-1", "raised", 0.5, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z"))
+					mock.ExpectExec("INSERT INTO probability_changes.*").WithArgs("1", "app-1", "raised", 0.55, "kill succeeded without breaking the steady state", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(4, 1))
+					mock.ExpectCommit()
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(HaveLen(1))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				It("records no change once the probability reaches the configured one", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnResult(sqlmock.NewResult(7, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					expectLock("adaptive")
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 1).
+						WillReturnRows(sqlmock.NewRows(changeColumns).AddRow(3, "1", "app-1", "raised", 1, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z"))
+					mock.ExpectCommit()
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(HaveLen(1))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the intent cannot be recorded", func() {
+				It("does not kill an app instance", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnError(fmt.Errorf("An error has occurred: %s", "INSERT error"))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the action is app wide", func() {
+			var expectIntent = func(action string, index string) {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", index, "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), action, "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			var expectEvent = func(action string, index string, outcome string, reason string) {
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), action, index, outcome, reason, "", "",
+					"STARTED with 2 instances (2 RUNNING)", "STARTED with 2 instances (2 RUNNING)").WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			var expectAction = func(action string, index string, outcome string, reason string) {
+				expectIntent(action, index)
+				expectEvent(action, index, outcome, reason)
+			}
+
+			var expectStateSnapshot = func(action string, restored bool) {
+				mock.ExpectExec("INSERT INTO state_snapshots.*").WithArgs(sqlmock.AnyArg(), "app-1", action, "STARTED", 2, "0", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				if restored {
+					mock.ExpectExec("DELETE FROM state_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+			}
+
+			BeforeEach(func() {
+				service.Settings.ActionDelay = 0
+				service.Settings.MinHealthy = 0
+			})
+
+			It("restarts the app", func() {
+				service.Settings.Action = sharedModel.ActionRestart
+				expectAction("restart", "0,1", "succeeded", "")
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Actions()).To(Equal([]string{"app-1/STOPPED", "app-1/STARTED"}))
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("stops the app and starts it after the delay", func() {
+				service.Settings.Action = sharedModel.ActionStopStart
+				service.Settings.ActionDelay = 1
+				expectIntent("stop-start", "0,1")
+				expectStateSnapshot("stop-start", true)
+				expectEvent("stop-start", "0,1", "succeeded", "")
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				started := time.Now()
+				processor.ProcessService(context.Background(), service)
+				Expect(time.Since(started)).To(BeNumerically(">=", time.Second))
+				Expect(cfClient.Actions()).To(Equal([]string{"app-1/STOPPED", "app-1/STARTED"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("restages the app", func() {
+				service.Settings.Action = sharedModel.ActionRestage
+				expectAction("restage", "0,1", "succeeded", "")
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Actions()).To(Equal([]string{"app-1/restage"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("takes the action sampled from the profile instead of the configured action", func() {
+				service.Profile = []sharedModel.WeightedAction{{Action: sharedModel.ActionKill, Weight: 0}, {Action: sharedModel.ActionRestage, Weight: 1}}
+				expectAction("restage", "0,1", "succeeded", "")
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Actions()).To(Equal([]string{"app-1/restage"}))
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("scales the app down by the victims and back up", func() {
+				service.Settings.Action = sharedModel.ActionScale
+				expectIntent("scale", "1")
+				expectStateSnapshot("scale", true)
+				expectEvent("scale", "1", "succeeded", "")
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Actions()).To(Equal([]string{"app-1/scale/1", "app-1/scale/2"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and the app cannot be stopped", func() {
+				It("starts it again and records the action as failed", func() {
+					service.Settings.Action = sharedModel.ActionStopStart
+					cfClient.actionErr = fmt.Errorf("An error has occurred: %s", "API error")
+					expectIntent("stop-start", "0,1")
+					expectStateSnapshot("stop-start", true)
+					expectEvent("stop-start", "0,1", "failed", "An error has occurred: API error")
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(Equal([]string{"app-1/STOPPED", "app-1/STARTED"}))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and stopping the app times out", func() {
+				It("starts it again only once the stop has returned", func() {
+					service.Settings.Action = sharedModel.ActionStopStart
+					cfClient.actionDelay = 200 * time.Millisecond
+					expectIntent("stop-start", "0,1")
+					expectStateSnapshot("stop-start", true)
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "stop-start", "0,1", "succeeded", "", "", "",
+						"STARTED with 2 instances (2 RUNNING)", "STARTED with 2 instances (2 RUNNING)").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+					defer cancel()
+					processor.ProcessService(ctx, service)
+					Expect(cfClient.Actions()).To(Equal([]string{"app-1/STOPPED", "app-1/STARTED"}))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the action times out", func() {
+				It("records the outcome as unknown until the call returns and skips the app until then", func() {
+					service.Settings.Action = sharedModel.ActionRestage
+					cfClient.actionDelay = 200 * time.Millisecond
+					expectIntent("restage", "0,1")
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "restage", "0,1", "unknown", "context deadline exceeded", "", "",
+						"STARTED with 2 instances (2 RUNNING)", "STARTED with 2 instances (2 RUNNING)").WillReturnResult(sqlmock.NewResult(7, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("unknown", 7, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "restage", "", "skipped", "an earlier action on the app timed out and has not returned", "", "",
+						"", "").WillReturnResult(sqlmock.NewResult(8, 1))
+					mock.ExpectExec("UPDATE chaos_events SET outcome=\\?, reason=\\? WHERE id=\\?").WithArgs("succeeded", "", 7).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+					defer cancel()
+					processor.ProcessService(ctx, service)
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(cfClient.Actions()).To(Equal([]string{"app-1/restage"}))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the state of the app cannot be saved", func() {
+				It("does not scale the app and records the action as failed", func() {
+					service.Settings.Action = sharedModel.ActionScale
+					expectIntent("scale", "1")
+					mock.ExpectExec("INSERT INTO state_snapshots.*").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					expectEvent("scale", "1", "failed", "An error has occurred: DB error")
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the app cannot be restored", func() {
+				It("pauses chaos for the binding and records the action as failed", func() {
+					service.Settings.Action = sharedModel.ActionScale
+					cfClient.restoreErr = fmt.Errorf("An error has occurred: %s", "API error")
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					expectStateSnapshot("scale", false)
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "app could not be scaled back to 2 instances: An error has occurred: API error").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "scale", "1", "failed", "app could not be scaled back to 2 instances: An error has occurred: API error", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and a minimum number of healthy instances is set", func() {
+				It("records the run as skipped", func() {
+					service.Settings.Action = sharedModel.ActionRestart
+					service.Settings.MinHealthy = 1
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "restart", "", "skipped", "restart would leave fewer than 1 healthy instances", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the state of the app cannot be fetched", func() {
+				It("records the action as failed without taking it", func() {
+					service.Settings.Action = sharedModel.ActionRestage
+					cfClient.appStateErr = fmt.Errorf("An error has occurred: %s", "API error")
+					cfClient.appStateOK = 1
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "restage", "0,1", "failed", "An error has occurred: API error", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the action exhausts the resources of the app", func() {
+			var expectTask = func(action string, outcome string, reason string) {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", "", "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), action, "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), action, "", outcome, reason, "", "",
+					sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			BeforeEach(func() {
+				service.Settings.ActionDelay = 0
+				service.Settings.Intensity = 50
+				cfClient.appState.Memory = 256
+				cfClient.taskState = "RUNNING"
+			})
+
+			It("runs a task burning CPU and cancels it after the duration", func() {
+				service.Settings.Action = sharedModel.ActionCPU
+				service.Settings.ActionDelay = 1
+				expectTask("cpu-burn", "succeeded", "")
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				started := time.Now()
+				processor.ProcessService(context.Background(), service)
+				Expect(time.Since(started)).To(BeNumerically(">=", time.Second))
+				Expect(cfClient.Tasks()).To(HaveLen(1))
+				Expect(cfClient.Tasks()[0].Name).To(Equal("chaos-galago-cpu-burn"))
+				Expect(cfClient.Tasks()[0].Command).To(ContainSubstring("timeout 1 "))
+				Expect(cfClient.Cancelled()).To(Equal([]string{"task-1"}))
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("runs a task holding memory sized to the app", func() {
+				service.Settings.Action = sharedModel.ActionMemory
+				expectTask("memory-pressure", "succeeded", "")
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Tasks()).To(HaveLen(1))
+				Expect(cfClient.Tasks()[0].MemoryInMB).To(Equal(256))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("runs even when a minimum number of healthy instances is set", func() {
+				service.Settings.Action = sharedModel.ActionDisk
+				service.Settings.MinHealthy = 2
+				expectTask("disk-fill", "succeeded", "")
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Tasks()).To(HaveLen(1))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and the task has already finished", func() {
+				It("does not cancel it", func() {
+					service.Settings.Action = sharedModel.ActionCPU
+					cfClient.taskState = "SUCCEEDED"
+					expectTask("cpu-burn", "succeeded", "")
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Cancelled()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the task cannot be run", func() {
+				It("records the action as failed", func() {
+					service.Settings.Action = sharedModel.ActionCPU
+					cfClient.taskErr = fmt.Errorf("An error has occurred: %s", "API error")
+					expectTask("cpu-burn", "failed", "An error has occurred: API error")
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Cancelled()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the task cannot be cancelled", func() {
+				It("pauses chaos for the binding and records the action as failed", func() {
+					service.Settings.Action = sharedModel.ActionCPU
+					cfClient.restoreErr = fmt.Errorf("An error has occurred: %s", "API error")
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "task task-1 could not be cancelled: An error has occurred: API error").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "cpu-burn", "", "failed", "task task-1 could not be cancelled: An error has occurred: API error", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the processor is stopped during the task", func() {
+				It("cancels the task straight away", func() {
+					service.Settings.Action = sharedModel.ActionCPU
+					service.Settings.ActionDelay = 60
+					expectTask("cpu-burn", "succeeded", "")
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.Stop()
+					started := time.Now()
+					processor.ProcessService(context.Background(), service)
+					Expect(time.Since(started)).To(BeNumerically("<", 5*time.Second))
+					Expect(cfClient.Cancelled()).To(Equal([]string{"task-1"}))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the action partitions the app from a peer", func() {
+			var expectPartition = func(outcome string, reason string) {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", "", "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "network-partition", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			BeforeEach(func() {
+				service.Settings.Action = sharedModel.ActionPartition
+				service.Settings.ActionDelay = 0
+				cfClient.policies = []model.Policy{
+					{Source: model.PolicySource{ID: "app-1"}, Destination: model.PolicyDestination{ID: "app-2", Protocol: "tcp", Ports: model.PolicyPorts{Start: 8080, End: 8080}}},
+					{Source: model.PolicySource{ID: "app-2"}, Destination: model.PolicyDestination{ID: "app-1", Protocol: "tcp", Ports: model.PolicyPorts{Start: 8080, End: 8080}}},
+				}
+			})
+
+			It("removes the policies with the peer and restores them after the delay", func() {
+				service.Settings.MinHealthy = 2
+				expectPartition("succeeded", "")
+				mock.ExpectExec("INSERT INTO policy_snapshots .*").WithArgs(sqlmock.AnyArg(), "app-1", "0", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM policy_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "network-partition", "", "succeeded", "", "", "",
+					sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Actions()).To(Equal([]string{"policies/delete/2", "policies/create/2"}))
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and the app has no policies with other apps", func() {
+				It("records the action as failed", func() {
+					cfClient.policies = nil
+					expectPartition("failed", "")
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "network-partition", "", "failed", "the app has no network policies with other apps", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the policies cannot be saved", func() {
+				It("records the action as failed without removing them", func() {
+					expectPartition("failed", "")
+					mock.ExpectExec("INSERT INTO policy_snapshots .*").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "network-partition", "", "failed", "An error has occurred: DB error", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the policies cannot be restored", func() {
+				It("keeps the snapshot, pauses chaos for the binding and records the action as failed", func() {
+					cfClient.restoreErr = fmt.Errorf("An error has occurred: %s", "API error")
+					expectPartition("failed", "")
+					mock.ExpectExec("INSERT INTO policy_snapshots .*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "network policies with app-2 could not be restored: An error has occurred: API error").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "network-partition", "", "failed", "network policies with app-2 could not be restored: An error has occurred: API error", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the action unmaps a route of the app", func() {
+			BeforeEach(func() {
+				service.Settings.Action = sharedModel.ActionUnmap
+				service.Settings.ActionDelay = 0
+				cfClient.mappings = []model.RouteMapping{{GUID: "mapping-1", AppID: "app-1", RouteID: "route-1", AppPort: 8080}}
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", "", "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "route-unmap", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+			})
+
+			It("unmaps the route and maps it again after the delay", func() {
+				mock.ExpectExec("INSERT INTO mapping_snapshots .*").WithArgs(sqlmock.AnyArg(), "app-1", "route-1", 8080, "0", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM mapping_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "route-unmap", "", "succeeded", "", "", "",
+					sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Actions()).To(Equal([]string{"unmap/mapping-1", "app-1/map/route-1"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and the app has no routes", func() {
+				It("records the action as failed", func() {
+					cfClient.mappings = nil
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "route-unmap", "", "failed", "the app has no routes mapped", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the route cannot be mapped again", func() {
+				It("keeps the snapshot, pauses chaos for the binding and records the action as failed", func() {
+					cfClient.restoreErr = fmt.Errorf("An error has occurred: %s", "API error")
+					mock.ExpectExec("INSERT INTO mapping_snapshots .*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "route route-1 could not be mapped again: An error has occurred: API error").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "route-unmap", "", "failed", "route route-1 could not be mapped again: An error has occurred: API error", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the action blackholes the egress of the app", func() {
+			BeforeEach(func() {
+				processor.Conf.BlackholeGroup = "chaos-galago-blackhole"
+				service.Settings.Action = sharedModel.ActionBlackhole
+				service.Settings.ActionDelay = 0
+				service.Settings.MinHealthy = 0
+				cfClient.location = model.AppLocation{SpaceID: "space-1", OrgID: "org-1"}
+				cfClient.spaceApps = 1
+				cfClient.secGroups = []cfclient.SecGroup{
+					{Guid: "blackhole", Name: "chaos-galago-blackhole"},
+					{Guid: "database", Name: "database", SpacesData: []cfclient.SpaceResource{{Entity: cfclient.Space{Guid: "space-1"}}}},
+				}
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", "0,1", "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "egress-blackhole", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+			})
+
+			It("swaps the security groups of the space for the blackhole and restores them after the delay", func() {
+				mock.ExpectExec("INSERT INTO secgroup_snapshots .*").WithArgs(sqlmock.AnyArg(), "app-1", "space-1", "blackhole", "database", "0", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM secgroup_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "egress-blackhole", "0,1", "succeeded", "", "", "",
+					sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Actions()).To(Equal([]string{
+					"space-1/bind/blackhole", "space-1/unbind/database", "app-1/STOPPED", "app-1/STARTED",
+					"space-1/bind/database", "space-1/unbind/blackhole", "app-1/STOPPED", "app-1/STARTED",
+				}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("limits the blackhole to the maximum duration", func() {
+				service.Settings.ActionDelay = 60
+				processor.Conf.MaxBlackhole = time.Second
+				mock.ExpectExec("INSERT INTO secgroup_snapshots .*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM secgroup_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				started := time.Now()
+				processor.ProcessService(context.Background(), service)
+				Expect(time.Since(started)).To(BeNumerically("<", 5*time.Second))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("counts the time taken to blackhole the app towards the maximum duration", func() {
+				service.Settings.ActionDelay = 60
+				processor.Conf.MaxBlackhole = time.Second
+				processor.Conf.AppTimeout = time.Second
+				cfClient.actionDelay = 400 * time.Millisecond
+				mock.ExpectExec("INSERT INTO secgroup_snapshots .*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM secgroup_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				started := time.Now()
+				processor.ProcessService(context.Background(), service)
+				Expect(time.Since(started)).To(BeNumerically("<", 2*time.Second))
+				Expect(cfClient.Actions()).To(HaveLen(8))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and the space holds other apps", func() {
+				It("records the action as failed without blackholing the space", func() {
+					cfClient.spaceApps = 3
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "egress-blackhole", "0,1", "failed", "space space-1 holds 2 other apps whose egress would be blackholed too", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and no blackhole group is configured", func() {
+				It("records the action as failed", func() {
+					processor.Conf.BlackholeGroup = ""
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "egress-blackhole", "0,1", "failed", "no egress blackhole security group is configured", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the security groups cannot be restored", func() {
+				It("keeps the snapshot, pauses chaos for the binding and records the action as failed", func() {
+					cfClient.restoreErr = fmt.Errorf("An error has occurred: %s", "API error")
+					mock.ExpectExec("INSERT INTO secgroup_snapshots .*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "security groups of space space-1 could not be restored: An error has occurred: API error").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "egress-blackhole", "0,1", "failed", sqlmock.AnyArg(), "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the action is taken by a plugin", func() {
+			var (
+				dir      string
+				validate string
+				run      string
+			)
+
+			var expectIntent = func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", "", "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "plugin:feature-flag", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			BeforeEach(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "chaos-galago-plugins")
+				Expect(err).To(BeNil())
+				validate = `echo '{"valid":true}'`
+				run = `echo '{"state":{"flag":"checkout"}}'`
+				service.Settings.Action = "plugin:feature-flag"
+				service.Settings.ActionDelay = 0
+				service.Settings.MinHealthy = 2
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+
+			It("runs the plugin and rolls it back after the delay with the state it answered", func() {
+				requests := installPlugin(processor, dir, validate, run, false)
+				expectIntent()
+				mock.ExpectExec("INSERT INTO plugin_snapshots .*").WithArgs(sqlmock.AnyArg(), "feature-flag", "app-1", "0", `{"flag":"checkout"}`, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM plugin_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "plugin:feature-flag", "", "succeeded", "", "", "",
+					sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				logged := pluginRequests(requests)
+				Expect(pluginCommands(logged)).To(Equal([]string{"validate", "run", "rollback"}))
+				Expect(logged[1].AppID).To(Equal("app-1"))
+				Expect(logged[1].ServiceBindingID).To(Equal("2"))
+				Expect(logged[2].State).To(MatchJSON(`{"flag":"checkout"}`))
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and no plugin of that name is installed", func() {
+				It("records the run as failed", func() {
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "plugin:feature-flag", "", "failed", "no plugin named feature-flag is installed", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the plugin cannot act on the app", func() {
+				It("records the run as skipped with the reason of the plugin", func() {
+					requests := installPlugin(processor, dir, `echo '{"valid":false,"reason":"the app has no feature flags"}'`, run, false)
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "plugin:feature-flag", "", "skipped", "plugin feature-flag cannot act on the app: the app has no feature flags", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(pluginCommands(pluginRequests(requests))).To(Equal([]string{"validate"}))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the plugin fails to run", func() {
+				It("rolls it back straight away and records the action as failed", func() {
+					requests := installPlugin(processor, dir, validate, `echo '{"error":"flag service unavailable"}'`, false)
+					expectIntent()
+					mock.ExpectExec("DELETE FROM plugin_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "plugin:feature-flag", "", "failed", "flag service unavailable", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(pluginCommands(pluginRequests(requests))).To(Equal([]string{"validate", "run", "rollback"}))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the plugin cannot roll back", func() {
+				It("keeps the snapshot, pauses chaos for the binding and records the action as failed", func() {
+					installPlugin(processor, dir, validate, run, true)
+					expectIntent()
+					mock.ExpectExec("INSERT INTO plugin_snapshots .*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "plugin feature-flag could not roll back: exit status 1: flag service unavailable").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "plugin:feature-flag", "", "failed", "plugin feature-flag could not roll back: exit status 1: flag service unavailable", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When several instances should be killed", func() {
+			BeforeEach(func() {
+				cfClient.instances["2"] = cfclient.AppInstance{State: "RUNNING"}
+				service.Settings.KillCount = 3
+				service.Settings.MinHealthy = 1
+			})
+
+			It("kills as many instances as the minimum healthy count allows", func() {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				for i := 0; i < 2; i++ {
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "succeeded", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(HaveLen(2))
+				Expect(cfClient.Killed()[0]).ToNot(Equal(cfClient.Killed()[1]))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and no instance can be spared", func() {
+				It("records the run as skipped", func() {
+					service.Settings.MinHealthy = 3
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", "killing would leave fewer than 3 healthy instances", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the action is a cell outage", func() {
+			BeforeEach(func() {
+				service.Settings.Action = sharedModel.ActionCell
+				cfClient.instances["2"] = cfclient.AppInstance{State: "RUNNING"}
+				cfClient.stats = map[string]model.AppStats{
+					"0": {Stats: model.InstanceStats{Host: "10.0.0.1"}},
+					"1": {Stats: model.InstanceStats{Host: "10.0.0.2"}},
+					"2": {Stats: model.InstanceStats{Host: "10.0.0.1"}},
+				}
+				service.Settings.MinHealthy = 2
+			})
+
+			It("kills every instance on one cell at once", func() {
+				service.Settings.MinHealthy = 0
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", sqlmock.AnyArg(), "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "cell-outage", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "cell-outage", sqlmock.AnyArg(), "succeeded", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect([][]string{{"app-1/0", "app-1/2"}, {"app-1/1"}}).To(ContainElement(cfClient.Killed()))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and only a cell with fewer instances can be spared", func() {
+				It("takes out that cell", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", "1", "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "cell-outage", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "cell-outage", "1", "succeeded", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(Equal([]string{"app-1/1"}))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and no cell can be spared", func() {
+				It("records the run as skipped", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "cell-outage", "", "skipped", "every cell hosts more than 1 of the healthy instances", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					cfClient.stats["1"] = model.AppStats{Stats: model.InstanceStats{Host: "10.0.0.1"}}
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and every instance is on one cell", func() {
+				It("keeps a healthy instance unless none is required", func() {
+					service.Settings.MinHealthy = sharedModel.NewInstanceSettings("1").MinHealthy
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "cell-outage", "", "skipped", "every cell hosts more than 2 of the healthy instances", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					cfClient.stats["1"] = model.AppStats{Stats: model.InstanceStats{Host: "10.0.0.1"}}
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the app stats cannot be fetched", func() {
+				It("records the run as failed", func() {
+					cfClient.statsErr = fmt.Errorf("An error has occurred: %s", "API error")
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "cell-outage", "", "failed", "An error has occurred: API error", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the recovery of killed instances is watched", func() {
+			BeforeEach(func() {
+				processor.Conf.RecoveryTimeout = 50 * time.Millisecond
+				processor.Conf.RecoveryPoll = 5 * time.Millisecond
+				mock.MatchExpectationsInOrder(false)
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			})
+
+			Context("and the instance is running again", func() {
+				It("records the event as recovered", func() {
+					cfClient.stats = map[string]model.AppStats{
+						"0": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+						"1": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+					}
+					mock.ExpectExec("UPDATE chaos_events SET recovery.*").WithArgs("recovered", sqlmock.AnyArg(), 7).WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the app was stopped until the action delay passed", func() {
+				It("measures the recovery from when the app was started again", func() {
+					service.Settings.Action = sharedModel.ActionStopStart
+					service.Settings.ActionDelay = 1
+					service.Settings.MinHealthy = 0
+					cfClient.stats = map[string]model.AppStats{
+						"0": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+						"1": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+					}
+					mock.ExpectExec("INSERT INTO state_snapshots.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("DELETE FROM state_snapshots.*").WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec("UPDATE chaos_events SET recovery.*").WithArgs("recovered", below(0.5), 7).WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(cfClient.Actions()).To(Equal([]string{"app-1/STOPPED", "app-1/STARTED"}))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the instance does not recover before the timeout", func() {
+				It("records the event as timed out", func() {
+					cfClient.stats = map[string]model.AppStats{
+						"0": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 1000}},
+						"1": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 1000}},
+					}
+					mock.ExpectExec("UPDATE chaos_events SET recovery.*").WithArgs("timed-out", 0.05, 7).WillReturnResult(sqlmock.NewResult(1, 1))
+					service.Settings.Strategy = sharedModel.StrategyNotFirst
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "instance 1 did not recover within 0 seconds").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the app crash loops after recovering", func() {
+				BeforeEach(func() {
+					cfClient.stats = map[string]model.AppStats{
+						"0": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+						"1": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+					}
+					mock.ExpectExec("UPDATE chaos_events SET recovery.*").WithArgs("recovered", sqlmock.AnyArg(), 7).WillReturnResult(sqlmock.NewResult(1, 1))
+				})
+
+				It("pauses chaos when the crashes reach the threshold", func() {
+					processor.Conf.CrashThreshold = 3
+					cfClient.crashes = 3
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "app crashed 3 times since the kill, the threshold is 3").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				It("pauses chaos when an instance is flapping", func() {
+					processor.Conf.CrashThreshold = 3
+					cfClient.instances["1"] = cfclient.AppInstance{State: "FLAPPING"}
+					service.Settings.MinHealthyPercent = 50
+					service.Settings.MinHealthy = 0
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WithArgs("2", "1", "app-1", sqlmock.AnyArg(), "instance 1 is crash looping").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				It("does not pause chaos below the threshold", func() {
+					processor.Conf.CrashThreshold = 3
+					cfClient.crashes = 2
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				It("does not pause chaos when the crashes cannot be counted", func() {
+					processor.Conf.CrashThreshold = 1
+					cfClient.crashesErr = fmt.Errorf("An error has occurred: %s", "API error")
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				Context("and an alert webhook is configured", func() {
+					It("alerts the webhook with the paused binding", func() {
+						alerts := make(chan sharedModel.PausedBinding, 1)
+						server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							var pause sharedModel.PausedBinding
+							json.NewDecoder(r.Body).Decode(&pause)
+							alerts <- pause
+						}))
+						defer server.Close()
+
+						processor.Conf.AlertURL = server.URL
+						processor.Conf.CrashThreshold = 1
+						cfClient.crashes = 1
+						mock.ExpectExec("INSERT INTO paused_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+						processor.ProcessService(context.Background(), service)
+						processor.Wait()
+						Expect(mock.ExpectationsWereMet()).To(BeNil())
+
+						var pause sharedModel.PausedBinding
+						Eventually(alerts).Should(Receive(&pause))
+						Expect(pause.ServiceBindingID).To(Equal("2"))
+						Expect(pause.AppID).To(Equal("app-1"))
+						Expect(pause.Reason).To(Equal("app crashed 1 times since the kill, the threshold is 1"))
+					})
+				})
+			})
+
+			Context("and chaos is adaptive", func() {
+				BeforeEach(func() {
+					service.Settings.AdaptiveStep = 5
+					service.Settings.Strategy = sharedModel.StrategyNotFirst
+					service.LastChange = sharedModel.ProbabilityChange{ID: 3, Probability: 1}
+					processor.Conf.CrashThreshold = 3
+				})
+
+				It("raises the probability after a recovery within the threshold", func() {
+					cfClient.stats = map[string]model.AppStats{
+						"0": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+						"1": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+					}
+					mock.ExpectExec("UPDATE chaos_events SET recovery.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					expectLock("adaptive")
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 1).
+						WillReturnRows(sqlmock.NewRows(changeColumns).AddRow(3, "1", "app-1", "raised", 0.5, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z"))
+					mock.ExpectExec("INSERT INTO probability_changes.*").WithArgs("1", "app-1", "raised", 0.55, "instance 1 recovered in 0 seconds", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(4, 1))
+					mock.ExpectCommit()
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				It("does not raise the probability twice for one run", func() {
+					cfClient.stats = map[string]model.AppStats{
+						"0": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+						"1": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+					}
+					mock.ExpectExec("UPDATE chaos_events SET recovery.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					expectLock("adaptive")
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 1).
+						WillReturnRows(sqlmock.NewRows(changeColumns).AddRow(4, "1", "app-1", "raised", 0.55, "instance 0 recovered in 0 seconds", "2999-01-01T00:00:00Z"))
+					mock.ExpectCommit()
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				It("leaves the probability as it is while another processor instance holds the adaptive lock", func() {
+					cfClient.stats = map[string]model.AppStats{
+						"0": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+						"1": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 0}},
+					}
+					mock.ExpectExec("UPDATE chaos_events SET recovery.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectBegin()
+					mock.ExpectExec("^SET SESSION").WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery("^SELECT name FROM chaos_locks").WithArgs("adaptive").
+						WillReturnError(&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"})
+					mock.ExpectRollback()
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+
+				It("backs the probability off when the instance does not recover", func() {
+					cfClient.stats = map[string]model.AppStats{
+						"0": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 1000}},
+						"1": {State: "RUNNING", Stats: model.InstanceStats{Uptime: 1000}},
+					}
+					mock.ExpectExec("UPDATE chaos_events SET recovery.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO paused_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					expectLock("adaptive")
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 1).
+						WillReturnRows(sqlmock.NewRows(changeColumns).AddRow(3, "1", "app-1", "raised", 0.5, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z"))
+					mock.ExpectExec("INSERT INTO probability_changes.*").WithArgs("1", "app-1", "backed-off", 0.25, "instance 1 did not recover within 0 seconds", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(4, 1))
+					mock.ExpectCommit()
+					processor.ProcessService(context.Background(), service)
+					processor.Wait()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the processor is stopped", func() {
+				It("stops watching without recording a recovery", func() {
+					processor.Conf.RecoveryTimeout = time.Minute
+					cfClient.statsErr = fmt.Errorf("An error has occurred: %s", "API error")
+					processor.ProcessService(context.Background(), service)
+					processor.Stop()
+
+					done := make(chan struct{})
+					go func() {
+						processor.Run(nil)
+						close(done)
+					}()
+					Eventually(done).Should(BeClosed())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the app has steady state probes", func() {
+			var server *httptest.Server
+
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/fragile" && len(cfClient.Killed()) > 0 {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					fmt.Fprint(w, "UP")
+				}))
+				service.Probes = []sharedModel.Probe{{URL: server.URL + "/health", ExpectedStatus: 200, BodyMatch: "UP"}}
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			Context("and the steady state holds after the chaos", func() {
+				It("records the hypothesis as held", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "succeeded", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(7, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_events SET hypothesis.*").WithArgs("held", "", 7).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(HaveLen(1))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the steady state does not hold after the chaos", func() {
+				It("records the hypothesis as failed", func() {
+					service.Probes = append(service.Probes, sharedModel.Probe{URL: server.URL + "/fragile", ExpectedStatus: 200})
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnResult(sqlmock.NewResult(7, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_events SET hypothesis.*").WithArgs("failed", fmt.Sprintf("steady state hypothesis failed: probe %s/fragile returned status 503, expected 200", server.URL), 7).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(HaveLen(1))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the steady state does not hold after adaptive chaos", func() {
+				It("backs the probability off", func() {
+					service.Settings.AdaptiveStep = 5
+					service.LastChange = sharedModel.ProbabilityChange{ID: 3, Probability: 1}
+					service.Probes = append(service.Probes, sharedModel.Probe{URL: server.URL + "/fragile", ExpectedStatus: 200})
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnResult(sqlmock.NewResult(7, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_events SET hypothesis.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					expectLock("adaptive")
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes WHERE serviceInstanceID=").WithArgs("1", 1).
+						WillReturnRows(sqlmock.NewRows(changeColumns).AddRow(3, "1", "app-1", "raised", 1, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z"))
+					mock.ExpectExec("INSERT INTO probability_changes.*").WithArgs("1", "app-1", "backed-off", 0.5,
+						fmt.Sprintf("steady state hypothesis failed: probe %s/fragile returned status 503, expected 200", server.URL), sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(4, 1))
+					mock.ExpectCommit()
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the steady state is not met before the chaos", func() {
+				It("records the run as skipped", func() {
+					service.Probes[0].BodyMatch = "DOWN"
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", fmt.Sprintf("steady state not met: probe %s/health body did not match DOWN", server.URL), "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When chaos is halted before the action", func() {
+			It("records the action as skipped with who halted it and why", func() {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns).AddRow(true, "operator", "incident 42", "2014-11-12T10:31:20Z"))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "skipped", "chaos halted by operator: incident 42", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("by the environment", func() {
+				AfterEach(func() {
+					os.Unsetenv("CHAOS_HALT")
+				})
+
+				It("records the action as skipped", func() {
+					os.Setenv("CHAOS_HALT", "change freeze")
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "skipped", "chaos halted by CHAOS_HALT: change freeze", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the halt cannot be read before the action", func() {
+			It("records the action as failed without killing", func() {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "failed", "An error has occurred: DB error", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When blast radius budgets are set", func() {
+			BeforeEach(func() {
+				cfClient.location = model.AppLocation{SpaceID: "space-1", OrgID: "org-1"}
+				processor.Conf.Budget = config.Budget{PerMinute: 10, OrgPerHour: 2}
+			})
+
+			Context("and they have room", func() {
+				It("kills an app instance recording its org and space", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					expectLock("budget")
+					mock.ExpectQuery("^SELECT \\(SELECT COUNT\\(\\*\\) FROM chaos_events").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(9))
+					mock.ExpectQuery("^SELECT \\(SELECT COUNT\\(\\*\\) FROM chaos_events (.+) AND orgID=").
+						WithArgs("succeeded", "unknown", sqlmock.AnyArg(), "org-1", "pending", sqlmock.AnyArg(), "org-1").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", sqlmock.AnyArg(), "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "org-1", "space-1").
+						WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectCommit()
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "succeeded", "", "org-1", "space-1", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(HaveLen(1))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and one is exhausted", func() {
+				It("records the action as skipped", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					expectLock("budget")
+					mock.ExpectQuery("^SELECT \\(SELECT COUNT\\(\\*\\) FROM chaos_events").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+					mock.ExpectQuery("^SELECT \\(SELECT COUNT\\(\\*\\) FROM chaos_events (.+) AND orgID=").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "skipped", "budget exhausted: 2 actions per hour in org org-1", "org-1", "space-1", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectCommit()
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the actions cannot be counted", func() {
+				It("records the action as failed", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					expectLock("budget")
+					mock.ExpectQuery("^SELECT \\(SELECT COUNT").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "failed", "An error has occurred: DB error", "org-1", "space-1", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectCommit()
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and another processor instance holds the budget lock", func() {
+				It("records the action as failed without killing", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectBegin()
+					mock.ExpectExec("^SET SESSION").WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery("^SELECT name FROM chaos_locks").WillReturnError(&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"})
+					mock.ExpectRollback()
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "failed", "the budget lock was not released within 50ms", "org-1", "space-1", "", "").
+						WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the org and space of the app cannot be fetched", func() {
+				It("records the run as failed", func() {
+					cfClient.locationErr = fmt.Errorf("An error has occurred: %s", "API error")
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "failed", "An error has occurred: API error", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the strategy ranks instances by their stats", func() {
+			BeforeEach(func() {
+				service.Settings.Strategy = sharedModel.StrategyBusiestCPU
+				cfClient.stats = map[string]model.AppStats{
+					"0": {State: "RUNNING", Stats: model.InstanceStats{Usage: model.InstanceUsage{CPU: 0.1}}},
+					"1": {State: "RUNNING", Stats: model.InstanceStats{Usage: model.InstanceUsage{CPU: 0.9}}},
+				}
+			})
+
+			It("kills the busiest app instance", func() {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs(sqlmock.AnyArg(), "app-1", "1", "0", "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "1", "succeeded", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(Equal([]string{"app-1/1"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and the app stats cannot be fetched", func() {
+				It("does not kill an app instance", func() {
+					cfClient.statsErr = fmt.Errorf("An error has occurred: %s", "API error")
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "failed", "An error has occurred: API error", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the strategy has no victim", func() {
+			It("records the run as skipped", func() {
+				service.Settings.Strategy = sharedModel.StrategyFixed
+				service.Settings.StrategyIndex = 5
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", "instance index 5 is not available", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the app is unhealthy", func() {
+			It("does not kill an app instance", func() {
+				cfClient.instances["1"] = cfclient.AppInstance{State: "STARTING"}
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", "1 of 2 instances are healthy, fewer than the minimum of 100 percent", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the app is healthy enough with an instance down", func() {
+			It("does not kill its last healthy instance", func() {
+				service.Settings.MinHealthyPercent = 50
+				cfClient.instances["1"] = cfclient.AppInstance{State: "CRASHED"}
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", "killing would leave fewer than 1 healthy instances", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			It("kills its last healthy instance when no healthy instance is required", func() {
+				service.Settings.MinHealthyPercent = 50
+				service.Settings.MinHealthy = 0
+				cfClient.instances["1"] = cfclient.AppInstance{State: "CRASHED"}
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "0", "succeeded", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(Equal([]string{"app-1/0"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the app has fewer instances than the minimum", func() {
+			It("does not kill an app instance", func() {
+				service.Settings.MinInstances = 3
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", "app has 2 instances, fewer than the minimum of 3", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When a deploy cooldown is set", func() {
+			BeforeEach(func() {
+				processor.Conf.DeployCooldown = 10 * time.Minute
+				cfClient.appState = model.AppState{State: "STARTED", Instances: 2, PackageState: "STAGED",
+					PackageUpdatedAt: time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)}
+			})
+
+			Context("and the app has not been deployed within it", func() {
+				It("kills an app instance", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "succeeded", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(HaveLen(1))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the app is staging", func() {
+				It("records the run as skipped", func() {
+					cfClient.appState.PackageState = "PENDING"
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", "app is staging", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the app has been updated or restaged within it", func() {
+				It("records the run as skipped", func() {
+					cfClient.deploys = 2
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", "app was updated or restaged 2 times within the deploy cooldown of 10m0s", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the deploy events cannot be fetched", func() {
+				It("records the run as failed", func() {
+					cfClient.deploysErr = fmt.Errorf("An error has occurred: %s", "API error")
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "failed", "An error has occurred: API error", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the deploy cooldown is 0", func() {
+			BeforeEach(func() {
+				processor.Conf.DeployCooldown = 0
+				cfClient.appState = model.AppState{State: "STARTED", Instances: 2, PackageState: "STAGED",
+					PackageUpdatedAt: time.Now().UTC().Format(time.RFC3339)}
+				cfClient.deploys = 2
+			})
+
+			Context("and the app has just been deployed", func() {
+				It("kills an app instance", func() {
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", sqlmock.AnyArg(), "succeeded", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(HaveLen(1))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and the app is staging", func() {
+				It("records the run as skipped", func() {
+					cfClient.appState.PackageState = "PENDING"
+					mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+					mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "skipped", "app is staging", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+					processor.ProcessService(context.Background(), service)
+					Expect(cfClient.Killed()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the app instances cannot be fetched", func() {
+			It("does not kill an app instance", func() {
+				cfClient.instancesErr = fmt.Errorf("An error has occurred: %s", "API error")
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "failed", "An error has occurred: API error", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+			})
+		})
+
+		Context("When the Cloud Foundry API does not answer before the context is done", func() {
+			It("gives up on the app without killing an instance", func() {
+				cfClient.delay = time.Second
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "failed", "context deadline exceeded", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+
+				start := time.Now()
+				processor.ProcessService(ctx, service)
+				Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+				Expect(cfClient.Killed()).To(BeEmpty())
+			})
+		})
+
+		Context("When the last processed time cannot be updated", func() {
+			It("does not kill an app instance", func() {
+				mock.ExpectExec("UPDATE service_bindings.*").WillReturnError(fmt.Errorf("An error has occurred: %s", "UPDATE error"))
+				processor.ProcessService(context.Background(), service)
+				Expect(cfClient.Killed()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#ProcessServices", func() {
+		It("processes every bound app using the configured app timeout", func() {
+			cfClient.delay = time.Second
+			instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+				AddRow("1", "example.com/1", "1", 1, 5)
+			bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+				AddRow("1", "app-1", "1", "1", "").
+				AddRow("2", "app-2", "1", "1", "")
+
+			mock.MatchExpectationsInOrder(false)
+			mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+			mock.ExpectQuery("^SELECT (.+) FROM campaigns").WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns))
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+			mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+			mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+			mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(sqlmock.NewRows(probeColumns))
+			mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(sqlmock.NewRows(pauseColumns))
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnRows(sqlmock.NewRows([]string{"serviceInstanceID", "actions"}))
+			mock.ExpectQuery("^SELECT (.+) FROM probability_changes").WillReturnRows(sqlmock.NewRows(changeColumns))
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_replays$").WillReturnRows(sqlmock.NewRows(replayColumns))
+			mock.ExpectExec("UPDATE service_bindings.*").WithArgs(sqlmock.AnyArg(), "app-1").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("UPDATE service_bindings.*").WithArgs(sqlmock.AnyArg(), "app-2").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "1", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "failed", "context deadline exceeded", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-2", "1", "2", sqlmock.AnyArg(), sqlmock.AnyArg(), "kill", "", "failed", "context deadline exceeded", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+
+			start := time.Now()
+			processor.ProcessServices()
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+			Expect(cfClient.Killed()).To(BeEmpty())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When chaos is halted", func() {
+			It("does not process any app", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM campaigns").WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns).AddRow(true, "operator", "incident 42", "2014-11-12T10:31:20Z"))
+				processor.ProcessServices()
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the halt cannot be read", func() {
+			It("does not process any app", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM campaigns").WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				processor.ProcessServices()
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When a scheduled change is due", func() {
+			It("applies the change, even while chaos is halted", func() {
+				changeRows := sqlmock.NewRows(scheduledChangeColumns).AddRow(3, "1", "2014-11-17T09:00:00Z", 0.4, nil, "pending", nil, 0, nil, nil)
+				mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE state=").WithArgs("pending", sqlmock.AnyArg()).WillReturnRows(changeRows)
+				mock.ExpectExec("UPDATE scheduled_changes SET state=").WithArgs("applied", sqlmock.AnyArg(), 3, "pending").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("^SELECT probability, frequency FROM service_instances").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"probability", "frequency"}).AddRow(0.2, 5))
+				mock.ExpectExec("UPDATE scheduled_changes SET replacedProbability=").WithArgs(0.2, nil, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE service_instances SET probability=").WithArgs(0.4, nil, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM campaigns").WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns).AddRow(true, "operator", "incident 42", "2014-11-12T10:31:20Z"))
+				processor.ProcessServices()
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When a campaign is in progress", func() {
+			It("runs chaos against the apps of its target spaces at its probability and actions", func() {
+				cfClient.delay = time.Second
+				cfClient.location = model.AppLocation{SpaceID: "space-1", OrgID: "org-1"}
+				instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+					AddRow("1", "example.com/1", "1", 0.0001, 5)
+				bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+					AddRow("1", "app-1", "1", "1", "")
+				campaignRows := sqlmock.NewRows(sharedUtils.CampaignColumns).
+					AddRow(4, "game day", "2014-11-12T10:00:00Z", "2114-11-12T12:00:00Z", "", "space-1", 1.0, 5, "stop-start", "active", nil)
+
+				mock.MatchExpectationsInOrder(false)
+				mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM campaigns").WillReturnRows(campaignRows)
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+				mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+				mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(sqlmock.NewRows(probeColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(sqlmock.NewRows(pauseColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnRows(sqlmock.NewRows([]string{"serviceInstanceID", "actions"}))
+				mock.ExpectQuery("^SELECT (.+) FROM probability_changes").WillReturnRows(sqlmock.NewRows(changeColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_replays$").WillReturnRows(sqlmock.NewRows(replayColumns))
+				mock.ExpectExec("INSERT IGNORE INTO campaign_apps").WithArgs(4, "app-1", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE service_bindings.*").WithArgs(sqlmock.AnyArg(), "app-1").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "1", sqlmock.AnyArg(), sqlmock.AnyArg(), "stop-start", "", "failed", "context deadline exceeded", "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+
+				processor.ProcessServices()
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and the space of an app cannot be fetched in time", func() {
+				It("leaves the app out of the campaign", func() {
+					cfClient.locationWait = time.Second
+					cfClient.location = model.AppLocation{SpaceID: "space-1", OrgID: "org-1"}
+					instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+						AddRow("1", "example.com/1", "1", 0.0001, 5)
+					bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+						AddRow("1", "app-1", "1", "1", "")
+					campaignRows := sqlmock.NewRows(sharedUtils.CampaignColumns).
+						AddRow(4, "game day", "2014-11-12T10:00:00Z", "2114-11-12T12:00:00Z", "", "space-1", 1.0, 5, "stop-start", "active", nil)
+
+					mock.MatchExpectationsInOrder(false)
+					mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns").WillReturnRows(campaignRows)
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(sqlmock.NewRows(probeColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(sqlmock.NewRows(pauseColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnRows(sqlmock.NewRows([]string{"serviceInstanceID", "actions"}))
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes").WillReturnRows(sqlmock.NewRows(changeColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_replays$").WillReturnRows(sqlmock.NewRows(replayColumns))
+					mock.ExpectExec("UPDATE service_bindings.*").WithArgs(sqlmock.AnyArg(), "app-1").WillReturnResult(sqlmock.NewResult(1, 1))
+
+					processor.ProcessServices()
+					Expect(cfClient.Actions()).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+
+			Context("and its start has come", func() {
+				It("starts the campaign", func() {
+					campaignRows := sqlmock.NewRows(sharedUtils.CampaignColumns).
+						AddRow(4, "game day", "2014-11-12T10:00:00Z", "2114-11-12T12:00:00Z", "1", "", 1.0, 5, "", "scheduled", nil)
+					mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE state<>").WithArgs("ended").WillReturnRows(campaignRows)
+					mock.ExpectExec("UPDATE campaigns SET state=").WithArgs("active", 4, "scheduled").WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns).AddRow(true, "operator", "incident 42", "2014-11-12T10:31:20Z"))
+					processor.ProcessServices()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When a campaign has ended", func() {
+			It("ends the campaign with a report on its events, even while chaos is halted", func() {
+				campaignRows := sqlmock.NewRows(sharedUtils.CampaignColumns).
+					AddRow(4, "game day", "2014-11-12T10:00:00Z", "2014-11-12T12:00:00Z", "1", "", 1.0, 5, "", "active", nil)
+				eventRows := sqlmock.NewRows(sharedUtils.EventColumns).
+					AddRow(1, "app-1", "1", "1", "2014-11-12T10:05:00Z", 42, "kill", "0", "succeeded", "", "recovered", 12.0, "", "", "", "", "").
+					AddRow(2, "app-1", "1", "1", "2014-11-12T10:10:00Z", 43, "kill", "", "skipped", "app is unhealthy", "", nil, "", "", "", "", "")
+				mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE state<>").WithArgs("ended").WillReturnRows(campaignRows)
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE createdAt>=\\? AND createdAt<\\?").WithArgs("2014-11-12T10:00:00Z", "2014-11-12T12:00:00Z", 4).
+					WillReturnRows(eventRows)
+				mock.ExpectExec("UPDATE campaigns SET state=\\?, report=\\?").WithArgs("ended", sqlmock.AnyArg(), 4, "ended").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns).AddRow(true, "operator", "incident 42", "2014-11-12T10:31:20Z"))
+				processor.ProcessServices()
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+
+			Context("and the recovery of its last kills is still watched", func() {
+				It("waits to report on the campaign", func() {
+					processor.Conf.RecoveryTimeout = time.Hour
+					endAt := time.Now().UTC().Add(-time.Minute).Format("2006-01-02T15:04:05Z")
+					campaignRows := sqlmock.NewRows(sharedUtils.CampaignColumns).
+						AddRow(4, "game day", "2014-11-12T10:00:00Z", endAt, "1", "", 1.0, 5, "", "active", nil)
+					mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE state<>").WithArgs("ended").WillReturnRows(campaignRows)
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns).AddRow(true, "operator", "incident 42", "2014-11-12T10:31:20Z"))
+					processor.ProcessServices()
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+	})
+
+	Describe("#Run", func() {
+		It("processes services until the processor is stopped", func() {
+			mock.MatchExpectationsInOrder(false)
+			for i := 0; i < 2; i++ {
+				mock.ExpectExec("INSERT INTO processor_owners.*").WithArgs("0", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes").WillReturnRows(sqlmock.NewRows(scheduledChangeColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM campaigns").WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}))
+				mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}))
+				mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(sqlmock.NewRows(probeColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(sqlmock.NewRows(pauseColumns))
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnRows(sqlmock.NewRows([]string{"serviceInstanceID", "actions"}))
+			}
+
+			ticks := make(chan time.Time)
+			done := make(chan struct{})
+			go func() {
+				processor.Run(ticks)
+				close(done)
+			}()
+
+			ticks <- time.Now()
+			processor.Stop()
+			processor.Stop()
+			Eventually(done).Should(BeClosed())
+		})
+	})
+
+	Describe("#ClaimOrphans", func() {
+		It("records this processor as seen and claims the rows of owners unseen for longer than the recovery timeout", func() {
+			mock.ExpectExec("INSERT INTO processor_owners.*").WithArgs("0", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("^UPDATE chaos_intents SET owner=\\?").WithArgs("0", "0", sqlmock.AnyArg(), "pending", "unknown").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			for i := 0; i < 5; i++ {
+				mock.ExpectExec("^UPDATE (.+)_snapshots SET owner=\\?").WithArgs("0", "0", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			Expect(processor.ClaimOrphans()).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When this processor cannot be recorded as seen", func() {
+			It("claims nothing and returns an error", func() {
+				mock.ExpectExec("INSERT INTO processor_owners.*").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				Expect(processor.ClaimOrphans()).To(MatchError("An error has occurred: DB error"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Describe("#RecoverIntents", func() {
+		It("records pending intents owned by this processor as aborted", func() {
+			intentRows := sqlmock.NewRows([]string{"id", "appID", "instanceIndex", "owner", "state", "createdAt", "updatedAt", "action", "orgID", "spaceID", "eventID"}).
+				AddRow("intent-1", "app-1", "0", "0", "pending", "2014-11-12T10:31:20Z", "2014-11-12T10:31:20Z", "kill", nil, nil, nil)
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_intents WHERE (.+)$").WithArgs("0", "pending", "unknown").WillReturnRows(intentRows)
+			mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("aborted", sqlmock.AnyArg(), "intent-1").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(processor.RecoverIntents()).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		It("records unknown intents owned by this processor as aborted, keeping their events unknown for good", func() {
+			intentRows := sqlmock.NewRows([]string{"id", "appID", "instanceIndex", "owner", "state", "createdAt", "updatedAt", "action", "orgID", "spaceID", "eventID"}).
+				AddRow("intent-1", "app-1", "0,1", "0", "unknown", "2014-11-12T10:31:20Z", "2014-11-12T10:31:50Z", "restage", nil, nil, 7)
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_intents WHERE (.+)$").WithArgs("0", "pending", "unknown").WillReturnRows(intentRows)
+			mock.ExpectExec("UPDATE chaos_events SET outcome=\\?, reason=\\? WHERE id=\\?").
+				WithArgs("unknown", "the processor stopped before the timed out call returned, so whether it landed will never be known", 7).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("aborted", sqlmock.AnyArg(), "intent-1").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(processor.RecoverIntents()).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the pending intents cannot be read", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_intents WHERE (.+)$").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				err := processor.RecoverIntents()
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("An error has occurred: DB error"))
+			})
+		})
+	})
+
+	Describe("#RestoreAppStates", func() {
+		var snapshotColumns = []string{"id", "appID", "action", "state", "instances", "owner", "createdAt"}
+
+		It("restores the apps of snapshots owned by this processor and deletes the snapshots", func() {
+			rows := sqlmock.NewRows(snapshotColumns).
+				AddRow("snapshot-1", "app-1", "stop-start", "STARTED", 2, "0", "2014-11-12T10:31:20Z").
+				AddRow("snapshot-2", "app-2", "scale", "STARTED", 3, "0", "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("^SELECT (.+) FROM state_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+			mock.ExpectExec("DELETE FROM state_snapshots.*").WithArgs("snapshot-1").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("DELETE FROM state_snapshots.*").WithArgs("snapshot-2").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(processor.RestoreAppStates()).To(BeNil())
+			Expect(cfClient.Actions()).To(Equal([]string{"app-1/STARTED", "app-2/scale/3"}))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the app cannot be restored", func() {
+			It("keeps the snapshot and returns an error", func() {
+				cfClient.restoreErr = fmt.Errorf("An error has occurred: %s", "API error")
+				rows := sqlmock.NewRows(snapshotColumns).AddRow("snapshot-1", "app-1", "stop-start", "STARTED", 2, "0", "2014-11-12T10:31:20Z")
+				mock.ExpectQuery("^SELECT (.+) FROM state_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+				Expect(processor.RestoreAppStates()).To(MatchError("An error has occurred: API error"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Describe("#RestorePolicies", func() {
+		var snapshotColumns = []string{"id", "appID", "owner", "policies", "createdAt"}
+
+		It("creates the policies of snapshots owned by this processor and deletes the snapshots", func() {
+			rows := sqlmock.NewRows(snapshotColumns).
+				AddRow("snapshot-1", "app-1", "0", `[{"source":{"id":"app-1"},"destination":{"id":"app-2","protocol":"tcp","ports":{"start":8080,"end":8080}}}]`, "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("^SELECT (.+) FROM policy_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+			mock.ExpectExec("DELETE FROM policy_snapshots.*").WithArgs("snapshot-1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(processor.RestorePolicies()).To(BeNil())
+			Expect(cfClient.Actions()).To(Equal([]string{"policies/create/1"}))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the policies cannot be created", func() {
+			It("keeps the snapshot and returns an error", func() {
+				cfClient.restoreErr = fmt.Errorf("An error has occurred: %s", "API error")
+				rows := sqlmock.NewRows(snapshotColumns).AddRow("snapshot-1", "app-1", "0", "[]", "2014-11-12T10:31:20Z")
+				mock.ExpectQuery("^SELECT (.+) FROM policy_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+				Expect(processor.RestorePolicies()).To(MatchError("An error has occurred: API error"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Describe("#RestoreRoutes", func() {
+		var snapshotColumns = []string{"id", "appID", "routeID", "appPort", "owner", "createdAt"}
+
+		It("maps the routes of snapshots owned by this processor and deletes the snapshots", func() {
+			rows := sqlmock.NewRows(snapshotColumns).AddRow("snapshot-1", "app-1", "route-1", 8080, "0", "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("^SELECT (.+) FROM mapping_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+			mock.ExpectExec("DELETE FROM mapping_snapshots.*").WithArgs("snapshot-1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(processor.RestoreRoutes()).To(BeNil())
+			Expect(cfClient.Actions()).To(Equal([]string{"app-1/map/route-1"}))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the route cannot be mapped", func() {
+			It("keeps the snapshot and returns an error", func() {
+				cfClient.restoreErr = fmt.Errorf("An error has occurred: %s", "API error")
+				rows := sqlmock.NewRows(snapshotColumns).AddRow("snapshot-1", "app-1", "route-1", 8080, "0", "2014-11-12T10:31:20Z")
+				mock.ExpectQuery("^SELECT (.+) FROM mapping_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+				Expect(processor.RestoreRoutes()).To(MatchError("An error has occurred: API error"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Describe("#RestoreSecGroups", func() {
+		It("binds the security groups of snapshots owned by this processor again and restarts their apps", func() {
+			rows := sqlmock.NewRows([]string{"id", "appID", "spaceID", "blackholeID", "secGroups", "owner", "createdAt"}).
+				AddRow("snapshot-1", "app-1", "space-1", "blackhole", "database", "0", "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("^SELECT (.+) FROM secgroup_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+			mock.ExpectExec("DELETE FROM secgroup_snapshots.*").WithArgs("snapshot-1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(processor.RestoreSecGroups()).To(BeNil())
+			Expect(cfClient.Actions()).To(Equal([]string{"space-1/bind/database", "space-1/unbind/blackhole", "app-1/STOPPED", "app-1/STARTED"}))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#RollbackPlugins", func() {
+		var (
+			dir             string
+			snapshotColumns = []string{"id", "plugin", "appID", "owner", "state", "createdAt"}
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "chaos-galago-plugins")
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("rolls back the actions of snapshots owned by this processor and deletes the snapshots", func() {
+			requests := installPlugin(processor, dir, "", "", false)
+			rows := sqlmock.NewRows(snapshotColumns).AddRow("snapshot-1", "feature-flag", "app-1", "0", `{"flag":"checkout"}`, "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("^SELECT (.+) FROM plugin_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+			mock.ExpectExec("DELETE FROM plugin_snapshots.*").WithArgs("snapshot-1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(processor.RollbackPlugins()).To(BeNil())
+			logged := pluginRequests(requests)
+			Expect(pluginCommands(logged)).To(Equal([]string{"rollback"}))
+			Expect(logged[0].AppID).To(Equal("app-1"))
+			Expect(logged[0].State).To(MatchJSON(`{"flag":"checkout"}`))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the plugin is no longer installed", func() {
+			It("keeps the snapshot and returns an error", func() {
+				rows := sqlmock.NewRows(snapshotColumns).AddRow("snapshot-1", "feature-flag", "app-1", "0", `{"flag":"checkout"}`, "2014-11-12T10:31:20Z")
+				mock.ExpectQuery("^SELECT (.+) FROM plugin_snapshots WHERE (.+)$").WithArgs("0").WillReturnRows(rows)
+				Expect(processor.RollbackPlugins()).To(MatchError("plugin feature-flag is no longer installed, its action on app app-1 cannot be rolled back"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,42 @@
+package chaos
+
+import (
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"sync"
+)
+
+// RunPool - runs work for every service on at most workers goroutines, returning once all work has finished.
+// Once stop is closed no further services are handed out, work already started is allowed to finish.
+func RunPool(stop <-chan struct{}, workers int, services []model.Service, work func(model.Service)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan model.Service)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for service := range jobs {
+				select {
+				case <-stop:
+					continue
+				default:
+				}
+				work(service)
+			}
+		}()
+	}
+
+DISPATCH:
+	for _, service := range services {
+		select {
+		case jobs <- service:
+		case <-stop:
+			break DISPATCH
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
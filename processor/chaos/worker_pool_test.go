@@ -0,0 +1,84 @@
+package chaos_test
+
+import (
+	"github.com/FidelityInternational/chaos-galago/processor/chaos"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sync"
+	"time"
+)
+
+var _ = Describe("#RunPool", func() {
+	var services []model.Service
+
+	BeforeEach(func() {
+		services = []model.Service{}
+		for _, appID := range []string{"1", "2", "3", "4", "5", "6"} {
+			services = append(services, model.Service{AppID: appID})
+		}
+	})
+
+	It("runs the work for every service", func() {
+		var (
+			mutex     sync.Mutex
+			processed []string
+		)
+		chaos.RunPool(nil, 2, services, func(service model.Service) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			processed = append(processed, service.AppID)
+		})
+		Expect(processed).To(ConsistOf("1", "2", "3", "4", "5", "6"))
+	})
+
+	It("never runs more than the configured number of workers at once", func() {
+		var (
+			mutex          sync.Mutex
+			active, maxRun int
+		)
+		chaos.RunPool(nil, 3, services, func(service model.Service) {
+			mutex.Lock()
+			active++
+			if active > maxRun {
+				maxRun = active
+			}
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mutex.Lock()
+			active--
+			mutex.Unlock()
+		})
+		Expect(maxRun).To(BeNumerically(">", 0))
+		Expect(maxRun).To(BeNumerically("<=", 3))
+	})
+
+	Context("When workers is less than 1", func() {
+		It("still runs the work with a single worker", func() {
+			count := 0
+			chaos.RunPool(nil, 0, services, func(service model.Service) {
+				count++
+			})
+			Expect(count).To(Equal(6))
+		})
+	})
+
+	Context("When stop is closed", func() {
+		It("finishes the work in flight without starting any more", func() {
+			var (
+				mutex     sync.Mutex
+				processed []string
+			)
+			stop := make(chan struct{})
+			chaos.RunPool(stop, 1, services, func(service model.Service) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				processed = append(processed, service.AppID)
+				if service.AppID == "2" {
+					close(stop)
+				}
+			})
+			Expect(processed).To(Equal([]string{"1", "2"}))
+		})
+	})
+})
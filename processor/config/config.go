@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultWorkers           = 10
+	defaultAppTimeoutSeconds = 30
+	defaultRecoveryTimeout   = 300
+	defaultRecoveryPoll      = 5
+	defaultProbeTimeout      = 10
+	defaultCrashThreshold    = 3
+	defaultMaxBlackhole      = 300
+	defaultPluginTimeout     = 30
+	defaultDeployCooldown    = 600
+)
+
+// Config struct
+type Config struct {
+	Workers         int
+	AppTimeout      time.Duration
+	InstanceIndex   string
+	RecoveryTimeout time.Duration
+	RecoveryPoll    time.Duration
+	ProbeTimeout    time.Duration
+	CrashThreshold  int
+	AlertURL        string
+	BlackholeGroup  string
+	MaxBlackhole    time.Duration
+	PluginDir       string
+	PluginTimeout   time.Duration
+	DeployCooldown  time.Duration
+	Budget          Budget
+}
+
+// Budget struct - ceilings on the chaos actions the processor takes, 0 for no limit
+type Budget struct {
+	PerMinute          int
+	PerHour            int
+	OrgPerMinute       int
+	OrgPerHour         int
+	SpacePerMinute     int
+	SpacePerHour       int
+	MaxUnrecoveredApps int
+}
+
+// Enabled - returns true if any budget is set
+func (b Budget) Enabled() bool {
+	return b != Budget{}
+}
+
+// NeedsLocation - returns true if a budget is set per org or per space, needing the org and space of every app
+func (b Budget) NeedsLocation() bool {
+	return b.OrgPerMinute > 0 || b.OrgPerHour > 0 || b.SpacePerMinute > 0 || b.SpacePerHour > 0
+}
+
+// LoadConfig - loads processor config from environment variables, falling back to defaults
+func LoadConfig() *Config {
+	return &Config{
+		Workers:         GetEnvInt("WORKERS", defaultWorkers),
+		AppTimeout:      time.Duration(GetEnvInt("APP_TIMEOUT", defaultAppTimeoutSeconds)) * time.Second,
+		InstanceIndex:   GetEnvString("CF_INSTANCE_INDEX", "0"),
+		RecoveryTimeout: time.Duration(GetEnvCount("RECOVERY_TIMEOUT", defaultRecoveryTimeout)) * time.Second,
+		RecoveryPoll:    time.Duration(GetEnvInt("RECOVERY_POLL", defaultRecoveryPoll)) * time.Second,
+		ProbeTimeout:    time.Duration(GetEnvInt("PROBE_TIMEOUT", defaultProbeTimeout)) * time.Second,
+		CrashThreshold:  GetEnvInt("CRASH_THRESHOLD", defaultCrashThreshold),
+		AlertURL:        GetEnvString("ALERT_URL", ""),
+		BlackholeGroup:  GetEnvString("EGRESS_BLACKHOLE_GROUP", ""),
+		MaxBlackhole:    time.Duration(GetEnvInt("MAX_BLACKHOLE_DURATION", defaultMaxBlackhole)) * time.Second,
+		PluginDir:       GetEnvString("PLUGIN_DIR", ""),
+		PluginTimeout:   time.Duration(GetEnvInt("PLUGIN_TIMEOUT", defaultPluginTimeout)) * time.Second,
+		DeployCooldown:  time.Duration(GetEnvCount("DEPLOY_COOLDOWN", defaultDeployCooldown)) * time.Second,
+		Budget: Budget{
+			PerMinute:          GetEnvInt("MAX_ACTIONS_PER_MINUTE", 0),
+			PerHour:            GetEnvInt("MAX_ACTIONS_PER_HOUR", 0),
+			OrgPerMinute:       GetEnvInt("MAX_ORG_ACTIONS_PER_MINUTE", 0),
+			OrgPerHour:         GetEnvInt("MAX_ORG_ACTIONS_PER_HOUR", 0),
+			SpacePerMinute:     GetEnvInt("MAX_SPACE_ACTIONS_PER_MINUTE", 0),
+			SpacePerHour:       GetEnvInt("MAX_SPACE_ACTIONS_PER_HOUR", 0),
+			MaxUnrecoveredApps: GetEnvInt("MAX_UNRECOVERED_APPS", 0),
+		},
+	}
+}
+
+// GetEnvInt - returns a positive integer from an environment variable, or the default if it is unset or invalid
+func GetEnvInt(varName string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(varName))
+	if err != nil || value < 1 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvCount - returns an integer of at least 0 from an environment variable, or the default if it is unset or invalid
+func GetEnvCount(varName string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(varName))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvString - returns an environment variable, or the default if it is unset
+func GetEnvString(varName string, defaultValue string) string {
+	if value := os.Getenv(varName); value != "" {
+		return value
+	}
+	return defaultValue
+}
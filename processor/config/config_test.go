@@ -0,0 +1,170 @@
+package config_test
+
+import (
+	. "github.com/FidelityInternational/chaos-galago/processor/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+	"strconv"
+	"time"
+)
+
+var budgetVars = []string{"MAX_ACTIONS_PER_MINUTE", "MAX_ACTIONS_PER_HOUR", "MAX_ORG_ACTIONS_PER_MINUTE", "MAX_ORG_ACTIONS_PER_HOUR",
+	"MAX_SPACE_ACTIONS_PER_MINUTE", "MAX_SPACE_ACTIONS_PER_HOUR", "MAX_UNRECOVERED_APPS"}
+
+var _ = Describe("#LoadConfig", func() {
+	AfterEach(func() {
+		os.Unsetenv("WORKERS")
+		os.Unsetenv("APP_TIMEOUT")
+		os.Unsetenv("RECOVERY_TIMEOUT")
+		os.Unsetenv("RECOVERY_POLL")
+		os.Unsetenv("PROBE_TIMEOUT")
+		os.Unsetenv("CRASH_THRESHOLD")
+		os.Unsetenv("ALERT_URL")
+		os.Unsetenv("EGRESS_BLACKHOLE_GROUP")
+		os.Unsetenv("MAX_BLACKHOLE_DURATION")
+		os.Unsetenv("PLUGIN_DIR")
+		os.Unsetenv("PLUGIN_TIMEOUT")
+		os.Unsetenv("DEPLOY_COOLDOWN")
+		for _, varName := range budgetVars {
+			os.Unsetenv(varName)
+		}
+	})
+
+	Context("When no environment variables are set", func() {
+		It("returns the default config", func() {
+			conf := LoadConfig()
+			Expect(conf.Workers).To(Equal(10))
+			Expect(conf.AppTimeout).To(Equal(30 * time.Second))
+			Expect(conf.RecoveryTimeout).To(Equal(300 * time.Second))
+			Expect(conf.RecoveryPoll).To(Equal(5 * time.Second))
+			Expect(conf.ProbeTimeout).To(Equal(10 * time.Second))
+			Expect(conf.CrashThreshold).To(Equal(3))
+			Expect(conf.AlertURL).To(Equal(""))
+			Expect(conf.BlackholeGroup).To(Equal(""))
+			Expect(conf.MaxBlackhole).To(Equal(5 * time.Minute))
+			Expect(conf.PluginDir).To(Equal(""))
+			Expect(conf.PluginTimeout).To(Equal(30 * time.Second))
+			Expect(conf.DeployCooldown).To(Equal(10 * time.Minute))
+			Expect(conf.Budget).To(Equal(Budget{}))
+			Expect(conf.Budget.Enabled()).To(BeFalse())
+		})
+	})
+
+	Context("When environment variables are set", func() {
+		BeforeEach(func() {
+			os.Setenv("WORKERS", "3")
+			os.Setenv("APP_TIMEOUT", "5")
+			os.Setenv("RECOVERY_TIMEOUT", "60")
+			os.Setenv("RECOVERY_POLL", "2")
+			os.Setenv("PROBE_TIMEOUT", "3")
+			os.Setenv("CRASH_THRESHOLD", "5")
+			os.Setenv("ALERT_URL", "https://alerts.example.com/chaos")
+			os.Setenv("EGRESS_BLACKHOLE_GROUP", "chaos-galago-blackhole")
+			os.Setenv("MAX_BLACKHOLE_DURATION", "60")
+			os.Setenv("PLUGIN_DIR", "/home/vcap/app/plugins")
+			os.Setenv("PLUGIN_TIMEOUT", "10")
+			os.Setenv("DEPLOY_COOLDOWN", "120")
+			for i, varName := range budgetVars {
+				os.Setenv(varName, strconv.Itoa(i+1))
+			}
+		})
+
+		It("returns the configured values", func() {
+			conf := LoadConfig()
+			Expect(conf.Workers).To(Equal(3))
+			Expect(conf.AppTimeout).To(Equal(5 * time.Second))
+			Expect(conf.RecoveryTimeout).To(Equal(60 * time.Second))
+			Expect(conf.RecoveryPoll).To(Equal(2 * time.Second))
+			Expect(conf.ProbeTimeout).To(Equal(3 * time.Second))
+			Expect(conf.CrashThreshold).To(Equal(5))
+			Expect(conf.AlertURL).To(Equal("https://alerts.example.com/chaos"))
+			Expect(conf.BlackholeGroup).To(Equal("chaos-galago-blackhole"))
+			Expect(conf.MaxBlackhole).To(Equal(time.Minute))
+			Expect(conf.PluginDir).To(Equal("/home/vcap/app/plugins"))
+			Expect(conf.PluginTimeout).To(Equal(10 * time.Second))
+			Expect(conf.DeployCooldown).To(Equal(2 * time.Minute))
+			Expect(conf.Budget).To(Equal(Budget{PerMinute: 1, PerHour: 2, OrgPerMinute: 3, OrgPerHour: 4, SpacePerMinute: 5, SpacePerHour: 6, MaxUnrecoveredApps: 7}))
+		})
+	})
+
+	Context("When recovery watching is turned off", func() {
+		BeforeEach(func() {
+			os.Setenv("RECOVERY_TIMEOUT", "0")
+		})
+
+		It("returns a recovery timeout of 0", func() {
+			Expect(LoadConfig().RecoveryTimeout).To(BeZero())
+		})
+	})
+})
+
+var _ = Describe("#GetEnvInt", func() {
+	AfterEach(func() {
+		os.Unsetenv("TEST_INT")
+	})
+
+	Context("When the variable is a positive integer", func() {
+		It("returns the value", func() {
+			os.Setenv("TEST_INT", "7")
+			Expect(GetEnvInt("TEST_INT", 1)).To(Equal(7))
+		})
+	})
+
+	Context("When the variable is not a number", func() {
+		It("returns the default", func() {
+			os.Setenv("TEST_INT", "seven")
+			Expect(GetEnvInt("TEST_INT", 1)).To(Equal(1))
+		})
+	})
+
+	Context("When the variable is not positive", func() {
+		It("returns the default", func() {
+			os.Setenv("TEST_INT", "0")
+			Expect(GetEnvInt("TEST_INT", 1)).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("#GetEnvCount", func() {
+	AfterEach(func() {
+		os.Unsetenv("TEST_INT")
+	})
+
+	Context("When the variable is 0", func() {
+		It("returns 0", func() {
+			os.Setenv("TEST_INT", "0")
+			Expect(GetEnvCount("TEST_INT", 1)).To(Equal(0))
+		})
+	})
+
+	Context("When the variable is unset", func() {
+		It("returns the default", func() {
+			Expect(GetEnvCount("TEST_INT", 1)).To(Equal(1))
+		})
+	})
+
+	Context("When the variable is negative", func() {
+		It("returns the default", func() {
+			os.Setenv("TEST_INT", "-1")
+			Expect(GetEnvCount("TEST_INT", 1)).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Budget", func() {
+	Describe("#Enabled", func() {
+		It("returns true when any budget is set", func() {
+			Expect(Budget{MaxUnrecoveredApps: 1}.Enabled()).To(BeTrue())
+			Expect(Budget{}.Enabled()).To(BeFalse())
+		})
+	})
+
+	Describe("#NeedsLocation", func() {
+		It("returns true only when an org or space budget is set", func() {
+			Expect(Budget{PerMinute: 1, PerHour: 1, MaxUnrecoveredApps: 1}.NeedsLocation()).To(BeFalse())
+			Expect(Budget{OrgPerHour: 1}.NeedsLocation()).To(BeTrue())
+			Expect(Budget{SpacePerMinute: 1}.NeedsLocation()).To(BeTrue())
+		})
+	})
+})
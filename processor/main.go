@@ -3,11 +3,14 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/chaos"
+	conf "github.com/FidelityInternational/chaos-galago/processor/config"
 	"github.com/FidelityInternational/chaos-galago/processor/utils"
 	"github.com/FidelityInternational/chaos-galago/shared/utils"
 	"github.com/cloudfoundry-community/go-cfclient"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -15,6 +18,7 @@ var (
 	dbConnectionString string
 	err                error
 	config             *cfclient.Config
+	processorConfig    *conf.Config
 )
 
 func init() {
@@ -24,10 +28,22 @@ func init() {
 		os.Exit(1)
 	}
 	config = utils.LoadCFConfig()
+	processorConfig = conf.LoadConfig()
 	fmt.Println("Config loaded:")
 	fmt.Println("ApiAddress: ", config.ApiAddress)
 	fmt.Println("Username: ", config.Username)
 	fmt.Println("SkipSslValidation: ", config.SkipSslValidation)
+	fmt.Println("Workers: ", processorConfig.Workers)
+	fmt.Println("AppTimeout: ", processorConfig.AppTimeout)
+	fmt.Println("RecoveryTimeout: ", processorConfig.RecoveryTimeout)
+	fmt.Println("RecoveryPoll: ", processorConfig.RecoveryPoll)
+	fmt.Println("ProbeTimeout: ", processorConfig.ProbeTimeout)
+	fmt.Println("CrashThreshold: ", processorConfig.CrashThreshold)
+	fmt.Println("DeployCooldown: ", processorConfig.DeployCooldown)
+	fmt.Println("AlertURL: ", processorConfig.AlertURL)
+	fmt.Printf("Budget: %+v\n", processorConfig.Budget)
+	fmt.Println("InstanceIndex: ", processorConfig.InstanceIndex)
+	fmt.Println("PluginDir: ", processorConfig.PluginDir)
 }
 
 func logError(err error) bool {
@@ -46,56 +62,59 @@ func main() {
 		os.Exit(1)
 	}
 
-	ticker := time.NewTicker(1 * time.Minute)
-
-	processServices(cfClient)
-	for range ticker.C {
-		processServices(cfClient)
-	}
-}
-
-func processServices(cfClient *cfclient.Client) {
 	db, err := sql.Open("mysql", dbConnectionString)
+	if err != nil {
+		logError(err)
+		os.Exit(1)
+	}
 	defer db.Close()
-	if logError(err) {
-		return
+
+	for _, setup := range []func(*sql.DB) error{utils.SetupOwnerDB, utils.SetupLockDB, utils.SetupIntentDB, utils.SetupStateSnapshotDB, utils.SetupPolicySnapshotDB, utils.SetupMappingSnapshotDB, utils.SetupSecGroupSnapshotDB, utils.SetupPluginSnapshotDB, utils.SetupScheduleDB, sharedUtils.SetupInstanceSettingsDB, sharedUtils.SetupEventDB, sharedUtils.SetupProbeDB, sharedUtils.SetupPauseDB, sharedUtils.SetupHaltDB, sharedUtils.SetupProbabilityChangeDB, sharedUtils.SetupCampaignDB, sharedUtils.SetupScheduledChangeDB, sharedUtils.SetupReplayDB} {
+		err = setup(db)
+		if err != nil {
+			logError(err)
+			os.Exit(1)
+		}
 	}
 
-	services := utils.GetBoundApps(db)
+	processor := chaos.CreateProcessor(db, &utils.CFAPI{Client: cfClient, Config: config}, processorConfig)
+	processor.Plugins, err = utils.DiscoverPlugins(processorConfig.PluginDir, processorConfig.PluginTimeout)
+	if err != nil {
+		logError(err)
+		os.Exit(1)
+	}
+	for name, plugin := range processor.Plugins {
+		fmt.Printf("Plugin %s: %s\n", name, plugin.Description)
+	}
+	err = processor.ClaimOrphans()
+	logError(err)
+	err = processor.RecoverIntents()
+	logError(err)
+	err = processor.RestoreAppStates()
+	logError(err)
+	err = processor.RestorePolicies()
+	logError(err)
+	err = processor.RestoreRoutes()
+	logError(err)
+	err = processor.RestoreSecGroups()
+	logError(err)
+	err = processor.RollbackPlugins()
+	logError(err)
 
-	for _, service := range services {
-		if utils.ShouldProcess(service.Frequency, service.LastProcessed) {
-			fmt.Printf("Processing chaos for %s\n", service.AppID)
-			err = utils.UpdateLastProcessed(db, service.AppID, utils.TimeNow())
-			if logError(err) {
-				continue
-			}
-			if utils.ShouldRun(service.Probability) {
-				fmt.Printf("Running chaos for %s\n", service.AppID)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
 
-				appInstances, err := cfClient.GetAppInstances(service.AppID)
-				if err != nil {
-					logError(err)
-					continue
-				}
+	ticker := time.NewTicker(1 * time.Minute)
+	done := make(chan struct{})
+	go func() {
+		processor.Run(ticker.C)
+		close(done)
+	}()
 
-				if utils.IsAppHealthy(appInstances) {
-					fmt.Printf("App %s is Healthy\n", service.AppID)
-					chaosInstance := strconv.Itoa(utils.PickAppInstance(appInstances))
-					fmt.Printf("About to kill app instance: %s at index: %s\n", service.AppID, chaosInstance)
-					cfClient.KillAppInstance(service.AppID, chaosInstance)
-					err = utils.UpdateLastProcessed(db, service.AppID, utils.TimeNow())
-					logError(err)
-				} else {
-					fmt.Printf("App %s is unhealthy, skipping\n", service.AppID)
-				}
-			} else {
-				fmt.Printf("Not running chaos for %s\n", service.AppID)
-				err = utils.UpdateLastProcessed(db, service.AppID, utils.TimeNow())
-				logError(err)
-			}
-		} else {
-			fmt.Printf("Skipping processing chaos for %s\n", service.AppID)
-		}
-	}
+	sig := <-signals
+	fmt.Printf("Received %s, waiting for in-flight chaos to finish...\n", sig)
+	ticker.Stop()
+	processor.Stop()
+	<-done
+	fmt.Println("Shutdown complete")
 }
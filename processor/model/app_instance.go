@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // AppInstances struct
 type AppInstances struct {
 	Instances map[string]AppInstance
@@ -9,3 +11,76 @@ type AppInstances struct {
 type AppInstance struct {
 	State string `json:"state"`
 }
+
+// AppStats struct - an app instance as returned by the Cloud Foundry instance stats endpoint
+type AppStats struct {
+	State string        `json:"state"`
+	Stats InstanceStats `json:"stats"`
+}
+
+// InstanceStats struct
+type InstanceStats struct {
+	Host   string        `json:"host"`
+	Port   int           `json:"port"`
+	Uptime int64         `json:"uptime"`
+	Usage  InstanceUsage `json:"usage"`
+}
+
+// InstanceUsage struct
+type InstanceUsage struct {
+	CPU  float64 `json:"cpu"`
+	Mem  int64   `json:"mem"`
+	Disk int64   `json:"disk"`
+}
+
+// AppEvent struct - an event of an app as returned by the Cloud Foundry events endpoint
+type AppEvent struct {
+	Type      string        `json:"type"`
+	ActorName string        `json:"actor_name"`
+	Timestamp time.Time     `json:"timestamp"`
+	MetaData  EventMetaData `json:"metadata"`
+}
+
+// EventMetaData struct - the app instance a crash event was recorded for
+type EventMetaData struct {
+	Index    int    `json:"index"`
+	Instance string `json:"instance"`
+}
+
+// AppLocation struct - the space and org an app is deployed to
+type AppLocation struct {
+	SpaceID string
+	OrgID   string
+}
+
+// AppState struct - the requested state, instance count, instance quotas and package state of an app
+type AppState struct {
+	State            string `json:"state"`
+	Instances        int    `json:"instances"`
+	Memory           int    `json:"memory"`
+	DiskQuota        int    `json:"disk_quota"`
+	PackageState     string `json:"package_state"`
+	PackageUpdatedAt string `json:"package_updated_at"`
+}
+
+// StateSnapshot struct - the requested state and instance count of an app stopped or scaled down by an action, saved before
+// the action so it can be restored
+type StateSnapshot struct {
+	ID        string `json:"id"`
+	AppID     string `json:"app_guid"`
+	Action    string `json:"action"`
+	State     string `json:"state"`
+	Instances int    `json:"instances"`
+	Owner     string `json:"owner"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Task struct - a Cloud Foundry v3 task, a one off command run with the droplet of an app
+type Task struct {
+	GUID       string `json:"guid,omitempty"`
+	Name       string `json:"name"`
+	Command    string `json:"command"`
+	State      string `json:"state,omitempty"`
+	MemoryInMB int    `json:"memory_in_mb,omitempty"`
+	DiskInMB   int    `json:"disk_in_mb,omitempty"`
+}
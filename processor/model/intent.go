@@ -0,0 +1,25 @@
+package model
+
+// Intent states
+const (
+	IntentPending   = "pending"
+	IntentCompleted = "completed"
+	IntentFailed    = "failed"
+	IntentAborted   = "aborted"
+	IntentUnknown   = "unknown"
+)
+
+// Intent struct - a chaos action that has been decided on, recorded before it is carried out
+type Intent struct {
+	ID            string `json:"id"`
+	AppID         string `json:"app_guid"`
+	InstanceIndex string `json:"instance_index"`
+	Owner         string `json:"owner"`
+	State         string `json:"state"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+	Action        string `json:"action"`
+	OrgID         string `json:"org_guid"`
+	SpaceID       string `json:"space_guid"`
+	EventID       int64  `json:"event_id"`
+}
@@ -0,0 +1,39 @@
+package model
+
+// Policies struct - a list of container to container network policies, as sent to and returned by the policy server
+type Policies struct {
+	Policies []Policy `json:"policies"`
+}
+
+// Policy struct - a network policy allowing one app to reach another over container networking
+type Policy struct {
+	Source      PolicySource      `json:"source"`
+	Destination PolicyDestination `json:"destination"`
+}
+
+// PolicySource struct
+type PolicySource struct {
+	ID string `json:"id"`
+}
+
+// PolicyDestination struct
+type PolicyDestination struct {
+	ID       string      `json:"id"`
+	Protocol string      `json:"protocol"`
+	Ports    PolicyPorts `json:"ports"`
+}
+
+// PolicyPorts struct
+type PolicyPorts struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// PolicySnapshot struct - network policies removed from an app, saved before removal so they can be restored
+type PolicySnapshot struct {
+	ID        string   `json:"id"`
+	AppID     string   `json:"app_guid"`
+	Owner     string   `json:"owner"`
+	Policies  []Policy `json:"policies"`
+	CreatedAt string   `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import "encoding/json"
+
+// Plugin struct - an executable found in the plugin directory that takes a custom chaos action
+type Plugin struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Path        string `json:"path"`
+}
+
+// Plugin commands, each sent to a plugin on its stdin as the command of a PluginRequest
+const (
+	PluginDescribe = "describe"
+	PluginValidate = "validate"
+	PluginRun      = "run"
+	PluginRollback = "rollback"
+)
+
+// PluginRequest struct - the JSON a plugin reads from its stdin
+type PluginRequest struct {
+	Command           string          `json:"command"`
+	AppID             string          `json:"app_guid,omitempty"`
+	ServiceInstanceID string          `json:"service_instance_guid,omitempty"`
+	ServiceBindingID  string          `json:"service_binding_guid,omitempty"`
+	Seed              int64           `json:"seed,omitempty"`
+	Intensity         int             `json:"intensity,omitempty"`
+	DelaySeconds      int             `json:"delay_seconds,omitempty"`
+	State             json.RawMessage `json:"state,omitempty"`
+}
+
+// PluginResponse struct - the JSON a plugin writes to its stdout. Describe answers with the name and description, validate
+// with valid and the reason when it is not, run with the state rollback is given back, and any command with the error it
+// failed with.
+type PluginResponse struct {
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Valid       bool            `json:"valid,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+	State       json.RawMessage `json:"state,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// PluginSnapshot struct - the state a plugin returned from running its action against an app, saved so that the action
+// can be rolled back
+type PluginSnapshot struct {
+	ID        string          `json:"id"`
+	Plugin    string          `json:"plugin"`
+	AppID     string          `json:"app_guid"`
+	State     json.RawMessage `json:"state"`
+	Owner     string          `json:"owner"`
+	CreatedAt string          `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+// RouteMapping struct - a route mapped to an app, routing requests for it to a port of the app's instances
+type RouteMapping struct {
+	GUID    string `json:"-"`
+	AppID   string `json:"app_guid"`
+	RouteID string `json:"route_guid"`
+	AppPort int    `json:"app_port,omitempty"`
+}
+
+// MappingSnapshot struct - a route mapping removed from an app, saved before removal so it can be restored
+type MappingSnapshot struct {
+	ID        string       `json:"id"`
+	Owner     string       `json:"owner"`
+	Mapping   RouteMapping `json:"mapping"`
+	CreatedAt string       `json:"created_at"`
+}
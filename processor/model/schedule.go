@@ -0,0 +1,8 @@
+package model
+
+// Schedule struct - when the next failure of an app run as a Poisson process is due, drawn for its mean time between failures
+type Schedule struct {
+	AppID         string `json:"app_guid"`
+	MTBFMinutes   int    `json:"mtbf_minutes"`
+	NextFailureAt string `json:"next_failure_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// SecGroupSnapshot struct - the running security groups bound to a space before the egress of its apps was blackholed,
+// saved before they are unbound so they can be bound again
+type SecGroupSnapshot struct {
+	ID          string   `json:"id"`
+	AppID       string   `json:"app_guid"`
+	SpaceID     string   `json:"space_guid"`
+	BlackholeID string   `json:"blackhole_guid"`
+	Groups      []string `json:"groups"`
+	Owner       string   `json:"owner"`
+	CreatedAt   string   `json:"created_at"`
+}
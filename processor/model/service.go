@@ -1,9 +1,22 @@
 package model
 
+import (
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+)
+
 // Service struct
 type Service struct {
-	Probability   float64 `json:"probability"`
-	Frequency     int     `json:"frequency"`
-	AppID         string  `json:"app_guid"`
-	LastProcessed string  `json:"LastProcessed"`
+	Probability       float64                       `json:"probability"`
+	Frequency         int                           `json:"frequency"`
+	AppID             string                        `json:"app_guid"`
+	LastProcessed     string                        `json:"LastProcessed"`
+	ServiceInstanceID string                        `json:"service_instance_id"`
+	ServiceBindingID  string                        `json:"service_binding_id"`
+	Settings          sharedModel.InstanceSettings  `json:"settings"`
+	Probes            []sharedModel.Probe           `json:"probes"`
+	Profile           []sharedModel.WeightedAction  `json:"profile"`
+	Paused            bool                          `json:"paused"`
+	LastChange        sharedModel.ProbabilityChange `json:"last_change"`
+	CampaignID        int64                         `json:"campaign_id"`
+	Replay            sharedModel.Replay            `json:"replay"`
 }
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/cloudfoundry-community/go-cfclient"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DescribeState - describes an app and its instances as the state before or after a chaos action,
+// e.g. "STARTED with 3 instances (1 CRASHED, 2 RUNNING)"
+func DescribeState(appState model.AppState, appInstances map[string]cfclient.AppInstance) string {
+	description := fmt.Sprintf("%s with %d instances", appState.State, appState.Instances)
+	if len(appInstances) == 0 {
+		return description
+	}
+
+	counts := make(map[string]int)
+	for _, instance := range appInstances {
+		counts[instance.State]++
+	}
+	var states []string
+	for state := range counts {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	var parts []string
+	for _, state := range states {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+	}
+	return fmt.Sprintf("%s (%s)", description, strings.Join(parts, ", "))
+}
+
+// ActionIndexes - returns the instance indexes disrupted by an app wide chaos action, every instance for a restart,
+// stop-start or restage and the highest victims indexes for a scale down, as Cloud Foundry removes those first
+func ActionIndexes(action string, appInstances map[string]cfclient.AppInstance, victims int) string {
+	indexes := InstanceIndexes(appInstances)
+	if action == sharedModel.ActionScale && victims < len(indexes) {
+		indexes = indexes[len(indexes)-victims:]
+	}
+	return JoinIndexes(indexes)
+}
+
+// JoinIndexes - returns instance indexes as the comma separated list recorded with an event
+func JoinIndexes(indexes []int) string {
+	var values []string
+	for _, index := range indexes {
+		values = append(values, strconv.Itoa(index))
+	}
+	return strings.Join(values, ",")
+}
+
+// ResourceTask - returns the task exhausting the CPU, memory or disk of an app at an intensity between 1 and 100 percent.
+// Every command ends itself once the duration has passed, so a task outlives the processor by no more than that.
+func ResourceTask(action string, appState model.AppState, intensity int, duration time.Duration) model.Task {
+	seconds := int(duration.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	task := model.Task{Name: fmt.Sprintf("chaos-galago-%s", action)}
+	switch action {
+	case sharedModel.ActionCPU:
+		task.Command = fmt.Sprintf(`n=$(( ($(nproc) * %d + 99) / 100 )); for i in $(seq $n); do timeout %d sh -c 'while :; do :; done' & done; wait`,
+			intensity, seconds)
+	case sharedModel.ActionMemory:
+		task.MemoryInMB = appState.Memory
+		task.Command = fmt.Sprintf(`timeout %d sh -c 'x=$(head -c %d /dev/zero | tr "\000" x); sleep %d'`,
+			seconds, share(appState.Memory, intensity)*1024*1024, seconds)
+	case sharedModel.ActionDisk:
+		task.DiskInMB = appState.DiskQuota
+		task.Command = fmt.Sprintf(`timeout %d sh -c 'dd if=/dev/zero of=chaos-galago-fill bs=1048576 count=%d 2>/dev/null; sleep %d'; rm -f chaos-galago-fill`,
+			seconds, share(appState.DiskQuota, intensity), seconds)
+	}
+	return task
+}
+
+// TaskFinished - determines if a task has stopped running
+func TaskFinished(task model.Task) bool {
+	return task.State == "SUCCEEDED" || task.State == "FAILED"
+}
+
+// share - returns a percentage of a quota in megabytes, at least 1
+func share(quota int, percent int) int {
+	if mb := quota * percent / 100; mb > 1 {
+		return mb
+	}
+	return 1
+}
+
+// SampleAction - picks one of the actions of a profile at random, each in proportion to its weight, or the fallback action
+// when the profile has no positive weights
+func SampleAction(rng *rand.Rand, profile []sharedModel.WeightedAction, fallback string) string {
+	var total int
+	for _, weighted := range profile {
+		if weighted.Weight > 0 {
+			total += weighted.Weight
+		}
+	}
+	if total == 0 {
+		return fallback
+	}
+
+	pick := rng.Intn(total)
+	for _, weighted := range profile {
+		if weighted.Weight <= 0 {
+			continue
+		}
+		if pick < weighted.Weight {
+			return weighted.Action
+		}
+		pick -= weighted.Weight
+	}
+	return fallback
+}
@@ -0,0 +1,122 @@
+package utils_test
+
+import (
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("Actions", func() {
+	var appInstances map[string]cfclient.AppInstance
+
+	BeforeEach(func() {
+		appInstances = map[string]cfclient.AppInstance{
+			"0": {State: "RUNNING"},
+			"1": {State: "CRASHED"},
+			"2": {State: "RUNNING"},
+		}
+	})
+
+	Describe("#DescribeState", func() {
+		It("describes the app and counts its instances by state", func() {
+			Expect(utils.DescribeState(model.AppState{State: "STARTED", Instances: 3}, appInstances)).To(Equal("STARTED with 3 instances (1 CRASHED, 2 RUNNING)"))
+		})
+
+		Context("When the app has no instances", func() {
+			It("describes the app alone", func() {
+				Expect(utils.DescribeState(model.AppState{State: "STOPPED", Instances: 3}, nil)).To(Equal("STOPPED with 3 instances"))
+			})
+		})
+	})
+
+	Describe("#ActionIndexes", func() {
+		It("returns every instance for an app wide action", func() {
+			Expect(utils.ActionIndexes(sharedModel.ActionRestart, appInstances, 1)).To(Equal("0,1,2"))
+		})
+
+		Context("When the app is scaled down", func() {
+			It("returns the highest indexes", func() {
+				Expect(utils.ActionIndexes(sharedModel.ActionScale, appInstances, 2)).To(Equal("1,2"))
+			})
+		})
+	})
+
+	Describe("#JoinIndexes", func() {
+		It("returns a comma separated list", func() {
+			Expect(utils.JoinIndexes([]int{0, 2, 5})).To(Equal("0,2,5"))
+		})
+	})
+
+	Describe("#ResourceTask", func() {
+		var appState model.AppState
+
+		BeforeEach(func() {
+			appState = model.AppState{State: "STARTED", Instances: 2, Memory: 256, DiskQuota: 1024}
+		})
+
+		It("burns a share of the CPUs for the duration", func() {
+			task := utils.ResourceTask(sharedModel.ActionCPU, appState, 50, time.Minute)
+			Expect(task.Name).To(Equal("chaos-galago-cpu-burn"))
+			Expect(task.Command).To(Equal(`n=$(( ($(nproc) * 50 + 99) / 100 )); for i in $(seq $n); do timeout 60 sh -c 'while :; do :; done' & done; wait`))
+		})
+
+		It("holds a share of the app memory for the duration", func() {
+			task := utils.ResourceTask(sharedModel.ActionMemory, appState, 50, time.Minute)
+			Expect(task.MemoryInMB).To(Equal(256))
+			Expect(task.Command).To(Equal(`timeout 60 sh -c 'x=$(head -c 134217728 /dev/zero | tr "\000" x); sleep 60'`))
+		})
+
+		It("fills a share of the app disk for the duration", func() {
+			task := utils.ResourceTask(sharedModel.ActionDisk, appState, 25, 30*time.Second)
+			Expect(task.DiskInMB).To(Equal(1024))
+			Expect(task.Command).To(Equal(`timeout 30 sh -c 'dd if=/dev/zero of=chaos-galago-fill bs=1048576 count=256 2>/dev/null; sleep 30'; rm -f chaos-galago-fill`))
+		})
+
+		Context("When the duration is under a second", func() {
+			It("runs for a second", func() {
+				task := utils.ResourceTask(sharedModel.ActionDisk, appState, 1, 0)
+				Expect(task.Command).To(Equal(`timeout 1 sh -c 'dd if=/dev/zero of=chaos-galago-fill bs=1048576 count=10 2>/dev/null; sleep 1'; rm -f chaos-galago-fill`))
+			})
+		})
+	})
+
+	Describe("#TaskFinished", func() {
+		It("is true once the task has succeeded or failed", func() {
+			Expect(utils.TaskFinished(model.Task{State: "SUCCEEDED"})).To(BeTrue())
+			Expect(utils.TaskFinished(model.Task{State: "FAILED"})).To(BeTrue())
+			Expect(utils.TaskFinished(model.Task{State: "RUNNING"})).To(BeFalse())
+		})
+	})
+
+	Describe("#SampleAction", func() {
+		var profile = []sharedModel.WeightedAction{{Action: "kill", Weight: 70}, {Action: "restart", Weight: 20}, {Action: "network-partition", Weight: 10}}
+
+		It("picks actions in proportion to their weights", func() {
+			rng := utils.NewRand(42)
+			counts := make(map[string]int)
+			for i := 0; i < 10000; i++ {
+				counts[utils.SampleAction(rng, profile, "kill")]++
+			}
+			Expect(counts["kill"]).To(BeNumerically("~", 7000, 300))
+			Expect(counts["restart"]).To(BeNumerically("~", 2000, 300))
+			Expect(counts["network-partition"]).To(BeNumerically("~", 1000, 300))
+		})
+
+		It("never picks an action without weight", func() {
+			rng := utils.NewRand(42)
+			for i := 0; i < 100; i++ {
+				Expect(utils.SampleAction(rng, []sharedModel.WeightedAction{{Action: "kill", Weight: 0}, {Action: "restage", Weight: 1}}, "kill")).To(Equal("restage"))
+			}
+		})
+
+		Context("When the profile has no weights", func() {
+			It("returns the fallback action", func() {
+				Expect(utils.SampleAction(utils.NewRand(42), nil, "scale")).To(Equal("scale"))
+			})
+		})
+	})
+})
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"net/http"
+)
+
+// SendAlert - posts a paused binding as JSON to an alert webhook
+func SendAlert(client *http.Client, alertURL string, pause sharedModel.PausedBinding) error {
+	body, err := json.Marshal(pause)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(alertURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("alert %s returned status %d", alertURL, resp.StatusCode)
+	}
+	return nil
+}
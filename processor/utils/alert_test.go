@@ -0,0 +1,52 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = Describe("#SendAlert", func() {
+	var (
+		pause  = sharedModel.PausedBinding{ServiceBindingID: "2", ServiceInstanceID: "1", AppID: "app-1", PausedAt: "2014-11-12T10:31:20Z", Reason: "app crashed 3 times"}
+		client = &http.Client{Timeout: time.Second}
+	)
+
+	Context("When the webhook accepts the alert", func() {
+		It("posts the paused binding as JSON", func() {
+			var received sharedModel.PausedBinding
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal("POST"))
+				Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+				json.NewDecoder(r.Body).Decode(&received)
+			}))
+			defer server.Close()
+
+			Expect(utils.SendAlert(client, server.URL, pause)).To(BeNil())
+			Expect(received).To(Equal(pause))
+		})
+	})
+
+	Context("When the webhook returns an error", func() {
+		It("returns an error", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			Expect(utils.SendAlert(client, server.URL, pause)).To(MatchError(fmt.Sprintf("alert %s returned status 500", server.URL)))
+		})
+	})
+
+	Context("When the webhook cannot be reached", func() {
+		It("returns an error", func() {
+			Expect(utils.SendAlert(client, "http://127.0.0.1:1/alerts", pause)).ToNot(BeNil())
+		})
+	})
+})
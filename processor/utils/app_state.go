@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+)
+
+// SetupStateSnapshotDB - creates the state_snapshots DB if it does not exist
+func SetupStateSnapshotDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS state_snapshots
+	(
+		id varchar(255),
+		appID varchar(255),
+		action varchar(255),
+		state varchar(255),
+		instances int,
+		owner varchar(255),
+		createdAt varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveStateSnapshot - adds a row to state_snapshots database
+func SaveStateSnapshot(db *sql.DB, snapshot model.StateSnapshot) error {
+	_, err := db.Exec("INSERT INTO state_snapshots (id, appID, action, state, instances, owner, createdAt) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		snapshot.ID, snapshot.AppID, snapshot.Action, snapshot.State, snapshot.Instances, snapshot.Owner, snapshot.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteStateSnapshot - removes a row from state_snapshots database once its app is restored
+func DeleteStateSnapshot(db *sql.DB, snapshotID string) error {
+	_, err := db.Exec("DELETE FROM state_snapshots WHERE id=?", snapshotID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetStateSnapshots - loads the snapshots an owner saved and has not yet restored
+func GetStateSnapshots(db *sql.DB, owner string) ([]model.StateSnapshot, error) {
+	var snapshots []model.StateSnapshot
+
+	rows, err := db.Query("SELECT id, appID, action, state, instances, owner, createdAt FROM state_snapshots WHERE owner=?", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var snapshot model.StateSnapshot
+		if err = rows.Scan(&snapshot.ID, &snapshot.AppID, &snapshot.Action, &snapshot.State, &snapshot.Instances, &snapshot.Owner, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,83 @@
+package utils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("State snapshots", func() {
+	var (
+		mock     sqlmock.Sqlmock
+		db       *sql.DB
+		snapshot model.StateSnapshot
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		snapshot = model.StateSnapshot{ID: "1", AppID: "app-1", Action: "scale", State: "STARTED", Instances: 3, Owner: "0", CreatedAt: "2014-11-12T10:31:20Z"}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupStateSnapshotDB", func() {
+		It("creates the state_snapshots table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS state_snapshots.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupStateSnapshotDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#SaveStateSnapshot", func() {
+		It("inserts the snapshot", func() {
+			mock.ExpectExec("INSERT INTO state_snapshots .*").WithArgs("1", "app-1", "scale", "STARTED", 3, "0", "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SaveStateSnapshot(db, snapshot)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO state_snapshots .*").WillReturnError(fmt.Errorf("An error has occurred: %s", "Database Insert Error"))
+				Expect(utils.SaveStateSnapshot(db, snapshot)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeleteStateSnapshot", func() {
+		It("deletes the snapshot", func() {
+			mock.ExpectExec("DELETE FROM state_snapshots WHERE id=\\?").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(utils.DeleteStateSnapshot(db, "1")).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#GetStateSnapshots", func() {
+		It("returns the snapshots of the owner", func() {
+			rows := sqlmock.NewRows([]string{"id", "appID", "action", "state", "instances", "owner", "createdAt"}).AddRow("1", "app-1", "scale", "STARTED", 3, "0", "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("SELECT .* FROM state_snapshots WHERE owner=\\?").WithArgs("0").WillReturnRows(rows)
+			snapshots, err := utils.GetStateSnapshots(db, "0")
+			Expect(err).To(BeNil())
+			Expect(snapshots).To(Equal([]model.StateSnapshot{snapshot}))
+		})
+
+		Context("When the snapshots cannot be read", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("SELECT .* FROM state_snapshots WHERE owner=\\?").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.GetStateSnapshots(db, "0")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/config"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/go-sql-driver/mysql"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	eventTimeLayout = "2006-01-02T15:04:05Z"
+	budgetLock      = "budget"
+	adaptiveLock    = "adaptive"
+	lockWaitTimeout = 1205
+)
+
+// SetupLockDB - creates the chaos_locks DB if it does not exist, with a row for every lock shared by the processor instances
+func SetupLockDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS chaos_locks
+	(
+		name varchar(255),
+		PRIMARY KEY (name)
+	)`)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{budgetLock, adaptiveLock} {
+		_, err = db.Exec("INSERT IGNORE INTO chaos_locks (name) VALUES (?)", name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockBudget - locks the chaos_locks row every processor instance checks and reserves the budgets under, waiting up to
+// timeout for it. The row is locked by a transaction of its own, which the returned function commits to release it.
+func LockBudget(db *sql.DB, timeout time.Duration) (func(), error) {
+	return lock(db, budgetLock, timeout)
+}
+
+// LockAdaptive - locks the chaos_locks row every processor instance changes the probability of adaptive chaos under,
+// waiting up to timeout for it, and returns the function releasing it
+func LockAdaptive(db *sql.DB, timeout time.Duration) (func(), error) {
+	return lock(db, adaptiveLock, timeout)
+}
+
+func lock(db *sql.DB, name string, timeout time.Duration) (func(), error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec("SET SESSION innodb_lock_wait_timeout = ?", int(math.Max(1, math.Ceil(timeout.Seconds()))))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var locked string
+	err = tx.QueryRow("SELECT name FROM chaos_locks WHERE name=? FOR UPDATE", name).Scan(&locked)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, fmt.Errorf("the %s lock is missing from chaos_locks", name)
+	}
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == lockWaitTimeout {
+		tx.Rollback()
+		return nil, fmt.Errorf("the %s lock was not released within %s", name, timeout)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return func() {
+		tx.Commit()
+	}, nil
+}
+
+// BudgetExhausted - returns which budget another action against the app of an event would exceed, blank if none would
+func BudgetExhausted(db *sql.DB, budget config.Budget, event sharedModel.Event, now time.Time, recoveryTimeout time.Duration) (string, error) {
+	limits := []struct {
+		max    int
+		window time.Duration
+		column string
+		value  string
+		reason string
+	}{
+		{budget.PerMinute, time.Minute, "", "", "%d actions per minute across the foundation"},
+		{budget.PerHour, time.Hour, "", "", "%d actions per hour across the foundation"},
+		{budget.OrgPerMinute, time.Minute, "orgID", event.OrgID, "%d actions per minute in org " + event.OrgID},
+		{budget.OrgPerHour, time.Hour, "orgID", event.OrgID, "%d actions per hour in org " + event.OrgID},
+		{budget.SpacePerMinute, time.Minute, "spaceID", event.SpaceID, "%d actions per minute in space " + event.SpaceID},
+		{budget.SpacePerHour, time.Hour, "spaceID", event.SpaceID, "%d actions per hour in space " + event.SpaceID},
+	}
+
+	for _, limit := range limits {
+		if limit.max <= 0 {
+			continue
+		}
+		actions, err := CountActions(db, now.Add(-limit.window), limit.column, limit.value)
+		if err != nil {
+			return "", err
+		}
+		if actions >= limit.max {
+			return fmt.Sprintf(limit.reason, limit.max), nil
+		}
+	}
+
+	if budget.MaxUnrecoveredApps > 0 && recoveryTimeout > 0 {
+		apps, err := CountUnrecoveredApps(db, now.Add(-recoveryTimeout), event.AppID)
+		if err != nil {
+			return "", err
+		}
+		if apps >= budget.MaxUnrecoveredApps {
+			return fmt.Sprintf("%d apps not yet recovered", budget.MaxUnrecoveredApps), nil
+		}
+	}
+	return "", nil
+}
+
+// CountActions - counts the chaos actions taken since a time, only those where column equals value when a column is given.
+// Actions that timed out are counted too, as they may still have landed, and so are the pending intents of actions not yet
+// recorded, which reserve their share of the budgets while the action is taken.
+func CountActions(db *sql.DB, since time.Time, column string, value string) (int, error) {
+	var actions int
+
+	filter := "createdAt>=?"
+	filterArgs := []interface{}{since.UTC().Format(eventTimeLayout)}
+	if column != "" {
+		filter += fmt.Sprintf(" AND %s=?", column)
+		filterArgs = append(filterArgs, value)
+	}
+	query := fmt.Sprintf("SELECT (SELECT COUNT(*) FROM chaos_events WHERE outcome IN (?, ?) AND %s) + (SELECT COUNT(*) FROM chaos_intents WHERE state=? AND %s)", filter, filter)
+	args := append([]interface{}{sharedModel.OutcomeSucceeded, sharedModel.OutcomeUnknown}, filterArgs...)
+	args = append(append(args, model.IntentPending), filterArgs...)
+	err := db.QueryRow(query, args...).Scan(&actions)
+	if err != nil {
+		return 0, err
+	}
+	return actions, nil
+}
+
+// CountUnrecoveredApps - counts the apps other than appID with an instance killed since a time that has not yet recovered.
+// Only the actions taking instances down are watched for recovery, so the events of those leaving them running, which
+// never get a recovery, are not counted.
+func CountUnrecoveredApps(db *sql.DB, since time.Time, appID string) (int, error) {
+	var apps int
+
+	args := []interface{}{sharedModel.OutcomeSucceeded, since.UTC().Format(eventTimeLayout), appID}
+	var placeholders []string
+	for _, action := range sharedModel.Actions {
+		if !sharedModel.KeepsInstances(action) {
+			args = append(args, action)
+			placeholders = append(placeholders, "?")
+		}
+	}
+	query := fmt.Sprintf("SELECT COUNT(DISTINCT appID) FROM chaos_events WHERE outcome=? AND createdAt>=? AND (recovery IS NULL OR recovery='') AND appID<>? AND action IN (%s)",
+		strings.Join(placeholders, ", "))
+	err := db.QueryRow(query, args...).Scan(&apps)
+	if err != nil {
+		return 0, err
+	}
+	return apps, nil
+}
@@ -0,0 +1,186 @@
+package utils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/config"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+	"time"
+)
+
+var _ = Describe("Budgets", func() {
+	var (
+		db    *sql.DB
+		mock  sqlmock.Sqlmock
+		now   = time.Date(2014, 11, 12, 10, 31, 20, 0, time.UTC)
+		event = sharedModel.Event{AppID: "app-1", OrgID: "org-1", SpaceID: "space-1"}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	countRows := func(count int) sqlmock.Rows {
+		return sqlmock.NewRows([]string{"count"}).AddRow(count)
+	}
+
+	Describe("#SetupLockDB", func() {
+		It("creates the chaos_locks table with the budget and adaptive locks", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_locks.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("^INSERT IGNORE INTO chaos_locks \\(name\\) VALUES \\(\\?\\)$").WithArgs("budget").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("^INSERT IGNORE INTO chaos_locks \\(name\\) VALUES \\(\\?\\)$").WithArgs("adaptive").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupLockDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#LockBudget", func() {
+		Context("When the lock is taken", func() {
+			It("holds the lock row in a transaction committed once unlocked", func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("^SET SESSION innodb_lock_wait_timeout = \\?$").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("^SELECT name FROM chaos_locks WHERE name=\\? FOR UPDATE$").WithArgs("budget").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("budget"))
+				mock.ExpectCommit()
+				unlock, err := utils.LockBudget(db, 1500*time.Millisecond)
+				Expect(err).To(BeNil())
+				unlock()
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the lock is not released in time", func() {
+			It("returns an error", func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("^SET SESSION").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("^SELECT name FROM chaos_locks").WillReturnError(&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"})
+				mock.ExpectRollback()
+				_, err := utils.LockBudget(db, time.Second)
+				Expect(err).To(MatchError("the budget lock was not released within 1s"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the lock row is missing", func() {
+			It("returns an error", func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("^SET SESSION").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("^SELECT name FROM chaos_locks").WillReturnRows(sqlmock.NewRows([]string{"name"}))
+				mock.ExpectRollback()
+				_, err := utils.LockBudget(db, time.Second)
+				Expect(err).To(MatchError("the budget lock is missing from chaos_locks"))
+			})
+		})
+
+		Context("When the transaction cannot be begun", func() {
+			It("returns an error", func() {
+				mock.ExpectBegin().WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.LockBudget(db, time.Second)
+				Expect(err).To(MatchError("An error has occurred: DB error"))
+			})
+		})
+	})
+
+	Describe("#BudgetExhausted", func() {
+		Context("When no budget is set", func() {
+			It("returns no reason without querying", func() {
+				reason, err := utils.BudgetExhausted(db, config.Budget{}, event, now, time.Minute)
+				Expect(err).To(BeNil())
+				Expect(reason).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When every budget has room", func() {
+			It("returns no reason", func() {
+				budget := config.Budget{PerMinute: 5, OrgPerHour: 10, SpacePerMinute: 2, MaxUnrecoveredApps: 3}
+				mock.ExpectQuery("^SELECT \\(SELECT COUNT\\(\\*\\) FROM chaos_events WHERE outcome IN \\(\\?, \\?\\) AND createdAt>=\\?\\) \\+ \\(SELECT COUNT\\(\\*\\) FROM chaos_intents WHERE state=\\? AND createdAt>=\\?\\)$").
+					WithArgs("succeeded", "unknown", "2014-11-12T10:30:20Z", "pending", "2014-11-12T10:30:20Z").WillReturnRows(countRows(4))
+				mock.ExpectQuery("AND orgID=\\?\\)$").WithArgs("succeeded", "unknown", "2014-11-12T09:31:20Z", "org-1", "pending", "2014-11-12T09:31:20Z", "org-1").WillReturnRows(countRows(9))
+				mock.ExpectQuery("AND spaceID=\\?\\)$").WithArgs("succeeded", "unknown", "2014-11-12T10:30:20Z", "space-1", "pending", "2014-11-12T10:30:20Z", "space-1").WillReturnRows(countRows(1))
+				mock.ExpectQuery("^SELECT COUNT\\(DISTINCT appID\\) FROM chaos_events (.+) AND action IN \\(\\?, \\?, \\?, \\?, \\?, \\?, \\?\\)$").
+					WithArgs("succeeded", "2014-11-12T10:26:20Z", "app-1", "kill", "restart", "stop-start", "restage", "scale", "cell-outage", "egress-blackhole").WillReturnRows(countRows(2))
+				reason, err := utils.BudgetExhausted(db, budget, event, now, 5*time.Minute)
+				Expect(err).To(BeNil())
+				Expect(reason).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the foundation budget is spent", func() {
+			It("returns the foundation budget", func() {
+				mock.ExpectQuery("^SELECT \\(SELECT COUNT").WillReturnRows(countRows(20))
+				reason, err := utils.BudgetExhausted(db, config.Budget{PerHour: 20, OrgPerMinute: 1}, event, now, time.Minute)
+				Expect(err).To(BeNil())
+				Expect(reason).To(Equal("20 actions per hour across the foundation"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the org budget is spent", func() {
+			It("returns the org budget", func() {
+				mock.ExpectQuery("AND orgID=\\?\\)$").WillReturnRows(countRows(3))
+				reason, err := utils.BudgetExhausted(db, config.Budget{OrgPerMinute: 3}, event, now, time.Minute)
+				Expect(err).To(BeNil())
+				Expect(reason).To(Equal("3 actions per minute in org org-1"))
+			})
+		})
+
+		Context("When the space budget is spent", func() {
+			It("returns the space budget", func() {
+				mock.ExpectQuery("AND spaceID=\\?\\)$").WillReturnRows(countRows(8))
+				reason, err := utils.BudgetExhausted(db, config.Budget{SpacePerHour: 5}, event, now, time.Minute)
+				Expect(err).To(BeNil())
+				Expect(reason).To(Equal("5 actions per hour in space space-1"))
+			})
+		})
+
+		Context("When too many apps have not recovered", func() {
+			It("returns the unrecovered apps budget", func() {
+				mock.ExpectQuery("^SELECT COUNT\\(DISTINCT appID\\)").WillReturnRows(countRows(2))
+				reason, err := utils.BudgetExhausted(db, config.Budget{MaxUnrecoveredApps: 2}, event, now, time.Minute)
+				Expect(err).To(BeNil())
+				Expect(reason).To(Equal("2 apps not yet recovered"))
+			})
+
+			Context("and recovery is not watched", func() {
+				It("does not enforce the unrecovered apps budget", func() {
+					reason, err := utils.BudgetExhausted(db, config.Budget{MaxUnrecoveredApps: 2}, event, now, 0)
+					Expect(err).To(BeNil())
+					Expect(reason).To(BeEmpty())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
+
+		Context("When the actions cannot be counted", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT \\(SELECT COUNT").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.BudgetExhausted(db, config.Budget{PerMinute: 1}, event, now, time.Minute)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		Context("When the unrecovered apps cannot be counted", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT COUNT\\(DISTINCT appID\\)").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.BudgetExhausted(db, config.Budget{MaxUnrecoveredApps: 1}, event, now, time.Minute)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
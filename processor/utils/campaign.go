@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"time"
+)
+
+// ApplyCampaign - overrides the chaos of a bound app with that of a campaign it takes part in. Campaign runs are drawn at the
+// probability and frequency of the campaign, sampling its actions evenly, so a mean time between failures and adaptive chaos
+// give way until the campaign ends.
+func ApplyCampaign(service model.Service, campaign sharedModel.Campaign) model.Service {
+	service.CampaignID = campaign.ID
+	service.Probability = campaign.Probability
+	service.Frequency = campaign.Frequency
+	service.Settings.MTBFMinutes = 0
+	service.Settings.AdaptiveStep = 0
+	if len(campaign.Actions) > 0 {
+		service.Profile = []sharedModel.WeightedAction{}
+		for _, action := range campaign.Actions {
+			service.Profile = append(service.Profile, sharedModel.WeightedAction{Action: action, Weight: 1})
+		}
+	}
+	return service
+}
+
+// CampaignReportDue - determines if the report on a campaign that has ended is due, which it is once the recovery of the apps
+// hit last has had the recovery timeout to be watched. A campaign whose end cannot be read is reported on straight away.
+func CampaignReportDue(campaign sharedModel.Campaign, recoveryTimeout time.Duration, now time.Time) bool {
+	end, err := time.Parse(eventTimeLayout, campaign.EndAt)
+	if err != nil {
+		return true
+	}
+	return !now.Before(end.Add(recoveryTimeout))
+}
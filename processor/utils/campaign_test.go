@@ -0,0 +1,56 @@
+package utils_test
+
+import (
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("#ApplyCampaign", func() {
+	var service model.Service
+
+	BeforeEach(func() {
+		service = model.Service{AppID: "app-1", Probability: 0.1, Frequency: 60, ServiceInstanceID: "1", Settings: sharedModel.NewInstanceSettings("1"),
+			Profile: []sharedModel.WeightedAction{{Action: "kill", Weight: 70}, {Action: "restart", Weight: 30}}}
+		service.Settings.MTBFMinutes = 240
+		service.Settings.AdaptiveStep = 5
+	})
+
+	It("runs chaos at the probability, frequency and actions of the campaign", func() {
+		campaigned := utils.ApplyCampaign(service, sharedModel.Campaign{ID: 4, Probability: 1, Frequency: 5, Actions: []string{"stop-start", "cpu-burn"}})
+		Expect(campaigned.CampaignID).To(Equal(int64(4)))
+		Expect(campaigned.Probability).To(Equal(1.0))
+		Expect(campaigned.Frequency).To(Equal(5))
+		Expect(campaigned.Settings.UsesMTBF()).To(BeFalse())
+		Expect(campaigned.Settings.Adaptive()).To(BeFalse())
+		Expect(campaigned.Profile).To(Equal([]sharedModel.WeightedAction{{Action: "stop-start", Weight: 1}, {Action: "cpu-burn", Weight: 1}}))
+	})
+
+	Context("When the campaign has no actions", func() {
+		It("keeps the actions of the service instance", func() {
+			campaigned := utils.ApplyCampaign(service, sharedModel.Campaign{ID: 4, Probability: 1, Frequency: 5})
+			Expect(campaigned.Profile).To(Equal(service.Profile))
+		})
+	})
+})
+
+var _ = Describe("#CampaignReportDue", func() {
+	var (
+		campaign = sharedModel.Campaign{EndAt: "2014-11-12T12:00:00Z"}
+		end      = time.Date(2014, 11, 12, 12, 0, 0, 0, time.UTC)
+	)
+
+	It("waits for the recovery timeout after the end", func() {
+		Expect(utils.CampaignReportDue(campaign, 5*time.Minute, end.Add(4*time.Minute))).To(BeFalse())
+		Expect(utils.CampaignReportDue(campaign, 5*time.Minute, end.Add(5*time.Minute))).To(BeTrue())
+	})
+
+	Context("When the end cannot be read", func() {
+		It("is due straight away", func() {
+			Expect(utils.CampaignReportDue(sharedModel.Campaign{EndAt: "noon"}, 5*time.Minute, end)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,332 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/cloudfoundry-community/go-cfclient"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// crashEventTypes - the Cloud Controller event types recorded when an app instance crashes, which go-cfclient cannot list.
+// Older foundations only record app.crash, newer ones audit.app.process.crash, and some record both for the same crash.
+const crashEventTypes = "app.crash,audit.app.process.crash"
+
+// deployEventTypes - the Cloud Controller event types recorded when an app is pushed, updated or restaged
+const deployEventTypes = "audit.app.update,audit.app.restage"
+
+// CFAPI - a Cloud Foundry client extended with the Cloud Controller endpoints go-cfclient does not provide.
+// Config must be the config the client was created with, holding the authenticated HTTP client.
+type CFAPI struct {
+	*cfclient.Client
+	Config *cfclient.Config
+}
+
+// GetAppStats - fetches the instance stats of an app
+func (c *CFAPI) GetAppStats(guid string) (map[string]model.AppStats, error) {
+	var appStats map[string]model.AppStats
+
+	err := c.doJSON("GET", fmt.Sprintf("/v2/apps/%s/stats", guid), nil, &appStats)
+	if err != nil {
+		return nil, fmt.Errorf("Error requesting app stats %v", err)
+	}
+	return appStats, nil
+}
+
+// CountAppCrashes - counts the crashes of an app since a time, counting a crash recorded as both crash event types once
+// by its instance index, instance guid and timestamp
+func (c *CFAPI) CountAppCrashes(guid string, since time.Time) (int, error) {
+	events, err := c.listEvents(crashEventTypes, guid, since)
+	if err != nil {
+		return 0, fmt.Errorf("Error requesting app crash events %v", err)
+	}
+	crashes := map[string]bool{}
+	for _, event := range events {
+		crashes[fmt.Sprintf("%d/%s/%d", event.MetaData.Index, event.MetaData.Instance, event.Timestamp.Unix())] = true
+	}
+	return len(crashes), nil
+}
+
+// CountAppDeploys - counts the update and restage events of an app since a time, leaving out the events of the
+// processor's own actions, which are made as the user it logs in as
+func (c *CFAPI) CountAppDeploys(guid string, since time.Time) (int, error) {
+	events, err := c.listEvents(deployEventTypes, guid, since)
+	if err != nil {
+		return 0, fmt.Errorf("Error requesting app deploy events %v", err)
+	}
+	var deploys int
+	for _, event := range events {
+		if event.ActorName != c.Config.Username {
+			deploys++
+		}
+	}
+	return deploys, nil
+}
+
+// GetAppLocation - fetches the space and org of an app
+func (c *CFAPI) GetAppLocation(guid string) (model.AppLocation, error) {
+	var app struct {
+		Entity struct {
+			SpaceID string `json:"space_guid"`
+		} `json:"entity"`
+	}
+	var space struct {
+		Entity struct {
+			OrgID string `json:"organization_guid"`
+		} `json:"entity"`
+	}
+
+	err := c.doJSON("GET", fmt.Sprintf("/v2/apps/%s", guid), nil, &app)
+	if err != nil {
+		return model.AppLocation{}, fmt.Errorf("Error requesting app %v", err)
+	}
+	err = c.doJSON("GET", fmt.Sprintf("/v2/spaces/%s", app.Entity.SpaceID), nil, &space)
+	if err != nil {
+		return model.AppLocation{}, fmt.Errorf("Error requesting space %v", err)
+	}
+	return model.AppLocation{SpaceID: app.Entity.SpaceID, OrgID: space.Entity.OrgID}, nil
+}
+
+// CountSpaceApps - counts the apps in a space
+func (c *CFAPI) CountSpaceApps(spaceGUID string) (int, error) {
+	var apps struct {
+		TotalResults int `json:"total_results"`
+	}
+
+	err := c.doJSON("GET", fmt.Sprintf("/v2/spaces/%s/apps?results-per-page=1", spaceGUID), nil, &apps)
+	if err != nil {
+		return 0, fmt.Errorf("Error requesting space apps %v", err)
+	}
+	return apps.TotalResults, nil
+}
+
+// GetAppState - fetches the requested state and instance count of an app
+func (c *CFAPI) GetAppState(guid string) (model.AppState, error) {
+	var app struct {
+		Entity model.AppState `json:"entity"`
+	}
+
+	err := c.doJSON("GET", fmt.Sprintf("/v2/apps/%s", guid), nil, &app)
+	if err != nil {
+		return model.AppState{}, fmt.Errorf("Error requesting app %v", err)
+	}
+	return app.Entity, nil
+}
+
+// UpdateAppState - starts or stops an app
+func (c *CFAPI) UpdateAppState(guid string, state string) error {
+	err := c.doJSON("PUT", fmt.Sprintf("/v2/apps/%s", guid), map[string]string{"state": state}, nil)
+	if err != nil {
+		return fmt.Errorf("Error updating app state %v", err)
+	}
+	return nil
+}
+
+// ScaleApp - sets the number of instances of an app
+func (c *CFAPI) ScaleApp(guid string, instances int) error {
+	err := c.doJSON("PUT", fmt.Sprintf("/v2/apps/%s", guid), map[string]int{"instances": instances}, nil)
+	if err != nil {
+		return fmt.Errorf("Error scaling app %v", err)
+	}
+	return nil
+}
+
+// RestageApp - restages an app, rebuilding its droplet and restarting every instance
+func (c *CFAPI) RestageApp(guid string) error {
+	err := c.doJSON("POST", fmt.Sprintf("/v2/apps/%s/restage", guid), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Error restaging app %v", err)
+	}
+	return nil
+}
+
+// RunTask - starts a task with the droplet of an app, returning the task as created
+func (c *CFAPI) RunTask(guid string, task model.Task) (model.Task, error) {
+	var created model.Task
+	err := c.doJSON("POST", fmt.Sprintf("/v3/apps/%s/tasks", guid), task, &created)
+	if err != nil {
+		return model.Task{}, fmt.Errorf("Error running task %v", err)
+	}
+	return created, nil
+}
+
+// GetTask - fetches a task
+func (c *CFAPI) GetTask(guid string) (model.Task, error) {
+	var task model.Task
+	err := c.doJSON("GET", fmt.Sprintf("/v3/tasks/%s", guid), nil, &task)
+	if err != nil {
+		return model.Task{}, fmt.Errorf("Error requesting task %v", err)
+	}
+	return task, nil
+}
+
+// CancelTask - cancels a task that has not yet finished
+func (c *CFAPI) CancelTask(guid string) error {
+	err := c.doJSON("POST", fmt.Sprintf("/v3/tasks/%s/actions/cancel", guid), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Error cancelling task %v", err)
+	}
+	return nil
+}
+
+// ListPolicies - fetches the network policies an app is the source or destination of from the policy server
+func (c *CFAPI) ListPolicies(guid string) ([]model.Policy, error) {
+	var policies model.Policies
+	err := c.doJSON("GET", "/networking/v1/external/policies?id="+url.QueryEscape(guid), nil, &policies)
+	if err != nil {
+		return nil, fmt.Errorf("Error requesting network policies %v", err)
+	}
+	return policies.Policies, nil
+}
+
+// CreatePolicies - creates network policies, policies that already exist are left as they are
+func (c *CFAPI) CreatePolicies(policies []model.Policy) error {
+	err := c.doJSON("POST", "/networking/v1/external/policies", model.Policies{Policies: policies}, nil)
+	if err != nil {
+		return fmt.Errorf("Error creating network policies %v", err)
+	}
+	return nil
+}
+
+// DeletePolicies - deletes network policies
+func (c *CFAPI) DeletePolicies(policies []model.Policy) error {
+	err := c.doJSON("POST", "/networking/v1/external/policies/delete", model.Policies{Policies: policies}, nil)
+	if err != nil {
+		return fmt.Errorf("Error deleting network policies %v", err)
+	}
+	return nil
+}
+
+// ListRouteMappings - fetches the routes mapped to an app
+func (c *CFAPI) ListRouteMappings(guid string) ([]model.RouteMapping, error) {
+	var mappings struct {
+		Resources []struct {
+			Metadata struct {
+				GUID string `json:"guid"`
+			} `json:"metadata"`
+			Entity model.RouteMapping `json:"entity"`
+		} `json:"resources"`
+	}
+
+	err := c.doJSON("GET", fmt.Sprintf("/v2/apps/%s/route_mappings?results-per-page=100", guid), nil, &mappings)
+	if err != nil {
+		return nil, fmt.Errorf("Error requesting route mappings %v", err)
+	}
+	var routeMappings []model.RouteMapping
+	for _, resource := range mappings.Resources {
+		mapping := resource.Entity
+		mapping.GUID = resource.Metadata.GUID
+		routeMappings = append(routeMappings, mapping)
+	}
+	return routeMappings, nil
+}
+
+// MapRoute - maps a route to an app
+func (c *CFAPI) MapRoute(mapping model.RouteMapping) error {
+	err := c.doJSON("POST", "/v2/route_mappings", mapping, nil)
+	if err != nil {
+		return fmt.Errorf("Error mapping route %v", err)
+	}
+	return nil
+}
+
+// UnmapRoute - removes a route mapping
+func (c *CFAPI) UnmapRoute(guid string) error {
+	err := c.doJSON("DELETE", fmt.Sprintf("/v2/route_mappings/%s", guid), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Error unmapping route %v", err)
+	}
+	return nil
+}
+
+// BindSecGroup - binds a security group to a space as a running security group
+func (c *CFAPI) BindSecGroup(guid string, spaceGUID string) error {
+	err := c.doJSON("PUT", fmt.Sprintf("/v2/security_groups/%s/spaces/%s", guid, spaceGUID), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Error binding security group %v", err)
+	}
+	return nil
+}
+
+// UnbindSecGroup - unbinds a running security group from a space
+func (c *CFAPI) UnbindSecGroup(guid string, spaceGUID string) error {
+	err := c.doJSON("DELETE", fmt.Sprintf("/v2/security_groups/%s/spaces/%s", guid, spaceGUID), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Error unbinding security group %v", err)
+	}
+	return nil
+}
+
+func (c *CFAPI) listEvents(types string, guid string, since time.Time) ([]model.AppEvent, error) {
+	var events []model.AppEvent
+
+	query := eventsQuery(types, guid, since)
+	query.Set("results-per-page", "100")
+	path := "/v2/events?" + query.Encode()
+	for path != "" {
+		var page struct {
+			NextURL   string `json:"next_url"`
+			Resources []struct {
+				Entity model.AppEvent `json:"entity"`
+			} `json:"resources"`
+		}
+		err := c.doJSON("GET", path, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range page.Resources {
+			events = append(events, resource.Entity)
+		}
+		path = page.NextURL
+	}
+	return events, nil
+}
+
+func eventsQuery(types string, guid string, since time.Time) url.Values {
+	query := url.Values{}
+	query.Add("q", "type IN "+types)
+	query.Add("q", "actee:"+guid)
+	query.Add("q", "timestamp>="+since.UTC().Format(time.RFC3339))
+	return query
+}
+
+func (c *CFAPI) doJSON(method string, path string, in interface{}, out interface{}) error {
+	var requestBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.Config.ApiAddress+path, requestBody)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Config.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, body)
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
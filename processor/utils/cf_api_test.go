@@ -0,0 +1,728 @@
+package utils_test
+
+import (
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+)
+
+// newFakeCC - starts a fake Cloud Controller serving the info and token endpoints go-cfclient needs plus the given handlers
+func newFakeCC(handlers map[string]http.HandlerFunc) (*httptest.Server, *utils.CFAPI) {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/v2/info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"authorization_endpoint": "%s", "token_endpoint": "%s"}`, server.URL, server.URL)
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "token", "token_type": "bearer", "expires_in": 3600}`)
+	})
+	for path, handler := range handlers {
+		mux.HandleFunc(path, handler)
+	}
+	server = httptest.NewServer(mux)
+
+	config := &cfclient.Config{ApiAddress: server.URL, Username: "user", Password: "password"}
+	client, err := cfclient.NewClient(config)
+	Expect(err).To(BeNil())
+	return server, &utils.CFAPI{Client: client, Config: config}
+}
+
+var _ = Describe("CFAPI", func() {
+	Describe("#GetAppStats", func() {
+		Context("When the stats can be fetched", func() {
+			It("returns the stats of every instance", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1/stats": func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, `{"0": {"state": "RUNNING", "stats": {"host": "10.0.0.1", "port": 61000, "uptime": 300, "usage": {"cpu": 0.25, "mem": 1024, "disk": 2048}}}}`)
+					},
+				})
+				defer server.Close()
+
+				appStats, err := cfAPI.GetAppStats("app-1")
+				Expect(err).To(BeNil())
+				Expect(appStats).To(Equal(map[string]model.AppStats{
+					"0": {State: "RUNNING", Stats: model.InstanceStats{Host: "10.0.0.1", Port: 61000, Uptime: 300, Usage: model.InstanceUsage{CPU: 0.25, Mem: 1024, Disk: 2048}}},
+				}))
+			})
+		})
+
+		Context("When the Cloud Controller returns an error", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1/stats": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusBadRequest)
+						fmt.Fprint(w, `{"description": "app is stopped"}`)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.GetAppStats("app-1")
+				Expect(err).To(MatchError(ContainSubstring("returned 400")))
+			})
+		})
+	})
+
+	Describe("#CountAppCrashes", func() {
+		Context("When the crash events can be fetched", func() {
+			It("returns the number of crash events since the time across pages", func() {
+				var queries []url.Values
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/events": func(w http.ResponseWriter, r *http.Request) {
+						queries = append(queries, r.URL.Query())
+						if r.URL.Query().Get("page") == "2" {
+							fmt.Fprint(w, `{"total_results": 3, "total_pages": 2, "next_url": null, "resources": [
+								{"entity": {"type": "app.crash", "timestamp": "2016-02-26T13:31:02Z", "metadata": {"instance": "instance-0", "index": 0}}}]}`)
+							return
+						}
+						fmt.Fprint(w, `{"total_results": 3, "total_pages": 2, "next_url": "/v2/events?page=2", "resources": [
+							{"entity": {"type": "audit.app.process.crash", "timestamp": "2016-02-26T13:30:01Z", "metadata": {"instance": "instance-0", "index": 0}}},
+							{"entity": {"type": "audit.app.process.crash", "timestamp": "2016-02-26T13:30:01Z", "metadata": {"instance": "instance-1", "index": 1}}}]}`)
+					},
+				})
+				defer server.Close()
+
+				crashes, err := cfAPI.CountAppCrashes("app-1", time.Date(2016, 2, 26, 13, 29, 44, 0, time.UTC))
+				Expect(err).To(BeNil())
+				Expect(crashes).To(Equal(3))
+				Expect(queries).To(HaveLen(2))
+				Expect(queries[0]["q"]).To(Equal([]string{"type IN app.crash,audit.app.process.crash", "actee:app-1", "timestamp>=2016-02-26T13:29:44Z"}))
+			})
+
+			It("counts each crash once when both crash event types are recorded for it", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/events": func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, `{"total_results": 4, "total_pages": 1, "resources": [
+							{"entity": {"type": "app.crash", "timestamp": "2016-02-26T13:30:01Z", "metadata": {"instance": "instance-0", "index": 0}}},
+							{"entity": {"type": "audit.app.process.crash", "timestamp": "2016-02-26T13:30:01Z", "metadata": {"instance": "instance-0", "index": 0}}},
+							{"entity": {"type": "app.crash", "timestamp": "2016-02-26T13:31:02Z", "metadata": {"instance": "instance-0", "index": 0}}},
+							{"entity": {"type": "audit.app.process.crash", "timestamp": "2016-02-26T13:31:02Z", "metadata": {"instance": "instance-0", "index": 0}}}]}`)
+					},
+				})
+				defer server.Close()
+
+				crashes, err := cfAPI.CountAppCrashes("app-1", time.Now())
+				Expect(err).To(BeNil())
+				Expect(crashes).To(Equal(2))
+			})
+		})
+
+		Context("When the Cloud Controller returns an error", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/events": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusForbidden)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.CountAppCrashes("app-1", time.Now())
+				Expect(err).To(MatchError(ContainSubstring("returned 403")))
+			})
+		})
+	})
+
+	Describe("#CountAppDeploys", func() {
+		Context("When the deploy events can be fetched", func() {
+			It("returns the number of update and restage events since the time not made by the processor", func() {
+				var queries []url.Values
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/events": func(w http.ResponseWriter, r *http.Request) {
+						queries = append(queries, r.URL.Query())
+						if r.URL.Query().Get("page") == "2" {
+							fmt.Fprint(w, `{"total_results": 3, "total_pages": 2, "next_url": null, "resources": [
+								{"entity": {"type": "audit.app.update", "actor_name": "developer"}}]}`)
+							return
+						}
+						fmt.Fprint(w, `{"total_results": 3, "total_pages": 2, "next_url": "/v2/events?page=2", "resources": [
+							{"entity": {"type": "audit.app.restage", "actor_name": "developer"}},
+							{"entity": {"type": "audit.app.restage", "actor_name": "user"}}]}`)
+					},
+				})
+				defer server.Close()
+
+				deploys, err := cfAPI.CountAppDeploys("app-1", time.Date(2016, 2, 26, 13, 29, 44, 0, time.UTC))
+				Expect(err).To(BeNil())
+				Expect(deploys).To(Equal(2))
+				Expect(queries).To(HaveLen(2))
+				Expect(queries[0]["q"]).To(Equal([]string{"type IN audit.app.update,audit.app.restage", "actee:app-1", "timestamp>=2016-02-26T13:29:44Z"}))
+			})
+		})
+
+		Context("When the Cloud Controller returns an error", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/events": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusForbidden)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.CountAppDeploys("app-1", time.Now())
+				Expect(err).To(MatchError(ContainSubstring("Error requesting app deploy events")))
+			})
+		})
+	})
+
+	Describe("#GetAppLocation", func() {
+		Context("When the app and its space can be fetched", func() {
+			It("returns the space and org of the app", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1": func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, `{"metadata": {"guid": "app-1"}, "entity": {"name": "app", "space_guid": "space-1"}}`)
+					},
+					"/v2/spaces/space-1": func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, `{"metadata": {"guid": "space-1"}, "entity": {"name": "dev", "organization_guid": "org-1"}}`)
+					},
+				})
+				defer server.Close()
+
+				location, err := cfAPI.GetAppLocation("app-1")
+				Expect(err).To(BeNil())
+				Expect(location).To(Equal(model.AppLocation{SpaceID: "space-1", OrgID: "org-1"}))
+			})
+		})
+
+		Context("When the app cannot be fetched", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusNotFound)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.GetAppLocation("app-1")
+				Expect(err).To(MatchError(ContainSubstring("Error requesting app")))
+			})
+		})
+
+		Context("When the space cannot be fetched", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1": func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, `{"entity": {"space_guid": "space-1"}}`)
+					},
+					"/v2/spaces/space-1": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusForbidden)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.GetAppLocation("app-1")
+				Expect(err).To(MatchError(ContainSubstring("Error requesting space")))
+			})
+		})
+	})
+
+	Describe("#CountSpaceApps", func() {
+		Context("When the apps of the space can be fetched", func() {
+			It("returns the number of apps in the space", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/spaces/space-1/apps": func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, `{"total_results": 3, "total_pages": 3, "resources": [{"metadata": {"guid": "app-1"}}]}`)
+					},
+				})
+				defer server.Close()
+
+				apps, err := cfAPI.CountSpaceApps("space-1")
+				Expect(err).To(BeNil())
+				Expect(apps).To(Equal(3))
+			})
+		})
+
+		Context("When the Cloud Controller returns an error", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/spaces/space-1/apps": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusNotFound)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.CountSpaceApps("space-1")
+				Expect(err).To(MatchError(ContainSubstring("Error requesting space apps")))
+			})
+		})
+	})
+
+	Describe("#GetAppState", func() {
+		Context("When the app can be fetched", func() {
+			It("returns the state, instance count and package state of the app", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1": func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, `{"metadata": {"guid": "app-1"}, "entity": {"name": "app", "state": "STARTED", "instances": 3, "memory": 256, "disk_quota": 1024, `+
+							`"package_state": "STAGED", "package_updated_at": "2016-02-26T13:29:44Z"}}`)
+					},
+				})
+				defer server.Close()
+
+				appState, err := cfAPI.GetAppState("app-1")
+				Expect(err).To(BeNil())
+				Expect(appState).To(Equal(model.AppState{State: "STARTED", Instances: 3, Memory: 256, DiskQuota: 1024, PackageState: "STAGED",
+					PackageUpdatedAt: "2016-02-26T13:29:44Z"}))
+			})
+		})
+
+		Context("When the app cannot be fetched", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusNotFound)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.GetAppState("app-1")
+				Expect(err).To(MatchError(ContainSubstring("returned 404")))
+			})
+		})
+	})
+
+	Describe("#UpdateAppState", func() {
+		It("puts the state to the app", func() {
+			var (
+				method string
+				body   string
+			)
+			server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+				"/v2/apps/app-1": func(w http.ResponseWriter, r *http.Request) {
+					method = r.Method
+					data, _ := ioutil.ReadAll(r.Body)
+					body = string(data)
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprint(w, `{"entity": {"state": "STOPPED"}}`)
+				},
+			})
+			defer server.Close()
+
+			Expect(cfAPI.UpdateAppState("app-1", "STOPPED")).To(BeNil())
+			Expect(method).To(Equal("PUT"))
+			Expect(body).To(MatchJSON(`{"state": "STOPPED"}`))
+		})
+
+		Context("When the Cloud Controller returns an error", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusForbidden)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.UpdateAppState("app-1", "STARTED")).To(MatchError(ContainSubstring("returned 403")))
+			})
+		})
+	})
+
+	Describe("#ScaleApp", func() {
+		It("puts the instance count to the app", func() {
+			var body string
+			server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+				"/v2/apps/app-1": func(w http.ResponseWriter, r *http.Request) {
+					data, _ := ioutil.ReadAll(r.Body)
+					body = string(data)
+					w.WriteHeader(http.StatusCreated)
+				},
+			})
+			defer server.Close()
+
+			Expect(cfAPI.ScaleApp("app-1", 0)).To(BeNil())
+			Expect(body).To(MatchJSON(`{"instances": 0}`))
+		})
+	})
+
+	Describe("#RestageApp", func() {
+		It("posts to the restage endpoint of the app", func() {
+			var method string
+			server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+				"/v2/apps/app-1/restage": func(w http.ResponseWriter, r *http.Request) {
+					method = r.Method
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprint(w, `{"entity": {"package_state": "PENDING"}}`)
+				},
+			})
+			defer server.Close()
+
+			Expect(cfAPI.RestageApp("app-1")).To(BeNil())
+			Expect(method).To(Equal("POST"))
+		})
+
+		Context("When the Cloud Controller returns an error", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1/restage": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusBadRequest)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.RestageApp("app-1")).To(MatchError(ContainSubstring("Error restaging app")))
+			})
+		})
+	})
+
+	Describe("#RunTask", func() {
+		It("posts the task to the app and returns it as created", func() {
+			var (
+				method string
+				body   string
+			)
+			server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+				"/v3/apps/app-1/tasks": func(w http.ResponseWriter, r *http.Request) {
+					method = r.Method
+					data, _ := ioutil.ReadAll(r.Body)
+					body = string(data)
+					w.WriteHeader(http.StatusAccepted)
+					fmt.Fprint(w, `{"guid": "task-1", "name": "chaos-galago-cpu-burn", "command": "sleep 1", "state": "RUNNING", "memory_in_mb": 256, "disk_in_mb": 1024}`)
+				},
+			})
+			defer server.Close()
+
+			task, err := cfAPI.RunTask("app-1", model.Task{Name: "chaos-galago-cpu-burn", Command: "sleep 1", MemoryInMB: 256})
+			Expect(err).To(BeNil())
+			Expect(method).To(Equal("POST"))
+			Expect(body).To(MatchJSON(`{"name": "chaos-galago-cpu-burn", "command": "sleep 1", "memory_in_mb": 256}`))
+			Expect(task).To(Equal(model.Task{GUID: "task-1", Name: "chaos-galago-cpu-burn", Command: "sleep 1", State: "RUNNING", MemoryInMB: 256, DiskInMB: 1024}))
+		})
+
+		Context("When the Cloud Controller returns an error", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v3/apps/app-1/tasks": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusUnprocessableEntity)
+						fmt.Fprint(w, `{"errors": [{"detail": "Task must have a droplet"}]}`)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.RunTask("app-1", model.Task{Command: "sleep 1"})
+				Expect(err).To(MatchError(ContainSubstring("Error running task")))
+			})
+		})
+	})
+
+	Describe("#GetTask", func() {
+		It("returns the task", func() {
+			server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+				"/v3/tasks/task-1": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"guid": "task-1", "state": "SUCCEEDED"}`)
+				},
+			})
+			defer server.Close()
+
+			task, err := cfAPI.GetTask("task-1")
+			Expect(err).To(BeNil())
+			Expect(task.State).To(Equal("SUCCEEDED"))
+		})
+
+		Context("When the task cannot be fetched", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v3/tasks/task-1": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusNotFound)
+					},
+				})
+				defer server.Close()
+
+				_, err := cfAPI.GetTask("task-1")
+				Expect(err).To(MatchError(ContainSubstring("returned 404")))
+			})
+		})
+	})
+
+	Describe("#CancelTask", func() {
+		It("posts to the cancel action of the task", func() {
+			var method string
+			server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+				"/v3/tasks/task-1/actions/cancel": func(w http.ResponseWriter, r *http.Request) {
+					method = r.Method
+					w.WriteHeader(http.StatusAccepted)
+					fmt.Fprint(w, `{"guid": "task-1", "state": "CANCELING"}`)
+				},
+			})
+			defer server.Close()
+
+			Expect(cfAPI.CancelTask("task-1")).To(BeNil())
+			Expect(method).To(Equal("POST"))
+		})
+
+		Context("When the Cloud Controller returns an error", func() {
+			It("returns an error", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v3/tasks/task-1/actions/cancel": func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusUnprocessableEntity)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.CancelTask("task-1")).To(MatchError(ContainSubstring("Error cancelling task")))
+			})
+		})
+	})
+
+	Describe("Network policies", func() {
+		var policies = []model.Policy{{
+			Source:      model.PolicySource{ID: "app-1"},
+			Destination: model.PolicyDestination{ID: "app-2", Protocol: "tcp", Ports: model.PolicyPorts{Start: 8080, End: 8080}},
+		}}
+		var policiesJSON = `{"policies": [{"source": {"id": "app-1"}, "destination": {"id": "app-2", "protocol": "tcp", "ports": {"start": 8080, "end": 8080}}}]}`
+
+		Describe("#ListPolicies", func() {
+			It("returns the policies of the app", func() {
+				var id string
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/networking/v1/external/policies": func(w http.ResponseWriter, r *http.Request) {
+						id = r.URL.Query().Get("id")
+						fmt.Fprint(w, `{"total_policies": 1, "policies": [{"source": {"id": "app-1"}, "destination": {"id": "app-2", "protocol": "tcp", "ports": {"start": 8080, "end": 8080}}}]}`)
+					},
+				})
+				defer server.Close()
+
+				listed, err := cfAPI.ListPolicies("app-1")
+				Expect(err).To(BeNil())
+				Expect(id).To(Equal("app-1"))
+				Expect(listed).To(Equal(policies))
+			})
+
+			Context("When the policy server returns an error", func() {
+				It("returns an error", func() {
+					server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+						"/networking/v1/external/policies": func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusForbidden)
+						},
+					})
+					defer server.Close()
+
+					_, err := cfAPI.ListPolicies("app-1")
+					Expect(err).To(MatchError(ContainSubstring("Error requesting network policies")))
+				})
+			})
+		})
+
+		Describe("#CreatePolicies", func() {
+			It("posts the policies", func() {
+				var (
+					method string
+					body   string
+				)
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/networking/v1/external/policies": func(w http.ResponseWriter, r *http.Request) {
+						method = r.Method
+						data, _ := ioutil.ReadAll(r.Body)
+						body = string(data)
+						fmt.Fprint(w, `{}`)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.CreatePolicies(policies)).To(BeNil())
+				Expect(method).To(Equal("POST"))
+				Expect(body).To(MatchJSON(policiesJSON))
+			})
+
+			Context("When the policy server returns an error", func() {
+				It("returns an error", func() {
+					server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+						"/networking/v1/external/policies": func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusBadRequest)
+						},
+					})
+					defer server.Close()
+
+					Expect(cfAPI.CreatePolicies(policies)).To(MatchError(ContainSubstring("Error creating network policies")))
+				})
+			})
+		})
+
+		Describe("#DeletePolicies", func() {
+			It("posts the policies to the delete endpoint", func() {
+				var body string
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/networking/v1/external/policies/delete": func(w http.ResponseWriter, r *http.Request) {
+						data, _ := ioutil.ReadAll(r.Body)
+						body = string(data)
+						fmt.Fprint(w, `{}`)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.DeletePolicies(policies)).To(BeNil())
+				Expect(body).To(MatchJSON(policiesJSON))
+			})
+
+			Context("When the policy server returns an error", func() {
+				It("returns an error", func() {
+					server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+						"/networking/v1/external/policies/delete": func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusBadRequest)
+						},
+					})
+					defer server.Close()
+
+					Expect(cfAPI.DeletePolicies(policies)).To(MatchError(ContainSubstring("Error deleting network policies")))
+				})
+			})
+		})
+	})
+
+	Describe("Route mappings", func() {
+		Describe("#ListRouteMappings", func() {
+			It("returns the route mappings of the app", func() {
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/apps/app-1/route_mappings": func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, `{"total_results": 1, "resources": [{"metadata": {"guid": "mapping-1"}, "entity": {"app_guid": "app-1", "route_guid": "route-1", "app_port": 8080}}]}`)
+					},
+				})
+				defer server.Close()
+
+				mappings, err := cfAPI.ListRouteMappings("app-1")
+				Expect(err).To(BeNil())
+				Expect(mappings).To(Equal([]model.RouteMapping{{GUID: "mapping-1", AppID: "app-1", RouteID: "route-1", AppPort: 8080}}))
+			})
+
+			Context("When Cloud Controller returns an error", func() {
+				It("returns an error", func() {
+					server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+						"/v2/apps/app-1/route_mappings": func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusNotFound)
+						},
+					})
+					defer server.Close()
+
+					_, err := cfAPI.ListRouteMappings("app-1")
+					Expect(err).To(MatchError(ContainSubstring("Error requesting route mappings")))
+				})
+			})
+		})
+
+		Describe("#MapRoute", func() {
+			It("creates the route mapping", func() {
+				var body string
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/route_mappings": func(w http.ResponseWriter, r *http.Request) {
+						data, _ := ioutil.ReadAll(r.Body)
+						body = string(data)
+						w.WriteHeader(http.StatusCreated)
+						fmt.Fprint(w, `{"metadata": {"guid": "mapping-2"}}`)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.MapRoute(model.RouteMapping{GUID: "mapping-1", AppID: "app-1", RouteID: "route-1", AppPort: 8080})).To(BeNil())
+				Expect(body).To(MatchJSON(`{"app_guid": "app-1", "route_guid": "route-1", "app_port": 8080}`))
+			})
+
+			Context("When Cloud Controller returns an error", func() {
+				It("returns an error", func() {
+					server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+						"/v2/route_mappings": func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusBadRequest)
+						},
+					})
+					defer server.Close()
+
+					Expect(cfAPI.MapRoute(model.RouteMapping{AppID: "app-1", RouteID: "route-1"})).To(MatchError(ContainSubstring("Error mapping route")))
+				})
+			})
+		})
+
+		Describe("#UnmapRoute", func() {
+			It("deletes the route mapping", func() {
+				var method string
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/route_mappings/mapping-1": func(w http.ResponseWriter, r *http.Request) {
+						method = r.Method
+						w.WriteHeader(http.StatusNoContent)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.UnmapRoute("mapping-1")).To(BeNil())
+				Expect(method).To(Equal("DELETE"))
+			})
+
+			Context("When Cloud Controller returns an error", func() {
+				It("returns an error", func() {
+					server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+						"/v2/route_mappings/mapping-1": func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusNotFound)
+						},
+					})
+					defer server.Close()
+
+					Expect(cfAPI.UnmapRoute("mapping-1")).To(MatchError(ContainSubstring("Error unmapping route")))
+				})
+			})
+		})
+	})
+
+	Describe("Security groups", func() {
+		Describe("#BindSecGroup", func() {
+			It("binds the security group to the space", func() {
+				var method string
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/security_groups/group-1/spaces/space-1": func(w http.ResponseWriter, r *http.Request) {
+						method = r.Method
+						w.WriteHeader(http.StatusCreated)
+						fmt.Fprint(w, `{"metadata": {"guid": "group-1"}}`)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.BindSecGroup("group-1", "space-1")).To(BeNil())
+				Expect(method).To(Equal("PUT"))
+			})
+
+			Context("When Cloud Controller returns an error", func() {
+				It("returns an error", func() {
+					server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+						"/v2/security_groups/group-1/spaces/space-1": func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusForbidden)
+						},
+					})
+					defer server.Close()
+
+					Expect(cfAPI.BindSecGroup("group-1", "space-1")).To(MatchError(ContainSubstring("Error binding security group")))
+				})
+			})
+		})
+
+		Describe("#UnbindSecGroup", func() {
+			It("unbinds the security group from the space", func() {
+				var method string
+				server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+					"/v2/security_groups/group-1/spaces/space-1": func(w http.ResponseWriter, r *http.Request) {
+						method = r.Method
+						w.WriteHeader(http.StatusNoContent)
+					},
+				})
+				defer server.Close()
+
+				Expect(cfAPI.UnbindSecGroup("group-1", "space-1")).To(BeNil())
+				Expect(method).To(Equal("DELETE"))
+			})
+
+			Context("When Cloud Controller returns an error", func() {
+				It("returns an error", func() {
+					server, cfAPI := newFakeCC(map[string]http.HandlerFunc{
+						"/v2/security_groups/group-1/spaces/space-1": func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusForbidden)
+						},
+					})
+					defer server.Close()
+
+					Expect(cfAPI.UnbindSecGroup("group-1", "space-1")).To(MatchError(ContainSubstring("Error unbinding security group")))
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"time"
+)
+
+// packageStaging - the package state of an app while it is being staged
+const packageStaging = "PENDING"
+
+// DeployingReason - returns why chaos must wait for the deploy of an app, while it is staging or until the cooldown has
+// passed since its package was updated, blank if it need not
+func DeployingReason(appState model.AppState, cooldown time.Duration, now time.Time) string {
+	if appState.PackageState == packageStaging {
+		return "app is staging"
+	}
+	updatedAt, err := time.Parse(time.RFC3339, appState.PackageUpdatedAt)
+	if err == nil && now.Sub(updatedAt) < cooldown {
+		return fmt.Sprintf("app was deployed at %s, within the deploy cooldown of %s", updatedAt.UTC().Format(eventTimeLayout), cooldown)
+	}
+	return ""
+}
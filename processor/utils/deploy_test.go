@@ -0,0 +1,39 @@
+package utils_test
+
+import (
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("#DeployingReason", func() {
+	var now = time.Date(2016, 2, 26, 13, 30, 0, 0, time.UTC)
+
+	Context("When the app is staging", func() {
+		It("waits for the staging", func() {
+			Expect(utils.DeployingReason(model.AppState{PackageState: "PENDING"}, 10*time.Minute, now)).To(Equal("app is staging"))
+		})
+	})
+
+	Context("When the package was updated within the cooldown", func() {
+		It("waits for the cooldown", func() {
+			appState := model.AppState{PackageState: "STAGED", PackageUpdatedAt: "2016-02-26T13:25:00Z"}
+			Expect(utils.DeployingReason(appState, 10*time.Minute, now)).To(Equal("app was deployed at 2016-02-26T13:25:00Z, within the deploy cooldown of 10m0s"))
+		})
+	})
+
+	Context("When the cooldown has passed", func() {
+		It("does not wait", func() {
+			appState := model.AppState{PackageState: "STAGED", PackageUpdatedAt: "2016-02-26T13:15:00Z"}
+			Expect(utils.DeployingReason(appState, 10*time.Minute, now)).To(Equal(""))
+		})
+	})
+
+	Context("When the package update time is not known", func() {
+		It("does not wait", func() {
+			Expect(utils.DeployingReason(model.AppState{PackageState: "STAGED"}, 10*time.Minute, now)).To(Equal(""))
+		})
+	})
+})
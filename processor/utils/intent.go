@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	"strings"
+)
+
+var (
+	intentColumns    = strings.Join([]string{"id", "appID", "instanceIndex", "owner", "state", "createdAt", "updatedAt", "action", "orgID", "spaceID"}, ", ")
+	intentMigrations = []sharedUtils.Column{
+		{Name: "action", Definition: "varchar(255)"},
+		{Name: "eventID", Definition: "bigint"},
+		{Name: "orgID", Definition: "varchar(255)"},
+		{Name: "spaceID", Definition: "varchar(255)"},
+	}
+)
+
+// SetupIntentDB - creates the chaos_intents DB if it does not exist
+func SetupIntentDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS chaos_intents
+	(
+		id varchar(255),
+		appID varchar(255),
+		instanceIndex varchar(255),
+		owner varchar(255),
+		state varchar(255),
+		createdAt varchar(255),
+		updatedAt varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return sharedUtils.AddColumns(db, "chaos_intents", intentMigrations)
+}
+
+// NewID - generates a random identifier
+func NewID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// RecordIntent - adds a row to chaos_intents database
+func RecordIntent(db *sql.DB, intent model.Intent) error {
+	_, err := db.Exec("INSERT INTO chaos_intents ("+intentColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		intent.ID, intent.AppID, intent.InstanceIndex, intent.Owner, intent.State, intent.CreatedAt, intent.UpdatedAt, intent.Action, intent.OrgID, intent.SpaceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateIntentState - writes the state of an intent to chaos_intents database
+func UpdateIntentState(db *sql.DB, intentID string, state string) error {
+	_, err := db.Exec("UPDATE chaos_intents SET state=?,updatedAt=? WHERE id=?", state, TimeNow(), intentID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RecordIntentUnknown - records an intent whose call timed out as unknown, along with the id of the event recorded for it
+func RecordIntentUnknown(db *sql.DB, intentID string, eventID int64) error {
+	_, err := db.Exec("UPDATE chaos_intents SET state=?,eventID=?,updatedAt=? WHERE id=?", model.IntentUnknown, eventID, TimeNow(), intentID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetUnresolvedIntents - loads the intents an owner recorded but never confirmed, and those it recorded as unknown whose
+// call never returned
+func GetUnresolvedIntents(db *sql.DB, owner string) ([]model.Intent, error) {
+	var intents []model.Intent
+
+	rows, err := db.Query("SELECT "+intentColumns+", eventID FROM chaos_intents WHERE owner=? AND state IN (?, ?)", owner, model.IntentPending, model.IntentUnknown)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			intent  model.Intent
+			action  sql.NullString
+			orgID   sql.NullString
+			spaceID sql.NullString
+			eventID sql.NullInt64
+		)
+		if err = rows.Scan(&intent.ID, &intent.AppID, &intent.InstanceIndex, &intent.Owner, &intent.State, &intent.CreatedAt, &intent.UpdatedAt, &action,
+			&orgID, &spaceID, &eventID); err != nil {
+			return nil, err
+		}
+		intent.Action = action.String
+		intent.OrgID = orgID.String
+		intent.SpaceID = spaceID.String
+		intent.EventID = eventID.Int64
+		if intent.Action == "" {
+			intent.Action = sharedModel.ActionKill
+		}
+		intents = append(intents, intent)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return intents, nil
+}
@@ -0,0 +1,130 @@
+package utils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Intents", func() {
+	var (
+		mock sqlmock.Sqlmock
+		db   *sql.DB
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupIntentDB", func() {
+		It("creates the chaos_intents table and adds any missing columns", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_intents.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_intents", "action").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectExec("ALTER TABLE chaos_intents ADD COLUMN action .*").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_intents", "eventID").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_intents", "orgID").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectExec("ALTER TABLE chaos_intents ADD COLUMN orgID .*").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_intents", "spaceID").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectExec("ALTER TABLE chaos_intents ADD COLUMN spaceID .*").WillReturnResult(sqlmock.NewResult(0, 0))
+			Expect(utils.SetupIntentDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("when the table cannot be created", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_intents.*").WillReturnError(fmt.Errorf("An error has occurred: %s", "Database Create Error"))
+				Expect(utils.SetupIntentDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#NewID", func() {
+		It("returns a unique 32 character identifier", func() {
+			id := utils.NewID()
+			Expect(id).To(MatchRegexp("^[0-9a-f]{32}$"))
+			Expect(utils.NewID()).ToNot(Equal(id))
+		})
+	})
+
+	Describe("#RecordIntent", func() {
+		It("inserts the intent", func() {
+			intent := model.Intent{ID: "1", AppID: "app-1", InstanceIndex: "0", Owner: "0", State: model.IntentPending, CreatedAt: "2014-11-12T10:31:20Z", UpdatedAt: "2014-11-12T10:31:20Z", Action: "kill", OrgID: "org-1", SpaceID: "space-1"}
+			mock.ExpectExec("INSERT INTO chaos_intents.*").WithArgs("1", "app-1", "0", "0", "pending", "2014-11-12T10:31:20Z", "2014-11-12T10:31:20Z", "kill", "org-1", "space-1").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.RecordIntent(db, intent)).To(BeNil())
+		})
+
+		Context("when the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO chaos_intents.*").WillReturnError(fmt.Errorf("An error has occurred: %s", "INSERT error"))
+				Expect(utils.RecordIntent(db, model.Intent{})).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#UpdateIntentState", func() {
+		It("updates the intent", func() {
+			mock.ExpectExec("UPDATE chaos_intents.*").WithArgs("completed", sqlmock.AnyArg(), "1").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.UpdateIntentState(db, "1", model.IntentCompleted)).To(BeNil())
+		})
+
+		Context("when the update fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnError(fmt.Errorf("An error has occurred: %s", "UPDATE error"))
+				Expect(utils.UpdateIntentState(db, "1", model.IntentCompleted)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#RecordIntentUnknown", func() {
+		It("records the intent as unknown with the id of its event", func() {
+			mock.ExpectExec("UPDATE chaos_intents SET state=\\?,eventID=\\?,updatedAt=\\? WHERE id=\\?").WithArgs("unknown", 7, sqlmock.AnyArg(), "1").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.RecordIntentUnknown(db, "1", 7)).To(BeNil())
+		})
+
+		Context("when the update fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("UPDATE chaos_intents.*").WillReturnError(fmt.Errorf("An error has occurred: %s", "UPDATE error"))
+				Expect(utils.RecordIntentUnknown(db, "1", 7)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetUnresolvedIntents", func() {
+		It("returns the pending and unknown intents for the owner", func() {
+			intentRows := sqlmock.NewRows([]string{"id", "appID", "instanceIndex", "owner", "state", "createdAt", "updatedAt", "action", "orgID", "spaceID", "eventID"}).
+				AddRow("1", "app-1", "0", "0", "pending", "2014-11-12T10:31:20Z", "2014-11-12T10:31:20Z", "restart", "org-1", "space-1", nil).
+				AddRow("2", "app-2", "1", "0", "pending", "2014-11-12T10:31:20Z", "2014-11-12T10:31:20Z", nil, nil, nil, nil).
+				AddRow("3", "app-3", "0,1", "0", "unknown", "2014-11-12T10:31:20Z", "2014-11-12T10:31:50Z", "restage", nil, nil, 7)
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_intents WHERE (.+)$").WithArgs("0", "pending", "unknown").WillReturnRows(intentRows)
+			intents, err := utils.GetUnresolvedIntents(db, "0")
+			Expect(err).To(BeNil())
+			Expect(intents).To(Equal([]model.Intent{
+				{ID: "1", AppID: "app-1", InstanceIndex: "0", Owner: "0", State: "pending", CreatedAt: "2014-11-12T10:31:20Z", UpdatedAt: "2014-11-12T10:31:20Z", Action: "restart", OrgID: "org-1", SpaceID: "space-1"},
+				{ID: "2", AppID: "app-2", InstanceIndex: "1", Owner: "0", State: "pending", CreatedAt: "2014-11-12T10:31:20Z", UpdatedAt: "2014-11-12T10:31:20Z", Action: "kill"},
+				{ID: "3", AppID: "app-3", InstanceIndex: "0,1", Owner: "0", State: "unknown", CreatedAt: "2014-11-12T10:31:20Z", UpdatedAt: "2014-11-12T10:31:50Z", Action: "restage", EventID: 7},
+			}))
+		})
+
+		Context("when the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_intents WHERE (.+)$").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.GetUnresolvedIntents(db, "0")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"math/rand"
+	"sort"
+)
+
+// ChoosePeer - picks at random one of the apps the given app has network policies with, in either direction, returning
+// the peer and the policies between the two. Policies from the app to itself are never chosen.
+func ChoosePeer(rng *rand.Rand, appID string, policies []model.Policy) (string, []model.Policy, error) {
+	peers := make(map[string][]model.Policy)
+	for _, policy := range policies {
+		var peer string
+		switch appID {
+		case policy.Source.ID:
+			peer = policy.Destination.ID
+		case policy.Destination.ID:
+			peer = policy.Source.ID
+		default:
+			continue
+		}
+		if peer == appID {
+			continue
+		}
+		peers[peer] = append(peers[peer], policy)
+	}
+	if len(peers) == 0 {
+		return "", nil, fmt.Errorf("the app has no network policies with other apps")
+	}
+
+	var ids []string
+	for id := range peers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	peer := ids[rng.Intn(len(ids))]
+	return peer, peers[peer], nil
+}
+
+// SetupPolicySnapshotDB - creates the policy_snapshots DB if it does not exist
+func SetupPolicySnapshotDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS policy_snapshots
+	(
+		id varchar(255),
+		appID varchar(255),
+		owner varchar(255),
+		policies text,
+		createdAt varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SavePolicySnapshot - adds a row to policy_snapshots database
+func SavePolicySnapshot(db *sql.DB, snapshot model.PolicySnapshot) error {
+	policies, err := json.Marshal(snapshot.Policies)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("INSERT INTO policy_snapshots (id, appID, owner, policies, createdAt) VALUES (?, ?, ?, ?, ?)",
+		snapshot.ID, snapshot.AppID, snapshot.Owner, string(policies), snapshot.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeletePolicySnapshot - removes a row from policy_snapshots database once its policies are restored
+func DeletePolicySnapshot(db *sql.DB, snapshotID string) error {
+	_, err := db.Exec("DELETE FROM policy_snapshots WHERE id=?", snapshotID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetPolicySnapshots - loads the snapshots an owner saved and has not yet restored
+func GetPolicySnapshots(db *sql.DB, owner string) ([]model.PolicySnapshot, error) {
+	var snapshots []model.PolicySnapshot
+
+	rows, err := db.Query("SELECT id, appID, owner, policies, createdAt FROM policy_snapshots WHERE owner=?", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			snapshot model.PolicySnapshot
+			policies string
+		)
+		if err = rows.Scan(&snapshot.ID, &snapshot.AppID, &snapshot.Owner, &policies, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(policies), &snapshot.Policies); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,132 @@
+package utils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+func policy(source string, destination string, port int) model.Policy {
+	return model.Policy{
+		Source:      model.PolicySource{ID: source},
+		Destination: model.PolicyDestination{ID: destination, Protocol: "tcp", Ports: model.PolicyPorts{Start: port, End: port}},
+	}
+}
+
+var _ = Describe("#ChoosePeer", func() {
+	var policies []model.Policy
+
+	BeforeEach(func() {
+		policies = []model.Policy{
+			policy("app-1", "app-2", 8080),
+			policy("app-3", "app-1", 8080),
+			policy("app-1", "app-2", 9090),
+			policy("app-1", "app-1", 8080),
+			policy("app-4", "app-5", 8080),
+		}
+	})
+
+	It("returns every policy between the app and the chosen peer", func() {
+		peer, chosen, err := utils.ChoosePeer(utils.NewRand(42), "app-1", policies)
+		Expect(err).To(BeNil())
+		Expect([]string{"app-2", "app-3"}).To(ContainElement(peer))
+		if peer == "app-2" {
+			Expect(chosen).To(Equal([]model.Policy{policies[0], policies[2]}))
+		} else {
+			Expect(chosen).To(Equal([]model.Policy{policies[1]}))
+		}
+	})
+
+	It("chooses the same peer for the same seed", func() {
+		first, _, _ := utils.ChoosePeer(utils.NewRand(42), "app-1", policies)
+		second, _, _ := utils.ChoosePeer(utils.NewRand(42), "app-1", policies)
+		Expect(first).To(Equal(second))
+	})
+
+	Context("When the app only has policies with itself", func() {
+		It("returns an error", func() {
+			_, _, err := utils.ChoosePeer(utils.NewRand(42), "app-1", policies[3:])
+			Expect(err).To(MatchError("the app has no network policies with other apps"))
+		})
+	})
+})
+
+var _ = Describe("Policy snapshots", func() {
+	var (
+		mock     sqlmock.Sqlmock
+		db       *sql.DB
+		snapshot model.PolicySnapshot
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		snapshot = model.PolicySnapshot{ID: "1", AppID: "app-1", Owner: "0", Policies: []model.Policy{policy("app-1", "app-2", 8080)}, CreatedAt: "2014-11-12T10:31:20Z"}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupPolicySnapshotDB", func() {
+		It("creates the policy_snapshots table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS policy_snapshots.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupPolicySnapshotDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#SavePolicySnapshot", func() {
+		It("inserts the snapshot with its policies as JSON", func() {
+			mock.ExpectExec("INSERT INTO policy_snapshots .*").
+				WithArgs("1", "app-1", "0", `[{"source":{"id":"app-1"},"destination":{"id":"app-2","protocol":"tcp","ports":{"start":8080,"end":8080}}}]`, "2014-11-12T10:31:20Z").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SavePolicySnapshot(db, snapshot)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO policy_snapshots .*").WillReturnError(fmt.Errorf("An error has occurred: %s", "Database Insert Error"))
+				Expect(utils.SavePolicySnapshot(db, snapshot)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeletePolicySnapshot", func() {
+		It("deletes the snapshot", func() {
+			mock.ExpectExec("DELETE FROM policy_snapshots WHERE id=\\?").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(utils.DeletePolicySnapshot(db, "1")).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#GetPolicySnapshots", func() {
+		It("returns the snapshots of the owner", func() {
+			rows := sqlmock.NewRows([]string{"id", "appID", "owner", "policies", "createdAt"}).
+				AddRow("1", "app-1", "0", `[{"source":{"id":"app-1"},"destination":{"id":"app-2","protocol":"tcp","ports":{"start":8080,"end":8080}}}]`, "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("SELECT .* FROM policy_snapshots WHERE owner=\\?").WithArgs("0").WillReturnRows(rows)
+			snapshots, err := utils.GetPolicySnapshots(db, "0")
+			Expect(err).To(BeNil())
+			Expect(snapshots).To(Equal([]model.PolicySnapshot{snapshot}))
+		})
+
+		Context("When the policies cannot be read", func() {
+			It("returns an error", func() {
+				rows := sqlmock.NewRows([]string{"id", "appID", "owner", "policies", "createdAt"}).AddRow("1", "app-1", "0", "not json", "2014-11-12T10:31:20Z")
+				mock.ExpectQuery("SELECT .* FROM policy_snapshots WHERE owner=\\?").WithArgs("0").WillReturnRows(rows)
+				_, err := utils.GetPolicySnapshots(db, "0")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"time"
+)
+
+// ownedTables - the tables whose rows belong to the processor instance that saved them, with the filter limiting which
+// rows of an instance no longer seen are still worth claiming
+var ownedTables = []struct {
+	table  string
+	filter string
+	args   []interface{}
+}{
+	{"chaos_intents", " AND state IN (?, ?)", []interface{}{model.IntentPending, model.IntentUnknown}},
+	{"state_snapshots", "", nil},
+	{"policy_snapshots", "", nil},
+	{"mapping_snapshots", "", nil},
+	{"secgroup_snapshots", "", nil},
+	{"plugin_snapshots", "", nil},
+}
+
+// SetupOwnerDB - creates the processor_owners DB if it does not exist
+func SetupOwnerDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS processor_owners
+	(
+		owner varchar(255),
+		seenAt varchar(255),
+		PRIMARY KEY (owner)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RecordOwnerSeen - records that the processor instance owning rows was running at a time
+func RecordOwnerSeen(db *sql.DB, owner string, seenAt string) error {
+	_, err := db.Exec("INSERT INTO processor_owners (owner, seenAt) VALUES (?, ?) ON DUPLICATE KEY UPDATE seenAt=?", owner, seenAt, seenAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClaimOrphans - hands the unresolved intents and snapshots of every other owner not seen since a time to owner, returning
+// how many rows it claimed. Owners that never recorded being seen count as not seen, as they predate processor_owners.
+func ClaimOrphans(db *sql.DB, owner string, since time.Time) (int64, error) {
+	var claimed int64
+
+	for _, owned := range ownedTables {
+		query := fmt.Sprintf("UPDATE %s SET owner=? WHERE owner<>? AND owner NOT IN (SELECT owner FROM processor_owners WHERE seenAt>=?)%s", owned.table, owned.filter)
+		args := append([]interface{}{owner, owner, since.UTC().Format(eventTimeLayout)}, owned.args...)
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			return claimed, err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return claimed, err
+		}
+		claimed += rows
+	}
+	return claimed, nil
+}
@@ -0,0 +1,73 @@
+package utils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+	"time"
+)
+
+var _ = Describe("Owners", func() {
+	var (
+		mock  sqlmock.Sqlmock
+		db    *sql.DB
+		since = time.Date(2014, 11, 12, 10, 26, 20, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupOwnerDB", func() {
+		It("creates the processor_owners table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS processor_owners.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupOwnerDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#RecordOwnerSeen", func() {
+		It("inserts or updates when the owner was seen", func() {
+			mock.ExpectExec("INSERT INTO processor_owners \\(owner, seenAt\\) VALUES \\(\\?, \\?\\) ON DUPLICATE KEY UPDATE seenAt=\\?").
+				WithArgs("0", "2014-11-12T10:31:20Z", "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.RecordOwnerSeen(db, "0", "2014-11-12T10:31:20Z")).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#ClaimOrphans", func() {
+		It("claims the unresolved intents and the snapshots of owners not seen since the time", func() {
+			mock.ExpectExec("^UPDATE chaos_intents SET owner=\\? WHERE owner<>\\? AND owner NOT IN \\(SELECT owner FROM processor_owners WHERE seenAt>=\\?\\) AND state IN \\(\\?, \\?\\)$").
+				WithArgs("0", "0", "2014-11-12T10:26:20Z", "pending", "unknown").WillReturnResult(sqlmock.NewResult(0, 1))
+			for _, table := range []string{"state_snapshots", "policy_snapshots", "mapping_snapshots", "secgroup_snapshots", "plugin_snapshots"} {
+				mock.ExpectExec("^UPDATE "+table+" SET owner=\\? WHERE owner<>\\? AND owner NOT IN \\(SELECT owner FROM processor_owners WHERE seenAt>=\\?\\)$").
+					WithArgs("0", "0", "2014-11-12T10:26:20Z").WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			claimed, err := utils.ClaimOrphans(db, "0", since)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(Equal(int64(6)))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the rows cannot be claimed", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("^UPDATE chaos_intents").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.ClaimOrphans(db, "0", since)
+				Expect(err).To(MatchError("An error has occurred: DB error"))
+			})
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DiscoverPlugins - asks every executable in a directory to describe itself, returning the plugins by name. Files that are not
+// executable are ignored, no directory means no plugins and an executable that cannot describe itself is an error.
+func DiscoverPlugins(dir string, timeout time.Duration) (map[string]model.Plugin, error) {
+	plugins := make(map[string]model.Plugin)
+	if dir == "" {
+		return plugins, nil
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name())
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+
+		plugin := model.Plugin{Path: path}
+		response, err := CallPlugin(plugin, model.PluginRequest{Command: model.PluginDescribe}, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s could not describe itself: %s", file.Name(), err.Error())
+		}
+		if !sharedModel.IsPluginAction(sharedModel.ActionPluginPrefix + response.Name) {
+			return nil, fmt.Errorf("plugin %s is named %q, names must be lower case letters, digits and dashes", file.Name(), response.Name)
+		}
+		if _, ok := plugins[response.Name]; ok {
+			return nil, fmt.Errorf("plugin %s is named %s like %s", file.Name(), response.Name, filepath.Base(plugins[response.Name].Path))
+		}
+		plugin.Name = response.Name
+		plugin.Description = response.Description
+		plugins[plugin.Name] = plugin
+	}
+	return plugins, nil
+}
+
+// CallPlugin - writes a request to the stdin of a plugin and reads its response from its stdout, killing the plugin if it has
+// not answered within the timeout. A plugin fails by exiting with a non zero status, giving the reason on its stderr, or by
+// answering with an error.
+func CallPlugin(plugin model.Plugin, request model.PluginRequest, timeout time.Duration) (model.PluginResponse, error) {
+	var response model.PluginResponse
+	input, err := json.Marshal(request)
+	if err != nil {
+		return response, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(plugin.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Start()
+	if err != nil {
+		return response, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err = <-done:
+	case <-timer.C:
+		cmd.Process.Kill()
+		return response, fmt.Errorf("no answer to %s within %s", request.Command, timeout)
+	}
+	if err != nil {
+		if reason := strings.TrimSpace(stderr.String()); reason != "" {
+			return response, fmt.Errorf("%s: %s", err.Error(), reason)
+		}
+		return response, err
+	}
+
+	err = json.Unmarshal(stdout.Bytes(), &response)
+	if err != nil {
+		return response, fmt.Errorf("the answer to %s is not JSON: %s", request.Command, err.Error())
+	}
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// SetupPluginSnapshotDB - creates the plugin_snapshots DB if it does not exist
+func SetupPluginSnapshotDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS plugin_snapshots
+	(
+		id varchar(255),
+		plugin varchar(255),
+		appID varchar(255),
+		owner varchar(255),
+		state text,
+		createdAt varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SavePluginSnapshot - adds a row to plugin_snapshots database
+func SavePluginSnapshot(db *sql.DB, snapshot model.PluginSnapshot) error {
+	_, err := db.Exec("INSERT INTO plugin_snapshots (id, plugin, appID, owner, state, createdAt) VALUES (?, ?, ?, ?, ?, ?)",
+		snapshot.ID, snapshot.Plugin, snapshot.AppID, snapshot.Owner, string(snapshot.State), snapshot.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeletePluginSnapshot - removes a row from plugin_snapshots database once its action is rolled back
+func DeletePluginSnapshot(db *sql.DB, snapshotID string) error {
+	_, err := db.Exec("DELETE FROM plugin_snapshots WHERE id=?", snapshotID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetPluginSnapshots - loads the snapshots an owner saved and has not yet rolled back
+func GetPluginSnapshots(db *sql.DB, owner string) ([]model.PluginSnapshot, error) {
+	var snapshots []model.PluginSnapshot
+
+	rows, err := db.Query("SELECT id, plugin, appID, owner, state, createdAt FROM plugin_snapshots WHERE owner=?", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			snapshot model.PluginSnapshot
+			state    string
+		)
+		if err = rows.Scan(&snapshot.ID, &snapshot.Plugin, &snapshot.AppID, &snapshot.Owner, &state, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		if state != "" {
+			snapshot.State = json.RawMessage(state)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,219 @@
+package utils_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const flagPlugin = `#!/bin/sh
+request=$(cat)
+case "$request" in
+*'"command":"describe"'*) echo '{"name":"feature-flag","description":"Turns a feature flag off"}' ;;
+*'"command":"validate"'*) echo '{"valid":true}' ;;
+*'"command":"run"'*) echo '{"state":{"flag":"checkout"}}' ;;
+*) echo '{}' ;;
+esac
+`
+
+func writePlugin(dir string, name string, script string) string {
+	path := filepath.Join(dir, name)
+	Expect(ioutil.WriteFile(path, []byte(script), 0755)).To(Succeed())
+	return path
+}
+
+var _ = Describe("Plugins", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "chaos-galago-plugins")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("#DiscoverPlugins", func() {
+		It("describes every executable in the directory", func() {
+			path := writePlugin(dir, "flag", flagPlugin)
+			Expect(ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0644)).To(Succeed())
+			plugins, err := utils.DiscoverPlugins(dir, time.Second)
+			Expect(err).To(BeNil())
+			Expect(plugins).To(Equal(map[string]model.Plugin{"feature-flag": {Name: "feature-flag", Description: "Turns a feature flag off", Path: path}}))
+		})
+
+		Context("When no directory is set", func() {
+			It("returns no plugins", func() {
+				Expect(utils.DiscoverPlugins("", time.Second)).To(BeEmpty())
+			})
+		})
+
+		Context("When the directory does not exist", func() {
+			It("returns an error", func() {
+				_, err := utils.DiscoverPlugins(filepath.Join(dir, "missing"), time.Second)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		Context("When a plugin cannot describe itself", func() {
+			It("returns an error", func() {
+				writePlugin(dir, "broken", "#!/bin/sh\necho 'no describe' >&2\nexit 1\n")
+				_, err := utils.DiscoverPlugins(dir, time.Second)
+				Expect(err).To(MatchError("plugin broken could not describe itself: exit status 1: no describe"))
+			})
+		})
+
+		Context("When a plugin has an invalid name", func() {
+			It("returns an error", func() {
+				writePlugin(dir, "shouty", "#!/bin/sh\necho '{\"name\":\"Feature Flag\"}'\n")
+				_, err := utils.DiscoverPlugins(dir, time.Second)
+				Expect(err).To(MatchError(`plugin shouty is named "Feature Flag", names must be lower case letters, digits and dashes`))
+			})
+		})
+
+		Context("When two plugins have the same name", func() {
+			It("returns an error", func() {
+				writePlugin(dir, "flag", flagPlugin)
+				writePlugin(dir, "flag-copy", flagPlugin)
+				_, err := utils.DiscoverPlugins(dir, time.Second)
+				Expect(err).To(MatchError("plugin flag-copy is named feature-flag like flag"))
+			})
+		})
+	})
+
+	Describe("#CallPlugin", func() {
+		It("returns the answer of the plugin", func() {
+			plugin := model.Plugin{Name: "feature-flag", Path: writePlugin(dir, "flag", flagPlugin)}
+			response, err := utils.CallPlugin(plugin, model.PluginRequest{Command: model.PluginRun, AppID: "app-1"}, time.Second)
+			Expect(err).To(BeNil())
+			Expect(response.State).To(Equal(json.RawMessage(`{"flag":"checkout"}`)))
+		})
+
+		It("writes the request to the plugin", func() {
+			requestPath := filepath.Join(dir, "request")
+			plugin := model.Plugin{Path: writePlugin(dir, "recording", "#!/bin/sh\ncat > "+requestPath+"\necho '{}'\n")}
+			_, err := utils.CallPlugin(plugin, model.PluginRequest{Command: model.PluginRollback, AppID: "app-1", State: json.RawMessage(`{"flag":"checkout"}`)}, time.Second)
+			Expect(err).To(BeNil())
+			Expect(ioutil.ReadFile(requestPath)).To(MatchJSON(`{"command":"rollback","app_guid":"app-1","state":{"flag":"checkout"}}`))
+		})
+
+		Context("When the plugin exits with an error", func() {
+			It("returns its stderr", func() {
+				plugin := model.Plugin{Path: writePlugin(dir, "failing", "#!/bin/sh\necho 'flag service unavailable' >&2\nexit 2\n")}
+				_, err := utils.CallPlugin(plugin, model.PluginRequest{Command: model.PluginRun}, time.Second)
+				Expect(err).To(MatchError("exit status 2: flag service unavailable"))
+			})
+		})
+
+		Context("When the plugin answers with an error", func() {
+			It("returns the error", func() {
+				plugin := model.Plugin{Path: writePlugin(dir, "erroring", "#!/bin/sh\necho '{\"error\":\"flag not found\"}'\n")}
+				_, err := utils.CallPlugin(plugin, model.PluginRequest{Command: model.PluginRun}, time.Second)
+				Expect(err).To(MatchError("flag not found"))
+			})
+		})
+
+		Context("When the plugin does not answer JSON", func() {
+			It("returns an error", func() {
+				plugin := model.Plugin{Path: writePlugin(dir, "chatty", "#!/bin/sh\necho 'done'\n")}
+				_, err := utils.CallPlugin(plugin, model.PluginRequest{Command: model.PluginRun}, time.Second)
+				Expect(err.Error()).To(HavePrefix("the answer to run is not JSON"))
+			})
+		})
+
+		Context("When the plugin does not answer in time", func() {
+			It("kills it and returns an error", func() {
+				plugin := model.Plugin{Path: writePlugin(dir, "slow", "#!/bin/sh\nexec sleep 5\n")}
+				started := time.Now()
+				_, err := utils.CallPlugin(plugin, model.PluginRequest{Command: model.PluginRun}, 100*time.Millisecond)
+				Expect(err).To(MatchError("no answer to run within 100ms"))
+				Expect(time.Since(started)).To(BeNumerically("<", 2*time.Second))
+			})
+		})
+	})
+})
+
+var _ = Describe("Plugin snapshots", func() {
+	var (
+		mock     sqlmock.Sqlmock
+		db       *sql.DB
+		snapshot model.PluginSnapshot
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		snapshot = model.PluginSnapshot{ID: "1", Plugin: "feature-flag", AppID: "app-1", State: json.RawMessage(`{"flag":"checkout"}`), Owner: "0", CreatedAt: "2014-11-12T10:31:20Z"}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupPluginSnapshotDB", func() {
+		It("creates the plugin_snapshots table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS plugin_snapshots.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupPluginSnapshotDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#SavePluginSnapshot", func() {
+		It("inserts the snapshot with the state of the plugin", func() {
+			mock.ExpectExec("INSERT INTO plugin_snapshots .*").
+				WithArgs("1", "feature-flag", "app-1", "0", `{"flag":"checkout"}`, "2014-11-12T10:31:20Z").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SavePluginSnapshot(db, snapshot)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO plugin_snapshots .*").WillReturnError(fmt.Errorf("An error has occurred: %s", "Database Insert Error"))
+				Expect(utils.SavePluginSnapshot(db, snapshot)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeletePluginSnapshot", func() {
+		It("deletes the snapshot", func() {
+			mock.ExpectExec("DELETE FROM plugin_snapshots WHERE id=\\?").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(utils.DeletePluginSnapshot(db, "1")).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#GetPluginSnapshots", func() {
+		It("returns the snapshots of the owner", func() {
+			rows := sqlmock.NewRows([]string{"id", "plugin", "appID", "owner", "state", "createdAt"}).
+				AddRow("1", "feature-flag", "app-1", "0", `{"flag":"checkout"}`, "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("SELECT .* FROM plugin_snapshots WHERE owner=\\?").WithArgs("0").WillReturnRows(rows)
+			snapshots, err := utils.GetPluginSnapshots(db, "0")
+			Expect(err).To(BeNil())
+			Expect(snapshots).To(Equal([]model.PluginSnapshot{snapshot}))
+		})
+
+		Context("When the snapshots cannot be fetched", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("SELECT .* FROM plugin_snapshots WHERE owner=\\?").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.GetPluginSnapshots(db, "0")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// RunProbes - runs every probe in turn, returning the error of the first that fails
+func RunProbes(client *http.Client, probes []sharedModel.Probe) error {
+	for _, probe := range probes {
+		err := RunProbe(client, probe)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunProbe - requests the probe URL and checks the status, latency and body of the response
+func RunProbe(client *http.Client, probe sharedModel.Probe) error {
+	start := time.Now()
+	resp, err := client.Get(probe.URL)
+	if err != nil {
+		return fmt.Errorf("probe %s: %v", probe.URL, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	latency := time.Since(start)
+	if err != nil {
+		return fmt.Errorf("probe %s: %v", probe.URL, err)
+	}
+
+	if probe.ExpectedStatus != 0 && resp.StatusCode != probe.ExpectedStatus {
+		return fmt.Errorf("probe %s returned status %d, expected %d", probe.URL, resp.StatusCode, probe.ExpectedStatus)
+	}
+	if probe.MaxLatencyMs > 0 && latency > time.Duration(probe.MaxLatencyMs)*time.Millisecond {
+		return fmt.Errorf("probe %s took %dms, longer than %dms", probe.URL, int64(latency/time.Millisecond), probe.MaxLatencyMs)
+	}
+	if probe.BodyMatch != "" {
+		matched, err := regexp.Match(probe.BodyMatch, body)
+		if err != nil {
+			return fmt.Errorf("probe %s has an invalid body match: %v", probe.URL, err)
+		}
+		if !matched {
+			return fmt.Errorf("probe %s body did not match %s", probe.URL, probe.BodyMatch)
+		}
+	}
+	return nil
+}
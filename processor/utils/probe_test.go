@@ -0,0 +1,98 @@
+package utils_test
+
+import (
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = Describe("Probes", func() {
+	var (
+		server *httptest.Server
+		client *http.Client
+		probe  sharedModel.Probe
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"status": "UP"}`)
+		})
+		mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprint(w, `{"status": "UP"}`)
+		})
+		mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		server = httptest.NewServer(mux)
+		client = &http.Client{Timeout: time.Second}
+		probe = sharedModel.Probe{URL: server.URL + "/health", ExpectedStatus: 200, MaxLatencyMs: 1000, BodyMatch: `"status": "UP"`}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("#RunProbe", func() {
+		Context("When the app is in its steady state", func() {
+			It("returns no error", func() {
+				Expect(utils.RunProbe(client, probe)).To(BeNil())
+			})
+		})
+
+		Context("When the status is not the expected status", func() {
+			It("returns an error", func() {
+				probe.URL = server.URL + "/broken"
+				Expect(utils.RunProbe(client, probe)).To(MatchError(fmt.Sprintf("probe %s/broken returned status 503, expected 200", server.URL)))
+			})
+		})
+
+		Context("When the response is slower than the latency threshold", func() {
+			It("returns an error", func() {
+				probe.URL = server.URL + "/slow"
+				probe.MaxLatencyMs = 10
+				Expect(utils.RunProbe(client, probe)).To(MatchError(ContainSubstring("longer than 10ms")))
+			})
+		})
+
+		Context("When the body does not match", func() {
+			It("returns an error", func() {
+				probe.BodyMatch = "DOWN"
+				Expect(utils.RunProbe(client, probe)).To(MatchError(ContainSubstring("body did not match DOWN")))
+			})
+		})
+
+		Context("When the body match is not a valid regular expression", func() {
+			It("returns an error", func() {
+				probe.BodyMatch = "("
+				Expect(utils.RunProbe(client, probe)).To(MatchError(ContainSubstring("invalid body match")))
+			})
+		})
+
+		Context("When the app cannot be reached", func() {
+			It("returns an error", func() {
+				probe.URL = "http://127.0.0.1:1/health"
+				Expect(utils.RunProbe(client, probe)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#RunProbes", func() {
+		It("returns the error of the first probe that fails", func() {
+			broken := sharedModel.Probe{URL: server.URL + "/broken", ExpectedStatus: 200}
+			Expect(utils.RunProbes(client, []sharedModel.Probe{probe, broken})).To(MatchError(ContainSubstring("returned status 503")))
+		})
+
+		Context("When every probe passes", func() {
+			It("returns no error", func() {
+				Expect(utils.RunProbes(client, []sharedModel.Probe{probe, probe})).To(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"math/rand"
+)
+
+// ChooseRoute - picks at random one of the route mappings of an app
+func ChooseRoute(rng *rand.Rand, mappings []model.RouteMapping) (model.RouteMapping, error) {
+	if len(mappings) == 0 {
+		return model.RouteMapping{}, fmt.Errorf("the app has no routes mapped")
+	}
+	return mappings[rng.Intn(len(mappings))], nil
+}
+
+// SetupMappingSnapshotDB - creates the mapping_snapshots DB if it does not exist
+func SetupMappingSnapshotDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS mapping_snapshots
+	(
+		id varchar(255),
+		appID varchar(255),
+		routeID varchar(255),
+		appPort int,
+		owner varchar(255),
+		createdAt varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveMappingSnapshot - adds a row to mapping_snapshots database
+func SaveMappingSnapshot(db *sql.DB, snapshot model.MappingSnapshot) error {
+	_, err := db.Exec("INSERT INTO mapping_snapshots (id, appID, routeID, appPort, owner, createdAt) VALUES (?, ?, ?, ?, ?, ?)",
+		snapshot.ID, snapshot.Mapping.AppID, snapshot.Mapping.RouteID, snapshot.Mapping.AppPort, snapshot.Owner, snapshot.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteMappingSnapshot - removes a row from mapping_snapshots database once its route is mapped again
+func DeleteMappingSnapshot(db *sql.DB, snapshotID string) error {
+	_, err := db.Exec("DELETE FROM mapping_snapshots WHERE id=?", snapshotID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetMappingSnapshots - loads the snapshots an owner saved and has not yet restored
+func GetMappingSnapshots(db *sql.DB, owner string) ([]model.MappingSnapshot, error) {
+	var snapshots []model.MappingSnapshot
+
+	rows, err := db.Query("SELECT id, appID, routeID, appPort, owner, createdAt FROM mapping_snapshots WHERE owner=?", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var snapshot model.MappingSnapshot
+		if err = rows.Scan(&snapshot.ID, &snapshot.Mapping.AppID, &snapshot.Mapping.RouteID, &snapshot.Mapping.AppPort, &snapshot.Owner, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,109 @@
+package utils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("#ChooseRoute", func() {
+	var mappings = []model.RouteMapping{
+		{GUID: "mapping-1", AppID: "app-1", RouteID: "route-1"},
+		{GUID: "mapping-2", AppID: "app-1", RouteID: "route-2"},
+	}
+
+	It("returns one of the route mappings", func() {
+		mapping, err := utils.ChooseRoute(utils.NewRand(42), mappings)
+		Expect(err).To(BeNil())
+		Expect(mappings).To(ContainElement(mapping))
+	})
+
+	It("chooses the same route for the same seed", func() {
+		first, _ := utils.ChooseRoute(utils.NewRand(42), mappings)
+		second, _ := utils.ChooseRoute(utils.NewRand(42), mappings)
+		Expect(first).To(Equal(second))
+	})
+
+	Context("When the app has no routes", func() {
+		It("returns an error", func() {
+			_, err := utils.ChooseRoute(utils.NewRand(42), nil)
+			Expect(err).To(MatchError("the app has no routes mapped"))
+		})
+	})
+})
+
+var _ = Describe("Mapping snapshots", func() {
+	var (
+		mock     sqlmock.Sqlmock
+		db       *sql.DB
+		snapshot model.MappingSnapshot
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		snapshot = model.MappingSnapshot{ID: "1", Owner: "0", Mapping: model.RouteMapping{AppID: "app-1", RouteID: "route-1", AppPort: 8080}, CreatedAt: "2014-11-12T10:31:20Z"}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupMappingSnapshotDB", func() {
+		It("creates the mapping_snapshots table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS mapping_snapshots.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupMappingSnapshotDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#SaveMappingSnapshot", func() {
+		It("inserts the snapshot", func() {
+			mock.ExpectExec("INSERT INTO mapping_snapshots .*").WithArgs("1", "app-1", "route-1", 8080, "0", "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SaveMappingSnapshot(db, snapshot)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO mapping_snapshots .*").WillReturnError(fmt.Errorf("An error has occurred: %s", "Database Insert Error"))
+				Expect(utils.SaveMappingSnapshot(db, snapshot)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeleteMappingSnapshot", func() {
+		It("deletes the snapshot", func() {
+			mock.ExpectExec("DELETE FROM mapping_snapshots WHERE id=\\?").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(utils.DeleteMappingSnapshot(db, "1")).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#GetMappingSnapshots", func() {
+		It("returns the snapshots of the owner", func() {
+			rows := sqlmock.NewRows([]string{"id", "appID", "routeID", "appPort", "owner", "createdAt"}).AddRow("1", "app-1", "route-1", 8080, "0", "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("SELECT .* FROM mapping_snapshots WHERE owner=\\?").WithArgs("0").WillReturnRows(rows)
+			snapshots, err := utils.GetMappingSnapshots(db, "0")
+			Expect(err).To(BeNil())
+			Expect(snapshots).To(Equal([]model.MappingSnapshot{snapshot}))
+		})
+
+		Context("When the snapshots cannot be read", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("SELECT .* FROM mapping_snapshots WHERE owner=\\?").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.GetMappingSnapshots(db, "0")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
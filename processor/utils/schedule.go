@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"math/rand"
+	"time"
+)
+
+// NextSchedule - draws the time of the failure of an app after now as a Poisson process would, its exponential inter-arrival
+// times averaging the mean time between failures
+func NextSchedule(rng *rand.Rand, appID string, mtbfMinutes int, now time.Time) model.Schedule {
+	nextFailure := now.Add(time.Duration(rng.ExpFloat64() * float64(mtbfMinutes) * float64(time.Minute)))
+	return model.Schedule{AppID: appID, MTBFMinutes: mtbfMinutes, NextFailureAt: nextFailure.UTC().Format(eventTimeLayout)}
+}
+
+// FailureDue - determines if the next failure of a schedule is due, a schedule drawn for another mean time between failures
+// never being due
+func FailureDue(schedule model.Schedule, mtbfMinutes int, now time.Time) bool {
+	if schedule.MTBFMinutes != mtbfMinutes {
+		return false
+	}
+	nextFailure, err := time.Parse(eventTimeLayout, schedule.NextFailureAt)
+	if err != nil {
+		return false
+	}
+	return !now.Before(nextFailure)
+}
+
+// SetupScheduleDB - creates the chaos_schedules DB if it does not exist
+func SetupScheduleDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS chaos_schedules
+	(
+		appID varchar(255),
+		mtbfMinutes int,
+		nextFailureAt varchar(255),
+		PRIMARY KEY (appID)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetSchedule - loads the schedule of an app, an empty schedule if none has been drawn
+func GetSchedule(db *sql.DB, appID string) (model.Schedule, error) {
+	var schedule model.Schedule
+	err := db.QueryRow("SELECT appID, mtbfMinutes, nextFailureAt FROM chaos_schedules WHERE appID=?", appID).
+		Scan(&schedule.AppID, &schedule.MTBFMinutes, &schedule.NextFailureAt)
+	if err == sql.ErrNoRows {
+		return model.Schedule{}, nil
+	}
+	if err != nil {
+		return model.Schedule{}, err
+	}
+	return schedule, nil
+}
+
+// ClaimSchedule - replaces the schedule of an app with the next one, returning false if another processor replaced it first
+func ClaimSchedule(db *sql.DB, current model.Schedule, next model.Schedule) (bool, error) {
+	var (
+		result sql.Result
+		err    error
+	)
+	if current.AppID == "" {
+		result, err = db.Exec("INSERT IGNORE INTO chaos_schedules (appID, mtbfMinutes, nextFailureAt) VALUES (?, ?, ?)",
+			next.AppID, next.MTBFMinutes, next.NextFailureAt)
+	} else {
+		result, err = db.Exec("UPDATE chaos_schedules SET mtbfMinutes=?, nextFailureAt=? WHERE appID=? AND mtbfMinutes=? AND nextFailureAt=?",
+			next.MTBFMinutes, next.NextFailureAt, current.AppID, current.MTBFMinutes, current.NextFailureAt)
+	}
+	if err != nil {
+		return false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return claimed == 1, nil
+}
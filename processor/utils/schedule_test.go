@@ -0,0 +1,150 @@
+package utils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+	"time"
+)
+
+var _ = Describe("Schedules", func() {
+	var (
+		mock     sqlmock.Sqlmock
+		db       *sql.DB
+		now      time.Time
+		schedule model.Schedule
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		now = time.Date(2014, 11, 12, 10, 31, 20, 0, time.UTC)
+		schedule = model.Schedule{AppID: "app-1", MTBFMinutes: 240, NextFailureAt: "2014-11-12T10:31:20Z"}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#NextSchedule", func() {
+		It("draws failures averaging the mean time between failures", func() {
+			rng := utils.NewRand(42)
+			var total time.Duration
+			for i := 0; i < 10000; i++ {
+				next, err := time.Parse("2006-01-02T15:04:05Z", utils.NextSchedule(rng, "app-1", 240, now).NextFailureAt)
+				Expect(err).To(BeNil())
+				Expect(next).ToNot(BeTemporally("<", now))
+				total += next.Sub(now)
+			}
+			Expect((total / 10000).Minutes()).To(BeNumerically("~", 240, 10))
+		})
+
+		It("repeats the draw for the same seed", func() {
+			Expect(utils.NextSchedule(utils.NewRand(42), "app-1", 240, now)).To(Equal(utils.NextSchedule(utils.NewRand(42), "app-1", 240, now)))
+		})
+	})
+
+	Describe("#FailureDue", func() {
+		It("is due once the failure time has come", func() {
+			Expect(utils.FailureDue(schedule, 240, now)).To(BeTrue())
+			Expect(utils.FailureDue(schedule, 240, now.Add(time.Hour))).To(BeTrue())
+		})
+
+		It("is not due before the failure time", func() {
+			Expect(utils.FailureDue(schedule, 240, now.Add(-time.Second))).To(BeFalse())
+		})
+
+		Context("When the mean time between failures has changed", func() {
+			It("is not due", func() {
+				Expect(utils.FailureDue(schedule, 60, now)).To(BeFalse())
+			})
+		})
+
+		Context("When there is no schedule", func() {
+			It("is not due", func() {
+				Expect(utils.FailureDue(model.Schedule{}, 240, now)).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("#SetupScheduleDB", func() {
+		It("creates the chaos_schedules table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_schedules.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupScheduleDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#GetSchedule", func() {
+		It("returns the schedule of the app", func() {
+			rows := sqlmock.NewRows([]string{"appID", "mtbfMinutes", "nextFailureAt"}).AddRow("app-1", 240, "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").WithArgs("app-1").WillReturnRows(rows)
+			Expect(utils.GetSchedule(db, "app-1")).To(Equal(schedule))
+		})
+
+		Context("When no schedule has been drawn", func() {
+			It("returns an empty schedule", func() {
+				mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").WillReturnRows(sqlmock.NewRows([]string{"appID", "mtbfMinutes", "nextFailureAt"}))
+				Expect(utils.GetSchedule(db, "app-1")).To(Equal(model.Schedule{}))
+			})
+		})
+
+		Context("When the schedule cannot be fetched", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("SELECT .* FROM chaos_schedules WHERE appID=\\?").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.GetSchedule(db, "app-1")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#ClaimSchedule", func() {
+		var next model.Schedule
+
+		BeforeEach(func() {
+			next = model.Schedule{AppID: "app-1", MTBFMinutes: 240, NextFailureAt: "2014-11-12T14:02:11Z"}
+		})
+
+		It("replaces the schedule it was drawn from", func() {
+			mock.ExpectExec("UPDATE chaos_schedules SET .* WHERE appID=\\? AND mtbfMinutes=\\? AND nextFailureAt=\\?").
+				WithArgs(240, "2014-11-12T14:02:11Z", "app-1", 240, "2014-11-12T10:31:20Z").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(utils.ClaimSchedule(db, schedule, next)).To(BeTrue())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When another processor replaced the schedule first", func() {
+			It("does not claim it", func() {
+				mock.ExpectExec("UPDATE chaos_schedules SET .*").WillReturnResult(sqlmock.NewResult(0, 0))
+				Expect(utils.ClaimSchedule(db, schedule, next)).To(BeFalse())
+			})
+		})
+
+		Context("When the app has no schedule", func() {
+			It("inserts the first one", func() {
+				mock.ExpectExec("INSERT IGNORE INTO chaos_schedules .*").
+					WithArgs("app-1", 240, "2014-11-12T14:02:11Z").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				Expect(utils.ClaimSchedule(db, model.Schedule{}, next)).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the schedule cannot be written", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("UPDATE chaos_schedules SET .*").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+				_, err := utils.ClaimSchedule(db, schedule, next)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/cloudfoundry-community/go-cfclient"
+	"strings"
+)
+
+// BlackholeGroups - finds the blackhole security group by name and the other security groups bound to a space, which
+// must be unbound for the blackhole to take effect. Platform wide running groups are not bound to spaces and stay in place.
+func BlackholeGroups(secGroups []cfclient.SecGroup, name string, spaceID string) (string, []string, error) {
+	var (
+		blackhole string
+		bound     []string
+	)
+	for _, secGroup := range secGroups {
+		if secGroup.Name == name {
+			if boundToSpace(secGroup, spaceID) {
+				return "", nil, fmt.Errorf("the egress of space %s is already blackholed", spaceID)
+			}
+			blackhole = secGroup.Guid
+			continue
+		}
+		if boundToSpace(secGroup, spaceID) {
+			bound = append(bound, secGroup.Guid)
+		}
+	}
+	if blackhole == "" {
+		return "", nil, fmt.Errorf("security group %s does not exist", name)
+	}
+	return blackhole, bound, nil
+}
+
+func boundToSpace(secGroup cfclient.SecGroup, spaceID string) bool {
+	for _, space := range secGroup.SpacesData {
+		if space.Entity.Guid == spaceID {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupSecGroupSnapshotDB - creates the secgroup_snapshots DB if it does not exist
+func SetupSecGroupSnapshotDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS secgroup_snapshots
+	(
+		id varchar(255),
+		appID varchar(255),
+		spaceID varchar(255),
+		blackholeID varchar(255),
+		secGroups text,
+		owner varchar(255),
+		createdAt varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveSecGroupSnapshot - adds a row to secgroup_snapshots database
+func SaveSecGroupSnapshot(db *sql.DB, snapshot model.SecGroupSnapshot) error {
+	_, err := db.Exec("INSERT INTO secgroup_snapshots (id, appID, spaceID, blackholeID, secGroups, owner, createdAt) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		snapshot.ID, snapshot.AppID, snapshot.SpaceID, snapshot.BlackholeID, strings.Join(snapshot.Groups, ","), snapshot.Owner, snapshot.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteSecGroupSnapshot - removes a row from secgroup_snapshots database once its security groups are bound again
+func DeleteSecGroupSnapshot(db *sql.DB, snapshotID string) error {
+	_, err := db.Exec("DELETE FROM secgroup_snapshots WHERE id=?", snapshotID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetSecGroupSnapshots - loads the snapshots an owner saved and has not yet restored
+func GetSecGroupSnapshots(db *sql.DB, owner string) ([]model.SecGroupSnapshot, error) {
+	var snapshots []model.SecGroupSnapshot
+
+	rows, err := db.Query("SELECT id, appID, spaceID, blackholeID, secGroups, owner, createdAt FROM secgroup_snapshots WHERE owner=?", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			snapshot model.SecGroupSnapshot
+			groups   string
+		)
+		if err = rows.Scan(&snapshot.ID, &snapshot.AppID, &snapshot.SpaceID, &snapshot.BlackholeID, &groups, &snapshot.Owner, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		if groups != "" {
+			snapshot.Groups = strings.Split(groups, ",")
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,124 @@
+package utils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+func secGroup(guid string, name string, spaces ...string) cfclient.SecGroup {
+	group := cfclient.SecGroup{Guid: guid, Name: name}
+	for _, space := range spaces {
+		group.SpacesData = append(group.SpacesData, cfclient.SpaceResource{Entity: cfclient.Space{Guid: space}})
+	}
+	return group
+}
+
+var _ = Describe("#BlackholeGroups", func() {
+	var secGroups []cfclient.SecGroup
+
+	BeforeEach(func() {
+		secGroups = []cfclient.SecGroup{
+			secGroup("group-1", "public_networks"),
+			secGroup("group-2", "database", "space-1", "space-2"),
+			secGroup("group-3", "chaos-galago-blackhole"),
+			secGroup("group-4", "api", "space-2"),
+			secGroup("group-5", "dns", "space-1"),
+		}
+	})
+
+	It("returns the blackhole and the other groups bound to the space", func() {
+		blackhole, bound, err := utils.BlackholeGroups(secGroups, "chaos-galago-blackhole", "space-1")
+		Expect(err).To(BeNil())
+		Expect(blackhole).To(Equal("group-3"))
+		Expect(bound).To(Equal([]string{"group-2", "group-5"}))
+	})
+
+	Context("When the blackhole group does not exist", func() {
+		It("returns an error", func() {
+			_, _, err := utils.BlackholeGroups(secGroups, "missing", "space-1")
+			Expect(err).To(MatchError("security group missing does not exist"))
+		})
+	})
+
+	Context("When the blackhole group is already bound to the space", func() {
+		It("returns an error", func() {
+			secGroups[2] = secGroup("group-3", "chaos-galago-blackhole", "space-1")
+			_, _, err := utils.BlackholeGroups(secGroups, "chaos-galago-blackhole", "space-1")
+			Expect(err).To(MatchError("the egress of space space-1 is already blackholed"))
+		})
+	})
+})
+
+var _ = Describe("Security group snapshots", func() {
+	var (
+		mock     sqlmock.Sqlmock
+		db       *sql.DB
+		snapshot model.SecGroupSnapshot
+		columns  = []string{"id", "appID", "spaceID", "blackholeID", "secGroups", "owner", "createdAt"}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		snapshot = model.SecGroupSnapshot{ID: "1", AppID: "app-1", SpaceID: "space-1", BlackholeID: "group-3", Groups: []string{"group-2", "group-5"}, Owner: "0", CreatedAt: "2014-11-12T10:31:20Z"}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupSecGroupSnapshotDB", func() {
+		It("creates the secgroup_snapshots table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS secgroup_snapshots.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SetupSecGroupSnapshotDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#SaveSecGroupSnapshot", func() {
+		It("inserts the snapshot", func() {
+			mock.ExpectExec("INSERT INTO secgroup_snapshots .*").WithArgs("1", "app-1", "space-1", "group-3", "group-2,group-5", "0", "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(utils.SaveSecGroupSnapshot(db, snapshot)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#DeleteSecGroupSnapshot", func() {
+		It("deletes the snapshot", func() {
+			mock.ExpectExec("DELETE FROM secgroup_snapshots WHERE id=\\?").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(utils.DeleteSecGroupSnapshot(db, "1")).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#GetSecGroupSnapshots", func() {
+		It("returns the snapshots of the owner", func() {
+			rows := sqlmock.NewRows(columns).AddRow("1", "app-1", "space-1", "group-3", "group-2,group-5", "0", "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("SELECT .* FROM secgroup_snapshots WHERE owner=\\?").WithArgs("0").WillReturnRows(rows)
+			snapshots, err := utils.GetSecGroupSnapshots(db, "0")
+			Expect(err).To(BeNil())
+			Expect(snapshots).To(Equal([]model.SecGroupSnapshot{snapshot}))
+		})
+
+		Context("When the space had no security groups bound", func() {
+			It("returns a snapshot without groups", func() {
+				rows := sqlmock.NewRows(columns).AddRow("1", "app-1", "space-1", "group-3", "", "0", "2014-11-12T10:31:20Z")
+				mock.ExpectQuery("SELECT .* FROM secgroup_snapshots WHERE owner=\\?").WithArgs("0").WillReturnRows(rows)
+				snapshots, err := utils.GetSecGroupSnapshots(db, "0")
+				Expect(err).To(BeNil())
+				Expect(snapshots[0].Groups).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// StrategyNeedsStats - determines if a victim selection strategy ranks instances by their stats
+func StrategyNeedsStats(strategy string) bool {
+	switch strategy {
+	case sharedModel.StrategyOldest, sharedModel.StrategyBusiestCPU, sharedModel.StrategyBusiestMemory:
+		return true
+	}
+	return false
+}
+
+// VictimCount - returns how many of an app's instances to kill in one run.
+// A kill percentage takes precedence over a kill count and is rounded to at least one instance. The count is capped so
+// that at least MinHealthy instances stay healthy, so it is 0 when no instance can be killed.
+func VictimCount(settings sharedModel.InstanceSettings, instances int, healthy int) int {
+	count := settings.KillCount
+	if settings.KillPercent > 0 {
+		count = int(math.Floor(float64(instances*settings.KillPercent)/100 + 0.5))
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	if spare := healthy - settings.MinHealthy; count > spare {
+		count = spare
+	}
+	if count < 0 {
+		return 0
+	}
+	return count
+}
+
+// RankInstances - orders candidate instance indexes by how strongly the strategy prefers them as victims
+func RankInstances(settings sharedModel.InstanceSettings, rng *rand.Rand, candidates []int, appStats map[string]model.AppStats) ([]int, error) {
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no instances are available")
+	}
+
+	switch settings.Strategy {
+	case sharedModel.StrategyFixed:
+		for _, index := range candidates {
+			if index == settings.StrategyIndex {
+				return []int{index}, nil
+			}
+		}
+		return nil, fmt.Errorf("instance index %d is not available", settings.StrategyIndex)
+	case sharedModel.StrategyNotFirst:
+		var ranked []int
+		for _, index := range shuffle(rng, candidates) {
+			if index != 0 {
+				ranked = append(ranked, index)
+			}
+		}
+		if len(ranked) == 0 {
+			return nil, fmt.Errorf("only instance index 0 is available")
+		}
+		return ranked, nil
+	case sharedModel.StrategyOldest:
+		return rankByStat(candidates, appStats, func(stats model.AppStats) float64 {
+			return float64(stats.Stats.Uptime)
+		})
+	case sharedModel.StrategyBusiestCPU:
+		return rankByStat(candidates, appStats, func(stats model.AppStats) float64 {
+			return stats.Stats.Usage.CPU
+		})
+	case sharedModel.StrategyBusiestMemory:
+		return rankByStat(candidates, appStats, func(stats model.AppStats) float64 {
+			return float64(stats.Stats.Usage.Mem)
+		})
+	}
+	return shuffle(rng, candidates), nil
+}
+
+func shuffle(rng *rand.Rand, indexes []int) []int {
+	shuffled := make([]int, len(indexes))
+	for i, j := range rng.Perm(len(indexes)) {
+		shuffled[i] = indexes[j]
+	}
+	return shuffled
+}
+
+type rankedInstance struct {
+	index int
+	value float64
+}
+
+type byValueDescending []rankedInstance
+
+func (r byValueDescending) Len() int      { return len(r) }
+func (r byValueDescending) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byValueDescending) Less(i, j int) bool {
+	if r[i].value == r[j].value {
+		return r[i].index < r[j].index
+	}
+	return r[i].value > r[j].value
+}
+
+func rankByStat(candidates []int, appStats map[string]model.AppStats, stat func(model.AppStats) float64) ([]int, error) {
+	var instances []rankedInstance
+	for _, index := range candidates {
+		stats, ok := appStats[strconv.Itoa(index)]
+		if !ok {
+			continue
+		}
+		instances = append(instances, rankedInstance{index: index, value: stat(stats)})
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no instance stats are available")
+	}
+
+	sort.Sort(byValueDescending(instances))
+	ranked := make([]int, len(instances))
+	for i, instance := range instances {
+		ranked[i] = instance.index
+	}
+	return ranked, nil
+}
+
+// ChooseCell - picks at random one of the cells hosting the candidate instances to take out at once, returning the cell
+// and the indexes of the candidates on it. Only cells hosting at most spare candidates can be chosen, so that killing
+// every instance on the cell keeps the app above its minimum healthy instances.
+func ChooseCell(rng *rand.Rand, candidates []int, appStats map[string]model.AppStats, spare int) (string, []int, error) {
+	cells := make(map[string][]int)
+	for _, index := range candidates {
+		stats, ok := appStats[strconv.Itoa(index)]
+		if !ok || stats.Stats.Host == "" {
+			continue
+		}
+		cells[stats.Stats.Host] = append(cells[stats.Stats.Host], index)
+	}
+	if len(cells) == 0 {
+		return "", nil, fmt.Errorf("no instance stats are available")
+	}
+
+	var hosts []string
+	for host, indexes := range cells {
+		if len(indexes) <= spare {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return "", nil, fmt.Errorf("every cell hosts more than %d of the healthy instances", spare)
+	}
+	sort.Strings(hosts)
+
+	host := hosts[rng.Intn(len(hosts))]
+	indexes := cells[host]
+	sort.Ints(indexes)
+	return host, indexes, nil
+}
@@ -0,0 +1,263 @@
+package utils_test
+
+import (
+	"github.com/FidelityInternational/chaos-galago/processor/model"
+	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math/rand"
+	"time"
+)
+
+var _ = Describe("#StrategyNeedsStats", func() {
+	It("is true only for strategies ranking by instance stats", func() {
+		Expect(utils.StrategyNeedsStats(sharedModel.StrategyOldest)).To(BeTrue())
+		Expect(utils.StrategyNeedsStats(sharedModel.StrategyBusiestCPU)).To(BeTrue())
+		Expect(utils.StrategyNeedsStats(sharedModel.StrategyBusiestMemory)).To(BeTrue())
+		Expect(utils.StrategyNeedsStats(sharedModel.StrategyRandom)).To(BeFalse())
+		Expect(utils.StrategyNeedsStats(sharedModel.StrategyFixed)).To(BeFalse())
+		Expect(utils.StrategyNeedsStats(sharedModel.StrategyNotFirst)).To(BeFalse())
+	})
+})
+
+var _ = Describe("#VictimCount", func() {
+	var settings sharedModel.InstanceSettings
+
+	BeforeEach(func() {
+		settings = sharedModel.NewInstanceSettings("1")
+	})
+
+	It("kills one instance by default", func() {
+		Expect(utils.VictimCount(settings, 4, 4)).To(Equal(1))
+	})
+
+	It("kills the configured number of instances", func() {
+		settings.KillCount = 3
+		Expect(utils.VictimCount(settings, 4, 4)).To(Equal(3))
+	})
+
+	Context("When a kill percentage is configured", func() {
+		It("takes precedence over the kill count", func() {
+			settings.KillCount = 3
+			settings.KillPercent = 50
+			Expect(utils.VictimCount(settings, 4, 4)).To(Equal(2))
+		})
+
+		It("rounds to the nearest instance", func() {
+			settings.KillPercent = 30
+			Expect(utils.VictimCount(settings, 5, 5)).To(Equal(2))
+			Expect(utils.VictimCount(settings, 4, 4)).To(Equal(1))
+		})
+
+		It("kills at least one instance", func() {
+			settings.KillPercent = 1
+			Expect(utils.VictimCount(settings, 4, 4)).To(Equal(1))
+		})
+	})
+
+	Context("When a minimum healthy count is configured", func() {
+		BeforeEach(func() {
+			settings.KillCount = 3
+			settings.MinHealthy = 2
+		})
+
+		It("never leaves fewer healthy instances", func() {
+			Expect(utils.VictimCount(settings, 4, 4)).To(Equal(2))
+			Expect(utils.VictimCount(settings, 4, 3)).To(Equal(1))
+		})
+
+		It("kills nothing when no instance can be spared", func() {
+			Expect(utils.VictimCount(settings, 2, 2)).To(Equal(0))
+			Expect(utils.VictimCount(settings, 2, 1)).To(Equal(0))
+		})
+	})
+})
+
+var _ = Describe("#RankInstances", func() {
+	var (
+		rng      *rand.Rand
+		settings sharedModel.InstanceSettings
+		appStats map[string]model.AppStats
+	)
+
+	BeforeEach(func() {
+		rng = utils.NewRand(time.Now().UTC().UnixNano())
+		settings = sharedModel.NewInstanceSettings("1")
+		appStats = map[string]model.AppStats{
+			"0": {Stats: model.InstanceStats{Uptime: 100, Usage: model.InstanceUsage{CPU: 0.5, Mem: 300}}},
+			"1": {Stats: model.InstanceStats{Uptime: 900, Usage: model.InstanceUsage{CPU: 0.1, Mem: 100}}},
+			"2": {Stats: model.InstanceStats{Uptime: 500, Usage: model.InstanceUsage{CPU: 0.9, Mem: 200}}},
+		}
+	})
+
+	Context("When there are no candidates", func() {
+		It("returns an error", func() {
+			_, err := utils.RankInstances(settings, rng, nil, appStats)
+			Expect(err).To(MatchError("no instances are available"))
+		})
+	})
+
+	Context("When the strategy is random", func() {
+		It("returns every candidate", func() {
+			ranked, err := utils.RankInstances(settings, rng, []int{10, 11, 12}, appStats)
+			Expect(err).To(BeNil())
+			Expect(ranked).To(ConsistOf(10, 11, 12))
+		})
+
+		It("puts every candidate first over many runs", func() {
+			first := make(map[int]int)
+			for i := 0; i < 300; i++ {
+				ranked, _ := utils.RankInstances(settings, rng, []int{0, 1, 2}, appStats)
+				first[ranked[0]]++
+			}
+			Expect(first).To(HaveLen(3))
+			for _, count := range first {
+				Expect(count).To(BeNumerically(">", 50))
+			}
+		})
+
+		It("ranks the same way for the same seed", func() {
+			first, _ := utils.RankInstances(settings, utils.NewRand(42), []int{0, 1, 2, 3, 4, 5}, appStats)
+			second, _ := utils.RankInstances(settings, utils.NewRand(42), []int{0, 1, 2, 3, 4, 5}, appStats)
+			Expect(first).To(Equal(second))
+		})
+	})
+
+	Context("When the strategy is not-first", func() {
+		BeforeEach(func() {
+			settings.Strategy = sharedModel.StrategyNotFirst
+		})
+
+		It("never returns instance index 0", func() {
+			ranked, err := utils.RankInstances(settings, rng, []int{0, 1, 2}, appStats)
+			Expect(err).To(BeNil())
+			Expect(ranked).To(ConsistOf(1, 2))
+		})
+
+		Context("and only instance index 0 is available", func() {
+			It("returns an error", func() {
+				_, err := utils.RankInstances(settings, rng, []int{0}, appStats)
+				Expect(err).To(MatchError("only instance index 0 is available"))
+			})
+		})
+	})
+
+	Context("When the strategy is fixed", func() {
+		BeforeEach(func() {
+			settings.Strategy = sharedModel.StrategyFixed
+			settings.StrategyIndex = 2
+		})
+
+		It("returns the configured instance index", func() {
+			ranked, err := utils.RankInstances(settings, rng, []int{0, 1, 2}, appStats)
+			Expect(err).To(BeNil())
+			Expect(ranked).To(Equal([]int{2}))
+		})
+
+		Context("and the configured instance index is not available", func() {
+			It("returns an error", func() {
+				_, err := utils.RankInstances(settings, rng, []int{0, 1}, appStats)
+				Expect(err).To(MatchError("instance index 2 is not available"))
+			})
+		})
+	})
+
+	Context("When the strategy is oldest", func() {
+		It("ranks instances by uptime", func() {
+			settings.Strategy = sharedModel.StrategyOldest
+			ranked, err := utils.RankInstances(settings, rng, []int{0, 1, 2}, appStats)
+			Expect(err).To(BeNil())
+			Expect(ranked).To(Equal([]int{1, 2, 0}))
+		})
+	})
+
+	Context("When the strategy is busiest-cpu", func() {
+		It("ranks instances by cpu usage", func() {
+			settings.Strategy = sharedModel.StrategyBusiestCPU
+			ranked, err := utils.RankInstances(settings, rng, []int{0, 1, 2}, appStats)
+			Expect(err).To(BeNil())
+			Expect(ranked).To(Equal([]int{2, 0, 1}))
+		})
+
+		Context("and a candidate has no stats", func() {
+			It("leaves it out", func() {
+				settings.Strategy = sharedModel.StrategyBusiestCPU
+				ranked, err := utils.RankInstances(settings, rng, []int{0, 1, 2, 3}, appStats)
+				Expect(err).To(BeNil())
+				Expect(ranked).To(Equal([]int{2, 0, 1}))
+			})
+		})
+
+		Context("and no candidate has stats", func() {
+			It("returns an error", func() {
+				settings.Strategy = sharedModel.StrategyBusiestCPU
+				_, err := utils.RankInstances(settings, rng, []int{0, 1}, nil)
+				Expect(err).To(MatchError("no instance stats are available"))
+			})
+		})
+	})
+
+	Context("When the strategy is busiest-memory", func() {
+		It("ranks instances by memory usage", func() {
+			settings.Strategy = sharedModel.StrategyBusiestMemory
+			ranked, err := utils.RankInstances(settings, rng, []int{0, 1, 2}, appStats)
+			Expect(err).To(BeNil())
+			Expect(ranked).To(Equal([]int{0, 2, 1}))
+		})
+	})
+})
+
+var _ = Describe("#ChooseCell", func() {
+	var appStats map[string]model.AppStats
+
+	BeforeEach(func() {
+		appStats = map[string]model.AppStats{
+			"0": {Stats: model.InstanceStats{Host: "10.0.0.1"}},
+			"1": {Stats: model.InstanceStats{Host: "10.0.0.2"}},
+			"2": {Stats: model.InstanceStats{Host: "10.0.0.1"}},
+			"3": {Stats: model.InstanceStats{Host: "10.0.0.3"}},
+		}
+	})
+
+	It("returns every candidate on the chosen cell", func() {
+		host, indexes, err := utils.ChooseCell(utils.NewRand(42), []int{0, 1, 2, 3}, appStats, 2)
+		Expect(err).To(BeNil())
+		Expect([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}).To(ContainElement(host))
+		if host == "10.0.0.1" {
+			Expect(indexes).To(Equal([]int{0, 2}))
+		} else {
+			Expect(indexes).To(HaveLen(1))
+		}
+	})
+
+	It("chooses the same cell for the same seed", func() {
+		first, _, _ := utils.ChooseCell(utils.NewRand(42), []int{0, 1, 2, 3}, appStats, 2)
+		second, _, _ := utils.ChooseCell(utils.NewRand(42), []int{0, 1, 2, 3}, appStats, 2)
+		Expect(first).To(Equal(second))
+	})
+
+	Context("When a cell hosts more instances than can be spared", func() {
+		It("is never chosen", func() {
+			for seed := int64(0); seed < 20; seed++ {
+				host, _, err := utils.ChooseCell(utils.NewRand(seed), []int{0, 1, 2, 3}, appStats, 1)
+				Expect(err).To(BeNil())
+				Expect(host).ToNot(Equal("10.0.0.1"))
+			}
+		})
+	})
+
+	Context("When no cell can be spared", func() {
+		It("returns an error", func() {
+			_, _, err := utils.ChooseCell(utils.NewRand(42), []int{0, 2}, appStats, 1)
+			Expect(err).To(MatchError("every cell hosts more than 1 of the healthy instances"))
+		})
+	})
+
+	Context("When there are no instance stats", func() {
+		It("returns an error", func() {
+			_, _, err := utils.ChooseCell(utils.NewRand(42), []int{0, 1}, nil, 2)
+			Expect(err).To(MatchError("no instance stats are available"))
+		})
+	})
+})
@@ -8,34 +8,98 @@ import (
 	"github.com/FidelityInternational/chaos-galago/shared/model"
 	"github.com/FidelityInternational/chaos-galago/shared/utils"
 	"github.com/cloudfoundry-community/go-cfclient"
+	"hash/fnv"
 	"math/rand"
 	"os"
+	"sort"
 	"strconv"
 	"time"
 )
 
+// NewRand - returns a random number generator seeded with seed
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// RunSeed - returns the seed for one run of chaos against an app.
+// A configured seed is mixed with the app and the time of the run so that runs differ from each other, yet each is
+// determined by them. Without a configured seed the current time is used.
+func RunSeed(configuredSeed int64, appID string, runAt string) int64 {
+	hash := fnv.New64a()
+	if configuredSeed == 0 {
+		fmt.Fprintf(hash, "%d/%s", time.Now().UnixNano(), appID)
+	} else {
+		fmt.Fprintf(hash, "%d/%s/%s", configuredSeed, appID, runAt)
+	}
+	return int64(hash.Sum64())
+}
+
 // ShouldRun - determins of chaos should be run based on probability
-func ShouldRun(probability float64) bool {
-	return rand.Float64() <= probability
+func ShouldRun(rng *rand.Rand, probability float64) bool {
+	return rng.Float64() < probability
 }
 
-// IsAppHealthy - determins if an app is currently in "RUNNING" state
-func IsAppHealthy(appInstances map[string]cfclient.AppInstance) bool {
+// CheckHealth - determines if an app is healthy enough for chaos, returning the reason when it is not
+func CheckHealth(settings sharedModel.InstanceSettings, appInstances map[string]cfclient.AppInstance) (bool, string) {
+	instances := len(appInstances)
+	if instances < settings.MinInstances {
+		return false, fmt.Sprintf("app has %d instances, fewer than the minimum of %d", instances, settings.MinInstances)
+	}
+
+	healthy := HealthyCount(settings, appInstances)
+	if healthy*100 < settings.MinHealthyPercent*instances {
+		return false, fmt.Sprintf("%d of %d instances are healthy, fewer than the minimum of %d percent", healthy, instances, settings.MinHealthyPercent)
+	}
+	return true, ""
+}
+
+// HealthyCount - counts the app instances in a state the settings count as healthy
+func HealthyCount(settings sharedModel.InstanceSettings, appInstances map[string]cfclient.AppInstance) int {
+	var healthy int
 	for _, instance := range appInstances {
-		if instance.State != "RUNNING" {
-			return false
+		if settings.IsHealthyState(instance.State) {
+			healthy++
 		}
 	}
-	return true
+	return healthy
 }
 
-// PickAppInstance - selects an app instance index
-func PickAppInstance(appInstances map[string]cfclient.AppInstance) int {
+// HealthyIndexes - returns the indexes of the healthy app instances in ascending order
+func HealthyIndexes(settings sharedModel.InstanceSettings, appInstances map[string]cfclient.AppInstance) []int {
+	healthyInstances := make(map[string]cfclient.AppInstance)
+	for index, instance := range appInstances {
+		if settings.IsHealthyState(instance.State) {
+			healthyInstances[index] = instance
+		}
+	}
+	return InstanceIndexes(healthyInstances)
+}
+
+// RecoveredAfter - determines if a killed app instance is running again, returning how long it took to recover.
+// An instance whose uptime reaches back before the kill is still the process that was killed.
+func RecoveredAfter(appStats model.AppStats, sinceKill time.Duration) (float64, bool) {
+	if appStats.State != "RUNNING" {
+		return 0, false
+	}
+	uptime := time.Duration(appStats.Stats.Uptime) * time.Second
+	if uptime > sinceKill {
+		return 0, false
+	}
+	return (sinceKill - uptime).Seconds(), true
+}
+
+// InstanceIndexes - returns the indexes of the app instances in ascending order
+func InstanceIndexes(appInstances map[string]cfclient.AppInstance) []int {
+	var indexes []int
 	for k := range appInstances {
-		value, _ := strconv.Atoi(k)
-		return value
+		value, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		indexes = append(indexes, value)
 	}
-	return 0
+	sort.Ints(indexes)
+	return indexes
 }
 
 // ShouldProcess - determines if chaos-galago should be run based on frequency and previous run
@@ -66,16 +130,52 @@ func GetBoundApps(db *sql.DB) []model.Service {
 	if err != nil {
 		return services
 	}
+	instanceSettings, err := sharedUtils.ReadInstanceSettings(db)
+	if err != nil {
+		return services
+	}
+	probes, err := sharedUtils.ReadProbes(db)
+	if err != nil {
+		return services
+	}
+	paused, err := sharedUtils.ReadPausedBindings(db)
+	if err != nil {
+		return services
+	}
+	profiles, err := sharedUtils.ReadProfiles(db)
+	if err != nil {
+		return services
+	}
+	lastChanges, err := sharedUtils.ReadLastProbabilityChanges(db)
+	if err != nil {
+		return services
+	}
+	replays, err := sharedUtils.ReadReplays(db)
+	if err != nil {
+		return services
+	}
 OUTER:
 	for _, binding := range serviceBindings {
 		serviceInstance := serviceInstances[binding.ServiceInstanceID]
 		appID := binding.AppID
 		probability := serviceInstance.Probability
 		frequency := serviceInstance.Frequency
-		if serviceInstance == (sharedModel.ServiceInstance{}) || appID == "" || probability == 0 || frequency == 0 {
+		if serviceInstance == (sharedModel.ServiceInstance{}) || appID == "" {
 			continue OUTER
 		}
-		services = append(services, model.Service{AppID: appID, LastProcessed: binding.LastProcessed, Probability: probability, Frequency: frequency})
+		settings, ok := instanceSettings[serviceInstance.ID]
+		if !ok {
+			settings = sharedModel.NewInstanceSettings(serviceInstance.ID)
+		}
+		if !settings.UsesMTBF() && (probability == 0 || frequency == 0) {
+			continue OUTER
+		}
+		services = append(services, model.Service{AppID: appID, LastProcessed: binding.LastProcessed, Probability: probability, Frequency: frequency,
+			ServiceInstanceID: serviceInstance.ID, ServiceBindingID: binding.ID, Settings: settings, Probes: probes[serviceInstance.ID],
+			Profile: profiles[serviceInstance.ID], LastChange: lastChanges[serviceInstance.ID], Replay: replays[binding.ID]})
+		if _, ok := paused[binding.ID]; ok {
+			services[len(services)-1].Paused = true
+		}
 	}
 	return services
 }
@@ -5,6 +5,8 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/FidelityInternational/chaos-galago/processor/model"
 	"github.com/FidelityInternational/chaos-galago/processor/utils"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
 	"github.com/cloudfoundry-community/go-cfclient"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -14,92 +16,178 @@ import (
 )
 
 var _ = Describe("#ShouldRun", func() {
+	var rng *rand.Rand
+
 	BeforeEach(func() {
-		rand.Seed(time.Now().UTC().UnixNano())
+		rng = utils.NewRand(time.Now().UTC().UnixNano())
 	})
 
 	Context("When the probability is 0", func() {
 		It("Returns false", func() {
-			Expect(utils.ShouldRun(0)).To(BeFalse())
+			Expect(utils.ShouldRun(rng, 0)).To(BeFalse())
 		})
 	})
 
 	Context("When the probability is 1", func() {
 		It("Returns true", func() {
-			Expect(utils.ShouldRun(1)).To(BeTrue())
+			Expect(utils.ShouldRun(rng, 1)).To(BeTrue())
+		})
+	})
+
+	Context("When two generators share a seed", func() {
+		It("makes the same decisions", func() {
+			first := utils.NewRand(42)
+			second := utils.NewRand(42)
+			for i := 0; i < 10; i++ {
+				Expect(utils.ShouldRun(first, 0.5)).To(Equal(utils.ShouldRun(second, 0.5)))
+			}
+		})
+	})
+})
+
+var _ = Describe("#RunSeed", func() {
+	Context("When a seed is configured", func() {
+		It("returns the same seed for the same app and run time", func() {
+			Expect(utils.RunSeed(42, "app-1", "2014-11-12T10:31:20Z")).To(Equal(utils.RunSeed(42, "app-1", "2014-11-12T10:31:20Z")))
+		})
+
+		It("returns a different seed for a different app or run time", func() {
+			seed := utils.RunSeed(42, "app-1", "2014-11-12T10:31:20Z")
+			Expect(utils.RunSeed(42, "app-2", "2014-11-12T10:31:20Z")).ToNot(Equal(seed))
+			Expect(utils.RunSeed(42, "app-1", "2014-11-12T10:32:20Z")).ToNot(Equal(seed))
+			Expect(utils.RunSeed(43, "app-1", "2014-11-12T10:31:20Z")).ToNot(Equal(seed))
+		})
+	})
+
+	Context("When no seed is configured", func() {
+		It("returns a seed that changes between runs", func() {
+			seed := utils.RunSeed(0, "app-1", "2014-11-12T10:31:20Z")
+			time.Sleep(time.Millisecond)
+			Expect(utils.RunSeed(0, "app-1", "2014-11-12T10:31:20Z")).ToNot(Equal(seed))
 		})
 	})
 })
 
-var _ = Describe("#IsAppHealthy", func() {
+var _ = Describe("#CheckHealth", func() {
+	var (
+		settings     sharedModel.InstanceSettings
+		appInstances map[string]cfclient.AppInstance
+	)
+
+	BeforeEach(func() {
+		settings = sharedModel.NewInstanceSettings("1")
+		appInstances = map[string]cfclient.AppInstance{
+			"0": {State: "RUNNING"},
+			"1": {State: "RUNNING"},
+		}
+	})
+
 	Context("When all app instances are running", func() {
 		It("returns true", func() {
-			appInstances := make(map[string]cfclient.AppInstance)
-			appInstances["0"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["1"] = cfclient.AppInstance{State: "RUNNING"}
-			Expect(utils.IsAppHealthy(appInstances)).To(BeTrue())
+			healthy, reason := utils.CheckHealth(settings, appInstances)
+			Expect(healthy).To(BeTrue())
+			Expect(reason).To(BeEmpty())
 		})
 	})
 
 	Context("When not all app instances are running", func() {
-		It("returns false", func() {
-			appInstances := make(map[string]cfclient.AppInstance)
-			appInstances["0"] = cfclient.AppInstance{State: "RUNNING"}
+		BeforeEach(func() {
 			appInstances["1"] = cfclient.AppInstance{State: "STARTING"}
-			Expect(utils.IsAppHealthy(appInstances)).To(BeFalse())
+		})
+
+		It("returns false with the reason", func() {
+			healthy, reason := utils.CheckHealth(settings, appInstances)
+			Expect(healthy).To(BeFalse())
+			Expect(reason).To(Equal("1 of 2 instances are healthy, fewer than the minimum of 100 percent"))
+		})
+
+		Context("and the minimum healthy percentage allows it", func() {
+			It("returns true", func() {
+				settings.MinHealthyPercent = 50
+				healthy, _ := utils.CheckHealth(settings, appInstances)
+				Expect(healthy).To(BeTrue())
+			})
+		})
+
+		Context("and the state counts as healthy", func() {
+			It("returns true", func() {
+				settings.HealthyStates = []string{"RUNNING", "STARTING"}
+				healthy, _ := utils.CheckHealth(settings, appInstances)
+				Expect(healthy).To(BeTrue())
+			})
 		})
 	})
-})
 
-var _ = Describe("#PickAppInstance", func() {
-	Context("When there are no app instances", func() {
-		It("Returns an app index of 0", func() {
-			appInstances := make(map[string]cfclient.AppInstance)
-			Expect(utils.PickAppInstance(appInstances)).To(Equal(0))
+	Context("When the app has fewer instances than the minimum", func() {
+		It("returns false with the reason", func() {
+			settings.MinInstances = 3
+			healthy, reason := utils.CheckHealth(settings, appInstances)
+			Expect(healthy).To(BeFalse())
+			Expect(reason).To(Equal("app has 2 instances, fewer than the minimum of 3"))
 		})
 	})
+})
+
+var _ = Describe("#HealthyCount", func() {
+	It("counts the app instances in a healthy state", func() {
+		settings := sharedModel.NewInstanceSettings("1")
+		appInstances := map[string]cfclient.AppInstance{
+			"0": {State: "RUNNING"},
+			"1": {State: "CRASHED"},
+			"2": {State: "RUNNING"},
+		}
+		Expect(utils.HealthyCount(settings, appInstances)).To(Equal(2))
+		settings.HealthyStates = []string{"CRASHED"}
+		Expect(utils.HealthyCount(settings, appInstances)).To(Equal(1))
+	})
+})
 
-	Context("When there is 1 app instance", func() {
-		It("Returns an app index of 0", func() {
-			appInstances := make(map[string]cfclient.AppInstance)
-			appInstances["0"] = cfclient.AppInstance{State: "RUNNING"}
-			Expect(utils.PickAppInstance(appInstances)).To(Equal(0))
+var _ = Describe("#HealthyIndexes", func() {
+	It("returns the indexes of the healthy app instances in ascending order", func() {
+		settings := sharedModel.NewInstanceSettings("1")
+		appInstances := map[string]cfclient.AppInstance{
+			"2": {State: "RUNNING"},
+			"1": {State: "CRASHED"},
+			"0": {State: "RUNNING"},
+		}
+		Expect(utils.HealthyIndexes(settings, appInstances)).To(Equal([]int{0, 2}))
+	})
+})
+
+var _ = Describe("#RecoveredAfter", func() {
+	Context("When the instance restarted after the kill", func() {
+		It("returns the time it took to recover", func() {
+			recoverySeconds, recovered := utils.RecoveredAfter(model.AppStats{State: "RUNNING", Stats: model.InstanceStats{Uptime: 5}}, 20*time.Second)
+			Expect(recovered).To(BeTrue())
+			Expect(recoverySeconds).To(Equal(15.0))
 		})
 	})
 
-	Context("When there is 6 app instances", func() {
-		It("Returns an app index between 0 and 5", func() {
-			appInstances := make(map[string]cfclient.AppInstance)
-			appInstances["0"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["1"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["2"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["3"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["4"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["5"] = cfclient.AppInstance{State: "RUNNING"}
-			Expect(utils.PickAppInstance(appInstances)).To(BeNumerically(">=", 0))
-			Expect(utils.PickAppInstance(appInstances)).To(BeNumerically("<=", 5))
+	Context("When the instance is still the process that was killed", func() {
+		It("returns false", func() {
+			_, recovered := utils.RecoveredAfter(model.AppStats{State: "RUNNING", Stats: model.InstanceStats{Uptime: 3600}}, 20*time.Second)
+			Expect(recovered).To(BeFalse())
 		})
 	})
 
-	Context("When there is 10 app instances, starting at 10", func() {
-		It("Returns an integer value of the app index", func() {
-			appInstances := make(map[string]cfclient.AppInstance)
-			appInstances["10"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["11"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["12"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["13"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["14"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["15"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["16"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["17"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["18"] = cfclient.AppInstance{State: "RUNNING"}
-			appInstances["19"] = cfclient.AppInstance{State: "RUNNING"}
-			Expect(utils.PickAppInstance(appInstances)).To(BeNumerically(">=", 10))
-			Expect(utils.PickAppInstance(appInstances)).To(BeNumerically("<=", 19))
+	Context("When the instance is not running", func() {
+		It("returns false", func() {
+			_, recovered := utils.RecoveredAfter(model.AppStats{State: "STARTING"}, 20*time.Second)
+			Expect(recovered).To(BeFalse())
 		})
 	})
 })
 
+var _ = Describe("#InstanceIndexes", func() {
+	It("returns the numeric indexes in ascending order", func() {
+		appInstances := make(map[string]cfclient.AppInstance)
+		appInstances["10"] = cfclient.AppInstance{State: "RUNNING"}
+		appInstances["2"] = cfclient.AppInstance{State: "RUNNING"}
+		appInstances["not_an_index"] = cfclient.AppInstance{State: "RUNNING"}
+		Expect(utils.InstanceIndexes(appInstances)).To(Equal([]int{2, 10}))
+	})
+})
+
 var _ = Describe("#GetBoundApps", func() {
 	Context("when services instances can be fetched", func() {
 		Context("and service bindings can be fetched", func() {
@@ -114,23 +202,209 @@ var _ = Describe("#GetBoundApps", func() {
 				instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
 					AddRow("1", "example.com/1", "1", 0.2, 5).
 					AddRow("2", "example.com/2", "1", 0.4, 10).
-					AddRow("3", "example.com/3", "1", 0, 10)
+					AddRow("3", "example.com/3", "1", 0, 10).
+					AddRow("5", "example.com/5", "1", 0, 0)
 
 				bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
 					AddRow("1", "1", "1", "1", "2014-11-12T10:31:20Z").
 					AddRow("2", "2", "1", "2", "2014-11-12T10:34:20Z").
 					AddRow("3", "3", "1", "2", "").
 					AddRow("4", "4", "1", "3", "2014-11-12T10:34:20Z").
-					AddRow("5", "5", "1", "4", "2014-11-12T10:34:20Z")
+					AddRow("5", "5", "1", "4", "2014-11-12T10:34:20Z").
+					AddRow("6", "6", "1", "5", "2014-11-12T10:34:20Z")
+
+				settingsRows := sqlmock.NewRows(sharedUtils.InstanceSettingsColumns).
+					AddRow("1", 42, "oldest", 0, 2, 0, 1, 1, 100, "RUNNING", "scale", 60, 75, nil, 5, 30).
+					AddRow("5", 42, "oldest", 0, 2, 0, 1, 1, 100, "RUNNING", "scale", 60, 75, 240, nil, nil)
 
 				mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
 				mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
-
+				probeRows := sqlmock.NewRows([]string{"id", "serviceInstanceID", "url", "expectedStatus", "maxLatencyMs", "bodyMatch"}).
+					AddRow(1, "2", "https://app-2.example.com/health", 200, 500, "UP")
+				pausedRows := sqlmock.NewRows([]string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}).
+					AddRow("3", "2", "2", "2014-11-12T10:31:20Z", "app crashed 3 times")
+
+				mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(settingsRows)
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(probeRows)
+				mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(pausedRows)
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnRows(sqlmock.NewRows([]string{"serviceInstanceID", "actions"}).AddRow("1", "kill:70,restart:30"))
+				lastChange := sharedModel.ProbabilityChange{ID: 3, ServiceInstanceID: "1", AppID: "1", Kind: sharedModel.ProbabilityRaised, Probability: 0.15,
+					Reason: "instance 0 recovered in 12 seconds", ChangedAt: "2014-11-12T10:31:20Z"}
+				mock.ExpectQuery("^SELECT (.+) FROM probability_changes").WillReturnRows(sqlmock.NewRows([]string{"id", "serviceInstanceID", "appID", "kind", "probability", "reason", "changedAt"}).
+					AddRow(3, "1", "1", "raised", 0.15, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z"))
+				replay := sharedModel.Replay{ServiceBindingID: "2", ServiceInstanceID: "2", AppID: "2", EventID: 7, Seed: -4237981, RequestedAt: "2014-11-12T10:35:20Z"}
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_replays$").WillReturnRows(sqlmock.NewRows([]string{"serviceBindingID", "serviceInstanceID", "appID", "eventID", "seed", "requestedAt"}).
+					AddRow("2", "2", "2", 7, -4237981, "2014-11-12T10:35:20Z"))
+
+				probes := []sharedModel.Probe{{ID: 1, ServiceInstanceID: "2", URL: "https://app-2.example.com/health", ExpectedStatus: 200, MaxLatencyMs: 500, BodyMatch: "UP"}}
 				services := utils.GetBoundApps(db)
-				Expect(services).To(HaveLen(3))
-				Expect(services).To(ContainElement(model.Service{AppID: "1", LastProcessed: "2014-11-12T10:31:20Z", Probability: 0.2, Frequency: 5}))
-				Expect(services).To(ContainElement(model.Service{AppID: "2", LastProcessed: "2014-11-12T10:34:20Z", Probability: 0.4, Frequency: 10}))
-				Expect(services).To(ContainElement(model.Service{AppID: "3", LastProcessed: "", Probability: 0.4, Frequency: 10}))
+				Expect(services).To(HaveLen(4))
+				Expect(services).To(ContainElement(model.Service{AppID: "1", LastProcessed: "2014-11-12T10:31:20Z", Probability: 0.2, Frequency: 5,
+					ServiceInstanceID: "1", ServiceBindingID: "1", Settings: sharedModel.InstanceSettings{ServiceInstanceID: "1", Seed: 42, Strategy: sharedModel.StrategyOldest, KillCount: 2, MinHealthy: 1,
+						MinInstances: 1, MinHealthyPercent: 100, HealthyStates: []string{"RUNNING"}, Action: sharedModel.ActionScale, ActionDelay: 60, Intensity: 75,
+						AdaptiveStep: 5, MaxRecoverySeconds: 30},
+					Profile: []sharedModel.WeightedAction{{Action: "kill", Weight: 70}, {Action: "restart", Weight: 30}}, LastChange: lastChange}))
+				Expect(services).To(ContainElement(model.Service{AppID: "2", LastProcessed: "2014-11-12T10:34:20Z", Probability: 0.4, Frequency: 10,
+					ServiceInstanceID: "2", ServiceBindingID: "2", Settings: sharedModel.NewInstanceSettings("2"), Probes: probes, Replay: replay}))
+				Expect(services).To(ContainElement(model.Service{AppID: "3", LastProcessed: "", Probability: 0.4, Frequency: 10,
+					ServiceInstanceID: "2", ServiceBindingID: "3", Settings: sharedModel.NewInstanceSettings("2"), Probes: probes, Paused: true}))
+				Expect(services).To(ContainElement(model.Service{AppID: "6", LastProcessed: "2014-11-12T10:34:20Z", Probability: 0, Frequency: 0,
+					ServiceInstanceID: "5", ServiceBindingID: "6", Settings: sharedModel.InstanceSettings{ServiceInstanceID: "5", Seed: 42, Strategy: sharedModel.StrategyOldest, KillCount: 2, MinHealthy: 1,
+						MinInstances: 1, MinHealthyPercent: 100, HealthyStates: []string{"RUNNING"}, Action: sharedModel.ActionScale, ActionDelay: 60, Intensity: 75, MTBFMinutes: 240,
+						MaxRecoverySeconds: 60}}))
+			})
+
+			Context("and the replays cannot be fetched", func() {
+				It("returns an empty services object", func() {
+					db, mock, err := sqlmock.New()
+					if err != nil {
+						fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+						os.Exit(1)
+					}
+					defer db.Close()
+
+					instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+						AddRow("1", "example.com/1", "1", 0.2, 5)
+					bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+						AddRow("1", "1", "1", "1", "2014-11-12T10:31:20Z")
+
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(sqlmock.NewRows([]string{"id", "serviceInstanceID", "url", "expectedStatus", "maxLatencyMs", "bodyMatch"}))
+					mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(sqlmock.NewRows([]string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnRows(sqlmock.NewRows([]string{"serviceInstanceID", "actions"}))
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes").WillReturnRows(sqlmock.NewRows([]string{"id", "serviceInstanceID", "appID", "kind", "probability", "reason", "changedAt"}))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_replays$").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+
+					services := utils.GetBoundApps(db)
+					Expect(services).To(HaveLen(0))
+				})
+			})
+
+			Context("and the last probability changes cannot be fetched", func() {
+				It("returns an empty services object", func() {
+					db, mock, err := sqlmock.New()
+					if err != nil {
+						fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+						os.Exit(1)
+					}
+					defer db.Close()
+
+					instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+						AddRow("1", "example.com/1", "1", 0.2, 5)
+					bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+						AddRow("1", "1", "1", "1", "2014-11-12T10:31:20Z")
+
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(sqlmock.NewRows([]string{"id", "serviceInstanceID", "url", "expectedStatus", "maxLatencyMs", "bodyMatch"}))
+					mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(sqlmock.NewRows([]string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnRows(sqlmock.NewRows([]string{"serviceInstanceID", "actions"}))
+					mock.ExpectQuery("^SELECT (.+) FROM probability_changes").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+
+					services := utils.GetBoundApps(db)
+					Expect(services).To(HaveLen(0))
+				})
+			})
+
+			Context("and profiles cannot be fetched", func() {
+				It("returns an empty services object", func() {
+					db, mock, err := sqlmock.New()
+					if err != nil {
+						fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+						os.Exit(1)
+					}
+					defer db.Close()
+
+					instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+						AddRow("1", "example.com/1", "1", 0.2, 5)
+					bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+						AddRow("1", "1", "1", "1", "2014-11-12T10:31:20Z")
+
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(sqlmock.NewRows([]string{"id", "serviceInstanceID", "url", "expectedStatus", "maxLatencyMs", "bodyMatch"}))
+					mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(sqlmock.NewRows([]string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+
+					services := utils.GetBoundApps(db)
+					Expect(services).To(HaveLen(0))
+				})
+			})
+
+			Context("and paused bindings cannot be fetched", func() {
+				It("returns an empty services object", func() {
+					db, mock, err := sqlmock.New()
+					if err != nil {
+						fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+						os.Exit(1)
+					}
+					defer db.Close()
+
+					instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+						AddRow("1", "example.com/1", "1", 0.2, 5)
+					bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+						AddRow("1", "1", "1", "1", "2014-11-12T10:31:20Z")
+
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnRows(sqlmock.NewRows([]string{"id", "serviceInstanceID", "url", "expectedStatus", "maxLatencyMs", "bodyMatch"}))
+					mock.ExpectQuery("^SELECT (.+) FROM paused_bindings").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+
+					services := utils.GetBoundApps(db)
+					Expect(services).To(HaveLen(0))
+				})
+			})
+
+			Context("and probes cannot be fetched", func() {
+				It("returns an empty services object", func() {
+					db, mock, err := sqlmock.New()
+					if err != nil {
+						fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+						os.Exit(1)
+					}
+					defer db.Close()
+
+					instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+						AddRow("1", "example.com/1", "1", 0.2, 5)
+					bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+						AddRow("1", "1", "1", "1", "2014-11-12T10:31:20Z")
+
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+					mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+
+					services := utils.GetBoundApps(db)
+					Expect(services).To(HaveLen(0))
+				})
+			})
+
+			Context("and instance settings cannot be fetched", func() {
+				It("returns an empty services object", func() {
+					db, mock, err := sqlmock.New()
+					if err != nil {
+						fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+						os.Exit(1)
+					}
+					defer db.Close()
+
+					instanceRows := sqlmock.NewRows([]string{"id", "dashboardURL", "planID", "probability", "frequency"}).
+						AddRow("1", "example.com/1", "1", 0.2, 5)
+					bindingRows := sqlmock.NewRows([]string{"id", "appID", "servicePlanID", "serviceInstanceID", "lastProcessed"}).
+						AddRow("1", "1", "1", "1", "2014-11-12T10:31:20Z")
+
+					mock.ExpectQuery("^SELECT (.+) FROM service_instances$").WillReturnRows(instanceRows)
+					mock.ExpectQuery("^SELECT (.+) FROM service_bindings$").WillReturnRows(bindingRows)
+					mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnError(fmt.Errorf("An error has occurred: %s", "DB error"))
+
+					services := utils.GetBoundApps(db)
+					Expect(services).To(HaveLen(0))
+				})
 			})
 		})
 
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/route_service/proxy"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultRefreshSeconds = 10
+
+var (
+	dbConnectionString string
+	err                error
+	refresh            time.Duration
+)
+
+func init() {
+	dbConnectionString, err = sharedUtils.GetDBConnectionDetails()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	refreshSeconds, err := strconv.Atoi(os.Getenv("REFRESH_INTERVAL"))
+	if err != nil || refreshSeconds < 0 {
+		refreshSeconds = defaultRefreshSeconds
+	}
+	refresh = time.Duration(refreshSeconds) * time.Second
+	fmt.Println("Config loaded:")
+	fmt.Println("RefreshInterval: ", refresh)
+}
+
+func logError(err error) bool {
+	if err != nil {
+		fmt.Println("An error has occured")
+		fmt.Println(err.Error())
+		return true
+	}
+	return false
+}
+
+func main() {
+	db, err := sql.Open("mysql", dbConnectionString)
+	if err != nil {
+		logError(err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	for _, setup := range []func(*sql.DB) error{sharedUtils.SetupRouteFaultsDB, sharedUtils.SetupHaltDB} {
+		err = setup(db)
+		if err != nil {
+			logError(err)
+			os.Exit(1)
+		}
+	}
+
+	err = http.ListenAndServe(":"+os.Getenv("PORT"), proxy.CreateProxy(db, refresh))
+	logError(err)
+}
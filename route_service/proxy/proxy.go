@@ -0,0 +1,179 @@
+package proxy
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	"math/rand"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ForwardedURLHeader - the header the Cloud Foundry router sets to the URL a request was originally sent to
+	ForwardedURLHeader = "X-CF-Forwarded-Url"
+	// SignatureHeader - the header the Cloud Foundry router signs the forwarded URL of a request in, which it checks when
+	// the request comes back through it
+	SignatureHeader = "X-CF-Proxy-Signature"
+	// MetadataHeader - the header the Cloud Foundry router sets to the metadata it needs to check the signature
+	MetadataHeader = "X-CF-Proxy-Metadata"
+)
+
+// Proxy struct - a route service injecting the route faults of a service instance into the traffic it forwards
+type Proxy struct {
+	DB        *sql.DB
+	Refresh   time.Duration
+	Transport http.RoundTripper
+	mutex     sync.Mutex
+	rng       *rand.Rand
+	cache     map[string]cachedFaults
+}
+
+type cachedFaults struct {
+	faults  sharedModel.RouteFaults
+	expires time.Time
+}
+
+// CreateProxy - returns a proxy reading the route faults of each service instance from the DB at most once per refresh
+func CreateProxy(db *sql.DB, refresh time.Duration) *Proxy {
+	return &Proxy{
+		DB:      db,
+		Refresh: refresh,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		cache:   make(map[string]cachedFaults),
+	}
+}
+
+// ServeHTTP - forwards a request to the URL it was originally sent to, injecting the route faults of the service instance in the path.
+// Only requests sent by the Cloud Foundry router, carrying its signature, are forwarded, so the proxy cannot be used to reach any URL.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	forwardedURL, err := url.Parse(r.Header.Get(ForwardedURLHeader))
+	if err != nil || forwardedURL.Host == "" {
+		http.Error(w, fmt.Sprintf("A valid %s header is required", ForwardedURLHeader), http.StatusBadRequest)
+		return
+	}
+	for _, header := range []string{SignatureHeader, MetadataHeader} {
+		if r.Header.Get(header) == "" {
+			http.Error(w, fmt.Sprintf("A %s header is required", header), http.StatusBadRequest)
+			return
+		}
+	}
+
+	faults, err := p.routeFaults(InstanceID(r.URL.Path))
+	if err != nil {
+		// traffic is forwarded untouched rather than failed when the faults cannot be read
+		fmt.Println("Could not load route faults, forwarding without faults")
+		fmt.Println(err.Error())
+	}
+
+	if p.roll(faults.DropPercent) {
+		drop(w)
+		return
+	}
+	if p.roll(faults.ErrorPercent) {
+		http.Error(w, "Fault injected by chaos-galago", faults.ErrorStatus)
+		return
+	}
+	if p.roll(faults.LatencyPercent) {
+		time.Sleep(time.Duration(faults.LatencyMs) * time.Millisecond)
+	}
+	if faults.BandwidthKBps > 0 {
+		w = &throttledWriter{ResponseWriter: w, bytesPerSecond: faults.BandwidthKBps * 1024}
+	}
+
+	reverseProxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL = forwardedURL
+			req.Host = forwardedURL.Host
+		},
+		Transport: p.Transport,
+	}
+	reverseProxy.ServeHTTP(w, r)
+}
+
+// InstanceID - returns the service instance id from the first segment of a route service URL path
+func InstanceID(path string) string {
+	return strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+}
+
+func (p *Proxy) routeFaults(serviceInstanceID string) (sharedModel.RouteFaults, error) {
+	noFaults := sharedModel.RouteFaults{ServiceInstanceID: serviceInstanceID}
+	if serviceInstanceID == "" {
+		return noFaults, nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if cached, ok := p.cache[serviceInstanceID]; ok && time.Now().Before(cached.expires) {
+		return cached.faults, nil
+	}
+
+	halt, err := sharedUtils.GetHalt(p.DB)
+	if err != nil {
+		return noFaults, err
+	}
+	faults := noFaults
+	if !halt.Halted {
+		faults, err = sharedUtils.GetRouteFaults(p.DB, serviceInstanceID)
+		if err != nil {
+			return noFaults, err
+		}
+	}
+	p.cache[serviceInstanceID] = cachedFaults{faults: faults, expires: time.Now().Add(p.Refresh)}
+	return faults, nil
+}
+
+func (p *Proxy) roll(percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.rng.Intn(100) < percent
+}
+
+func drop(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Connection dropped by chaos-galago", http.StatusBadGateway)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
+
+// throttledWriter - writes a response at no more than a number of bytes per second
+type throttledWriter struct {
+	http.ResponseWriter
+	bytesPerSecond int
+}
+
+func (t *throttledWriter) Write(data []byte) (int, error) {
+	chunkSize := t.bytesPerSecond / 10
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	var written int
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+		n, err := t.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		time.Sleep(time.Duration(n) * time.Second / time.Duration(t.bytesPerSecond))
+		data = data[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,184 @@
+package proxy_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/route_service/proxy"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = Describe("Proxy", func() {
+	var (
+		db          *sql.DB
+		mock        sqlmock.Sqlmock
+		err         error
+		app         *httptest.Server
+		routeServer *httptest.Server
+		received    *http.Request
+		haltNames   = []string{"halted", "setBy", "reason", "setAt"}
+		faultNames  = []string{"latencyMs", "latencyPercent", "errorStatus", "errorPercent", "dropPercent", "bandwidthKBps"}
+	)
+
+	expectFaults := func(values ...driver.Value) {
+		mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltNames))
+		mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=\\?$").WithArgs("instance-1").
+			WillReturnRows(sqlmock.NewRows(faultNames).AddRow(values...))
+	}
+
+	send := func() (*http.Response, error) {
+		request, _ := http.NewRequest("GET", routeServer.URL+"/instance-1", nil)
+		request.Header.Set(proxy.ForwardedURLHeader, app.URL+"/hello?name=galago")
+		request.Header.Set(proxy.SignatureHeader, "signature")
+		request.Header.Set(proxy.MetadataHeader, "metadata")
+		return http.DefaultClient.Do(request)
+	}
+
+	BeforeEach(func() {
+		received = nil
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+		}
+		app = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			fmt.Fprint(w, "hello galago")
+		}))
+		routeServer = httptest.NewServer(proxy.CreateProxy(db, 0))
+	})
+
+	AfterEach(func() {
+		routeServer.Close()
+		app.Close()
+		db.Close()
+	})
+
+	Describe("#InstanceID", func() {
+		It("returns the first path segment", func() {
+			Expect(proxy.InstanceID("/instance-1/extra")).To(Equal("instance-1"))
+			Expect(proxy.InstanceID("/instance-1")).To(Equal("instance-1"))
+			Expect(proxy.InstanceID("/")).To(Equal(""))
+		})
+	})
+
+	Describe("#ServeHTTP", func() {
+		Context("When the forwarded URL header is missing", func() {
+			It("returns a 400", func() {
+				response, err := http.Get(routeServer.URL + "/instance-1")
+				Expect(err).To(BeNil())
+				Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("When the request was not sent by the Cloud Foundry router", func() {
+			It("returns a 400 without calling the app", func() {
+				for _, header := range []string{"X-CF-Proxy-Signature", "X-CF-Proxy-Metadata"} {
+					request, _ := http.NewRequest("GET", routeServer.URL+"/instance-1", nil)
+					request.Header.Set(proxy.ForwardedURLHeader, app.URL+"/hello?name=galago")
+					request.Header.Set(header, "value")
+					response, err := http.DefaultClient.Do(request)
+					Expect(err).To(BeNil())
+					Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+				}
+				Expect(received).To(BeNil())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When no faults are set", func() {
+			It("forwards the request to the app with the route service headers", func() {
+				expectFaults(0, 0, 0, 0, 0, 0)
+				response, err := send()
+				Expect(err).To(BeNil())
+				body, _ := ioutil.ReadAll(response.Body)
+				Expect(string(body)).To(Equal("hello galago"))
+				Expect(received.URL.String()).To(Equal("/hello?name=galago"))
+				Expect(received.Header.Get("X-CF-Proxy-Signature")).To(Equal("signature"))
+				Expect(received.Header.Get("X-CF-Proxy-Metadata")).To(Equal("metadata"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When every request is failed with a status", func() {
+			It("returns the status without calling the app", func() {
+				expectFaults(0, 0, 503, 100, 0, 0)
+				response, err := send()
+				Expect(err).To(BeNil())
+				Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+				Expect(received).To(BeNil())
+			})
+		})
+
+		Context("When every connection is dropped", func() {
+			It("closes the connection without a response", func() {
+				expectFaults(0, 0, 0, 0, 100, 0)
+				_, err := send()
+				Expect(err).ToNot(BeNil())
+				Expect(received).To(BeNil())
+			})
+		})
+
+		Context("When every request is delayed", func() {
+			It("forwards the request after the latency", func() {
+				expectFaults(200, 100, 0, 0, 0, 0)
+				start := time.Now()
+				response, err := send()
+				Expect(err).To(BeNil())
+				Expect(response.StatusCode).To(Equal(http.StatusOK))
+				Expect(time.Since(start)).To(BeNumerically(">=", 200*time.Millisecond))
+			})
+		})
+
+		Context("When the bandwidth is limited", func() {
+			It("slows down the response", func() {
+				expectFaults(0, 0, 0, 0, 0, 1)
+				app.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write(make([]byte, 512))
+				})
+				start := time.Now()
+				response, err := send()
+				Expect(err).To(BeNil())
+				body, _ := ioutil.ReadAll(response.Body)
+				Expect(body).To(HaveLen(512))
+				Expect(time.Since(start)).To(BeNumerically(">=", 400*time.Millisecond))
+			})
+		})
+
+		Context("When chaos is halted", func() {
+			It("forwards the request without faults", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltNames).AddRow(true, "operator", "incident", "2014-11-12T10:31:20Z"))
+				response, err := send()
+				Expect(err).To(BeNil())
+				Expect(response.StatusCode).To(Equal(http.StatusOK))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the faults cannot be loaded", func() {
+			It("forwards the request without faults", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				response, err := send()
+				Expect(err).To(BeNil())
+				Expect(response.StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("When the faults were loaded within the refresh interval", func() {
+			It("does not read them again", func() {
+				routeServer.Config.Handler = proxy.CreateProxy(db, time.Minute)
+				expectFaults(0, 0, 0, 0, 0, 0)
+				for i := 0; i < 2; i++ {
+					_, err := send()
+					Expect(err).To(BeNil())
+				}
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,19 @@
+package sharedModel
+
+// Kinds of change to the effective probability of adaptive chaos
+const (
+	ProbabilityRaised    = "raised"
+	ProbabilityBackedOff = "backed-off"
+)
+
+// ProbabilityChange struct - a change to the effective probability of a service instance running adaptive chaos, made after a run
+// against one of its apps
+type ProbabilityChange struct {
+	ID                int64   `json:"id"`
+	ServiceInstanceID string  `json:"service_instance_id"`
+	AppID             string  `json:"app_guid"`
+	Kind              string  `json:"kind"`
+	Probability       float64 `json:"probability"`
+	Reason            string  `json:"reason"`
+	ChangedAt         string  `json:"changed_at"`
+}
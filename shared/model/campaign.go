@@ -0,0 +1,61 @@
+package sharedModel
+
+// Campaign states
+const (
+	CampaignScheduled = "scheduled"
+	CampaignActive    = "active"
+	CampaignEnded     = "ended"
+)
+
+// Campaign struct - a time-bounded game day, running chaos at its own probability, frequency and actions against the apps of
+// its target service instances and spaces between its start and end
+type Campaign struct {
+	ID                 int64           `json:"id"`
+	Name               string          `json:"name"`
+	StartAt            string          `json:"start_at"`
+	EndAt              string          `json:"end_at"`
+	ServiceInstanceIDs []string        `json:"service_instance_ids"`
+	SpaceIDs           []string        `json:"space_guids"`
+	Probability        float64         `json:"probability"`
+	Frequency          int             `json:"frequency"`
+	Actions            []string        `json:"actions"`
+	State              string          `json:"state"`
+	Report             *CampaignReport `json:"report,omitempty"`
+}
+
+// CampaignReport struct - the summary of the chaos run during a campaign, generated when it ends
+type CampaignReport struct {
+	GeneratedAt      string         `json:"generated_at"`
+	Apps             int            `json:"apps"`
+	Runs             int            `json:"runs"`
+	Succeeded        int            `json:"succeeded"`
+	Failed           int            `json:"failed"`
+	Skipped          int            `json:"skipped"`
+	Actions          map[string]int `json:"actions"`
+	Recovered        int            `json:"recovered"`
+	TimedOut         int            `json:"timed_out"`
+	MeanSeconds      float64        `json:"mttr_seconds"`
+	MaxSeconds       float64        `json:"max_recovery_seconds"`
+	HypothesisHeld   int            `json:"hypothesis_held"`
+	HypothesisFailed int            `json:"hypothesis_failed"`
+}
+
+// TargetsInstance - determines if a campaign targets the apps of a service instance
+func (c Campaign) TargetsInstance(serviceInstanceID string) bool {
+	for _, target := range c.ServiceInstanceIDs {
+		if serviceInstanceID == target {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetsSpace - determines if a campaign targets the apps of a space
+func (c Campaign) TargetsSpace(spaceID string) bool {
+	for _, target := range c.SpaceIDs {
+		if spaceID == target {
+			return true
+		}
+	}
+	return false
+}
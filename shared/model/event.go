@@ -0,0 +1,46 @@
+package sharedModel
+
+// Event outcomes
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeSkipped   = "skipped"
+	OutcomeUnknown   = "unknown"
+)
+
+// Recovery states of a killed instance
+const (
+	RecoveryRecovered = "recovered"
+	RecoveryTimedOut  = "timed-out"
+)
+
+// Event struct - a record of chaos run against an app
+type Event struct {
+	ID                int64   `json:"id"`
+	AppID             string  `json:"app_guid"`
+	ServiceInstanceID string  `json:"service_instance_id"`
+	ServiceBindingID  string  `json:"service_binding_id"`
+	CreatedAt         string  `json:"created_at"`
+	Seed              int64   `json:"seed"`
+	Action            string  `json:"action"`
+	InstanceIndex     string  `json:"instance_index"`
+	Outcome           string  `json:"outcome"`
+	Reason            string  `json:"reason"`
+	Recovery          string  `json:"recovery"`
+	RecoverySeconds   float64 `json:"recovery_seconds"`
+	Hypothesis        string  `json:"hypothesis"`
+	OrgID             string  `json:"org_guid"`
+	SpaceID           string  `json:"space_guid"`
+	Before            string  `json:"before_state"`
+	After             string  `json:"after_state"`
+}
+
+// RecoveryStats struct - time to recover statistics of the instances killed in an app
+type RecoveryStats struct {
+	AppID       string  `json:"app_guid"`
+	Kills       int     `json:"kills"`
+	Recovered   int     `json:"recovered"`
+	TimedOut    int     `json:"timed_out"`
+	MeanSeconds float64 `json:"mttr_seconds"`
+	MaxSeconds  float64 `json:"max_recovery_seconds"`
+}
@@ -0,0 +1,9 @@
+package sharedModel
+
+// Halt struct - the foundation wide kill switch, stopping every chaos action while halted
+type Halt struct {
+	Halted bool   `json:"halted"`
+	SetBy  string `json:"set_by"`
+	Reason string `json:"reason"`
+	SetAt  string `json:"set_at"`
+}
@@ -0,0 +1,172 @@
+package sharedModel
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Victim selection strategies
+const (
+	StrategyRandom        = "random"
+	StrategyOldest        = "oldest"
+	StrategyBusiestCPU    = "busiest-cpu"
+	StrategyBusiestMemory = "busiest-memory"
+	StrategyFixed         = "fixed"
+	StrategyNotFirst      = "not-first"
+)
+
+// Strategies - every victim selection strategy
+var Strategies = []string{StrategyRandom, StrategyOldest, StrategyBusiestCPU, StrategyBusiestMemory, StrategyFixed, StrategyNotFirst}
+
+// Chaos actions
+const (
+	ActionKill      = "kill"
+	ActionRestart   = "restart"
+	ActionStopStart = "stop-start"
+	ActionRestage   = "restage"
+	ActionScale     = "scale"
+	ActionCell      = "cell-outage"
+	ActionCPU       = "cpu-burn"
+	ActionMemory    = "memory-pressure"
+	ActionDisk      = "disk-fill"
+	ActionPartition = "network-partition"
+	ActionUnmap     = "route-unmap"
+	ActionBlackhole = "egress-blackhole"
+)
+
+// ActionPluginPrefix - prefixes the name of an action plugin to make the chaos action it takes
+const ActionPluginPrefix = "plugin:"
+
+var pluginName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Actions - every chaos action
+var Actions = []string{ActionKill, ActionRestart, ActionStopStart, ActionRestage, ActionScale, ActionCell, ActionCPU, ActionMemory, ActionDisk, ActionPartition, ActionUnmap, ActionBlackhole}
+
+// AppActions - the chaos actions disrupting every instance of an app rather than chosen victims
+var AppActions = []string{ActionRestart, ActionStopStart, ActionRestage, ActionBlackhole}
+
+// InstanceStates - the states a Cloud Foundry app instance can be in
+var InstanceStates = []string{"RUNNING", "STARTING", "CRASHED", "DOWN", "FLAPPING", "UNKNOWN"}
+
+// InstanceSettings struct - the optional chaos settings of a service instance
+type InstanceSettings struct {
+	ServiceInstanceID  string   `json:"service_instance_id"`
+	Seed               int64    `json:"seed"`
+	Strategy           string   `json:"strategy"`
+	StrategyIndex      int      `json:"strategy_index"`
+	KillCount          int      `json:"kill_count"`
+	KillPercent        int      `json:"kill_percent"`
+	MinHealthy         int      `json:"min_healthy"`
+	MinInstances       int      `json:"min_instances"`
+	MinHealthyPercent  int      `json:"min_healthy_percent"`
+	HealthyStates      []string `json:"healthy_states"`
+	Action             string   `json:"action"`
+	ActionDelay        int      `json:"action_delay"`
+	Intensity          int      `json:"intensity"`
+	MTBFMinutes        int      `json:"mtbf_minutes"`
+	AdaptiveStep       int      `json:"adaptive_step"`
+	MaxRecoverySeconds int      `json:"max_recovery_seconds"`
+}
+
+// NewInstanceSettings - returns the default settings for a service instance
+func NewInstanceSettings(serviceInstanceID string) InstanceSettings {
+	return InstanceSettings{
+		ServiceInstanceID:  serviceInstanceID,
+		Strategy:           StrategyRandom,
+		KillCount:          1,
+		MinHealthy:         1,
+		MinInstances:       1,
+		MinHealthyPercent:  100,
+		HealthyStates:      []string{"RUNNING"},
+		Action:             ActionKill,
+		ActionDelay:        30,
+		Intensity:          50,
+		MaxRecoverySeconds: 60,
+	}
+}
+
+// UsesMTBF - determines if chaos runs as a Poisson process with a mean time between failures rather than by probability
+func (s InstanceSettings) UsesMTBF() bool {
+	return s.MTBFMinutes > 0
+}
+
+// Adaptive - determines if chaos starts at a low probability and adapts it to the resilience of the apps. A mean time between
+// failures takes precedence.
+func (s InstanceSettings) Adaptive() bool {
+	return s.AdaptiveStep > 0 && !s.UsesMTBF()
+}
+
+// IsStrategy - determines if a victim selection strategy exists
+func IsStrategy(strategy string) bool {
+	for _, known := range Strategies {
+		if strategy == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAction - determines if a chaos action exists, plugin actions existing once they are named
+func IsAction(action string) bool {
+	for _, known := range Actions {
+		if action == known {
+			return true
+		}
+	}
+	return IsPluginAction(action)
+}
+
+// IsPluginAction - determines if a chaos action is taken by an action plugin, made of the prefix and a lower case name
+func IsPluginAction(action string) bool {
+	return strings.HasPrefix(action, ActionPluginPrefix) && pluginName.MatchString(PluginName(action))
+}
+
+// PluginName - returns the name of the plugin taking a plugin action
+func PluginName(action string) string {
+	return strings.TrimPrefix(action, ActionPluginPrefix)
+}
+
+// IsAppAction - determines if a chaos action disrupts every instance of an app rather than chosen victims
+func IsAppAction(action string) bool {
+	for _, known := range AppActions {
+		if action == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTaskAction - determines if a chaos action exhausts the resources of an app with a task rather than disrupting instances
+func IsTaskAction(action string) bool {
+	return action == ActionCPU || action == ActionMemory || action == ActionDisk
+}
+
+// IsNetworkAction - determines if a chaos action cuts the network of an app off rather than disrupting instances
+func IsNetworkAction(action string) bool {
+	return action == ActionPartition || action == ActionUnmap
+}
+
+// KeepsInstances - determines if a chaos action leaves every instance of an app running
+func KeepsInstances(action string) bool {
+	return IsTaskAction(action) || IsNetworkAction(action) || IsPluginAction(action)
+}
+
+// IsInstanceState - determines if an app instance state exists
+func IsInstanceState(state string) bool {
+	for _, known := range InstanceStates {
+		if state == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHealthyState - determines if the settings count an app instance state as healthy
+func (s InstanceSettings) IsHealthyState(state string) bool {
+	for _, healthy := range s.HealthyStates {
+		if state == healthy {
+			return true
+		}
+	}
+	return false
+}
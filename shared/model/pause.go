@@ -0,0 +1,10 @@
+package sharedModel
+
+// PausedBinding struct - a binding chaos is paused for after its app failed to recover or crash looped
+type PausedBinding struct {
+	ServiceBindingID  string `json:"service_binding_id"`
+	ServiceInstanceID string `json:"service_instance_id"`
+	AppID             string `json:"app_guid"`
+	PausedAt          string `json:"paused_at"`
+	Reason            string `json:"reason"`
+}
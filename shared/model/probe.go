@@ -0,0 +1,17 @@
+package sharedModel
+
+// Hypothesis results of the probes run after chaos
+const (
+	HypothesisHeld   = "held"
+	HypothesisFailed = "failed"
+)
+
+// Probe struct - an HTTP check of an app's steady state
+type Probe struct {
+	ID                int64  `json:"id"`
+	ServiceInstanceID string `json:"service_instance_id"`
+	URL               string `json:"url"`
+	ExpectedStatus    int    `json:"expected_status"`
+	MaxLatencyMs      int    `json:"max_latency_ms"`
+	BodyMatch         string `json:"body_match"`
+}
@@ -0,0 +1,13 @@
+package sharedModel
+
+// Profile struct - a weighted mix of chaos actions, one of which is sampled on every run in place of the configured action
+type Profile struct {
+	ServiceInstanceID string           `json:"service_instance_id"`
+	Actions           []WeightedAction `json:"actions"`
+}
+
+// WeightedAction struct - a chaos action and its share of the runs of a profile, relative to the other weights
+type WeightedAction struct {
+	Action string `json:"action"`
+	Weight int    `json:"weight"`
+}
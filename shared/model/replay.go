@@ -0,0 +1,11 @@
+package sharedModel
+
+// Replay struct - a recorded run of chaos to be replayed on the next run of its binding, drawn from the seed of its event
+type Replay struct {
+	ServiceBindingID  string `json:"service_binding_id"`
+	ServiceInstanceID string `json:"service_instance_id"`
+	AppID             string `json:"app_guid"`
+	EventID           int64  `json:"event_id"`
+	Seed              int64  `json:"seed"`
+	RequestedAt       string `json:"requested_at"`
+}
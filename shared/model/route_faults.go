@@ -0,0 +1,12 @@
+package sharedModel
+
+// RouteFaults struct - the faults the route service injects into traffic for the routes bound to a service instance
+type RouteFaults struct {
+	ServiceInstanceID string `json:"service_instance_id"`
+	LatencyMs         int    `json:"latency_ms"`
+	LatencyPercent    int    `json:"latency_percent"`
+	ErrorStatus       int    `json:"error_status"`
+	ErrorPercent      int    `json:"error_percent"`
+	DropPercent       int    `json:"drop_percent"`
+	BandwidthKBps     int    `json:"bandwidth_kbps"`
+}
@@ -0,0 +1,24 @@
+package sharedModel
+
+// Scheduled change states
+const (
+	ChangePending   = "pending"
+	ChangeApplied   = "applied"
+	ChangeCancelled = "cancelled"
+)
+
+// ScheduledChange struct - a future change to the probability and frequency of a service instance, applied by the processor
+// once its time has come. A probability or frequency left out is kept as it is. The values a change replaces are recorded
+// when it is applied, and a revert of the change, given by RevertsID, sets them back.
+type ScheduledChange struct {
+	ID                  int64    `json:"id"`
+	ServiceInstanceID   string   `json:"service_instance_id"`
+	ApplyAt             string   `json:"apply_at"`
+	Probability         *float64 `json:"probability,omitempty"`
+	Frequency           *int     `json:"frequency,omitempty"`
+	State               string   `json:"state"`
+	AppliedAt           string   `json:"applied_at,omitempty"`
+	RevertsID           int64    `json:"reverts_id,omitempty"`
+	ReplacedProbability *float64 `json:"replaced_probability,omitempty"`
+	ReplacedFrequency   *int     `json:"replaced_frequency,omitempty"`
+}
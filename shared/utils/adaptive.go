@@ -0,0 +1,125 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"math"
+)
+
+// EffectiveProbability - returns the probability chaos runs at for a service instance. Adaptive chaos starts at one step and
+// follows its last change, never above the configured probability.
+func EffectiveProbability(settings sharedModel.InstanceSettings, configured float64, last sharedModel.ProbabilityChange) float64 {
+	if !settings.Adaptive() {
+		return configured
+	}
+	probability := adaptiveStep(settings)
+	if last.ID != 0 {
+		probability = last.Probability
+	}
+	return math.Min(probability, configured)
+}
+
+// RaiseProbability - returns an effective probability one step higher, capped at the configured probability
+func RaiseProbability(settings sharedModel.InstanceSettings, configured float64, current float64) float64 {
+	return math.Min(roundProbability(current+adaptiveStep(settings)), configured)
+}
+
+// BackOffProbability - returns half an effective probability, but no less than one step or the configured probability
+func BackOffProbability(settings sharedModel.InstanceSettings, configured float64, current float64) float64 {
+	return math.Min(math.Max(roundProbability(current/2), adaptiveStep(settings)), configured)
+}
+
+func adaptiveStep(settings sharedModel.InstanceSettings) float64 {
+	return float64(settings.AdaptiveStep) / 100
+}
+
+func roundProbability(probability float64) float64 {
+	return math.Floor(probability*10000+0.5) / 10000
+}
+
+// SetupProbabilityChangeDB - creates the probability_changes DB if it does not exist
+func SetupProbabilityChangeDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS probability_changes
+	(
+		id int NOT NULL AUTO_INCREMENT,
+		serviceInstanceID varchar(255),
+		appID varchar(255),
+		kind varchar(255),
+		probability double,
+		reason varchar(255),
+		changedAt varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RecordProbabilityChange - adds a row to probability_changes database
+func RecordProbabilityChange(db *sql.DB, change sharedModel.ProbabilityChange) error {
+	_, err := db.Exec("INSERT INTO probability_changes (serviceInstanceID, appID, kind, probability, reason, changedAt) VALUES (?, ?, ?, ?, ?, ?)",
+		change.ServiceInstanceID, change.AppID, change.Kind, change.Probability, change.Reason, change.ChangedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadLastProbabilityChanges - loads the last change of every service instance running adaptive chaos, keyed by service instance ID
+func ReadLastProbabilityChanges(db *sql.DB) (map[string]sharedModel.ProbabilityChange, error) {
+	changesMap := make(map[string]sharedModel.ProbabilityChange)
+
+	changes, err := queryProbabilityChanges(db, "SELECT id, serviceInstanceID, appID, kind, probability, reason, changedAt FROM probability_changes "+
+		"WHERE id IN (SELECT MAX(id) FROM probability_changes GROUP BY serviceInstanceID)")
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		changesMap[change.ServiceInstanceID] = change
+	}
+	return changesMap, nil
+}
+
+// GetProbabilityChanges - loads the most recent probability changes of a service instance, newest first
+func GetProbabilityChanges(db *sql.DB, serviceInstanceID string, limit int) ([]sharedModel.ProbabilityChange, error) {
+	return queryProbabilityChanges(db, "SELECT id, serviceInstanceID, appID, kind, probability, reason, changedAt FROM probability_changes "+
+		"WHERE serviceInstanceID=? ORDER BY id DESC LIMIT ?", serviceInstanceID, limit)
+}
+
+// DeleteProbabilityChanges - deletes every probability change of a service instance
+func DeleteProbabilityChanges(db *sql.DB, serviceInstanceID string) error {
+	_, err := db.Exec("DELETE FROM probability_changes WHERE serviceInstanceID=?", serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func queryProbabilityChanges(db *sql.DB, query string, args ...interface{}) ([]sharedModel.ProbabilityChange, error) {
+	changes := []sharedModel.ProbabilityChange{}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			change              sharedModel.ProbabilityChange
+			appID, kind, reason sql.NullString
+		)
+		err = rows.Scan(&change.ID, &change.ServiceInstanceID, &appID, &kind, &change.Probability, &reason, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		change.AppID = appID.String
+		change.Kind = kind.String
+		change.Reason = reason.String
+		changes = append(changes, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
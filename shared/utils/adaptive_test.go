@@ -0,0 +1,142 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Adaptive chaos", func() {
+	var (
+		db          *sql.DB
+		mock        sqlmock.Sqlmock
+		settings    sharedModel.InstanceSettings
+		changeNames = []string{"id", "serviceInstanceID", "appID", "kind", "probability", "reason", "changedAt"}
+		change      = sharedModel.ProbabilityChange{ID: 3, ServiceInstanceID: "1", AppID: "app-1", Kind: sharedModel.ProbabilityRaised, Probability: 0.15,
+			Reason: "instance 0 recovered in 12 seconds", ChangedAt: "2014-11-12T10:31:20Z"}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		settings = sharedModel.NewInstanceSettings("1")
+		settings.AdaptiveStep = 5
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#EffectiveProbability", func() {
+		It("starts at one step", func() {
+			Expect(sharedUtils.EffectiveProbability(settings, 0.5, sharedModel.ProbabilityChange{})).To(Equal(0.05))
+		})
+
+		It("follows the last change", func() {
+			Expect(sharedUtils.EffectiveProbability(settings, 0.5, change)).To(Equal(0.15))
+		})
+
+		It("never exceeds the configured probability", func() {
+			Expect(sharedUtils.EffectiveProbability(settings, 0.1, change)).To(Equal(0.1))
+		})
+
+		Context("When chaos is not adaptive", func() {
+			It("returns the configured probability", func() {
+				settings.AdaptiveStep = 0
+				Expect(sharedUtils.EffectiveProbability(settings, 0.5, change)).To(Equal(0.5))
+			})
+		})
+
+		Context("When a mean time between failures is set", func() {
+			It("returns the configured probability", func() {
+				settings.MTBFMinutes = 240
+				Expect(sharedUtils.EffectiveProbability(settings, 0.5, change)).To(Equal(0.5))
+			})
+		})
+	})
+
+	Describe("#RaiseProbability", func() {
+		It("adds a step", func() {
+			Expect(sharedUtils.RaiseProbability(settings, 0.5, 0.15)).To(Equal(0.2))
+		})
+
+		It("stops at the configured probability", func() {
+			Expect(sharedUtils.RaiseProbability(settings, 0.5, 0.48)).To(Equal(0.5))
+		})
+	})
+
+	Describe("#BackOffProbability", func() {
+		It("halves the probability", func() {
+			Expect(sharedUtils.BackOffProbability(settings, 0.5, 0.4)).To(Equal(0.2))
+		})
+
+		It("keeps at least one step", func() {
+			Expect(sharedUtils.BackOffProbability(settings, 0.5, 0.06)).To(Equal(0.05))
+		})
+	})
+
+	Describe("#SetupProbabilityChangeDB", func() {
+		It("creates the probability_changes table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS probability_changes.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.SetupProbabilityChangeDB(db)).To(BeNil())
+		})
+	})
+
+	Describe("#RecordProbabilityChange", func() {
+		It("inserts the change", func() {
+			mock.ExpectExec("INSERT INTO probability_changes .*").
+				WithArgs("1", "app-1", "raised", 0.15, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z").
+				WillReturnResult(sqlmock.NewResult(3, 1))
+			Expect(sharedUtils.RecordProbabilityChange(db, change)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO probability_changes .*").WillReturnError(fmt.Errorf("An error has occured: %s", "INSERT error"))
+				Expect(sharedUtils.RecordProbabilityChange(db, change)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#ReadLastProbabilityChanges", func() {
+		It("returns the last change of every service instance", func() {
+			rows := sqlmock.NewRows(changeNames).AddRow(3, "1", "app-1", "raised", 0.15, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("SELECT .* FROM probability_changes WHERE id IN \\(SELECT MAX\\(id\\) .*").WillReturnRows(rows)
+			Expect(sharedUtils.ReadLastProbabilityChanges(db)).To(Equal(map[string]sharedModel.ProbabilityChange{"1": change}))
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("SELECT .* FROM probability_changes .*").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.ReadLastProbabilityChanges(db)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetProbabilityChanges", func() {
+		It("returns the changes of the service instance", func() {
+			rows := sqlmock.NewRows(changeNames).AddRow(3, "1", "app-1", "raised", 0.15, "instance 0 recovered in 12 seconds", "2014-11-12T10:31:20Z")
+			mock.ExpectQuery("SELECT .* FROM probability_changes WHERE serviceInstanceID=\\? ORDER BY id DESC LIMIT \\?").WithArgs("1", 20).WillReturnRows(rows)
+			Expect(sharedUtils.GetProbabilityChanges(db, "1", 20)).To(Equal([]sharedModel.ProbabilityChange{change}))
+		})
+	})
+
+	Describe("#DeleteProbabilityChanges", func() {
+		It("deletes the changes of the service instance", func() {
+			mock.ExpectExec("DELETE FROM probability_changes WHERE serviceInstanceID=\\?").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 3))
+			Expect(sharedUtils.DeleteProbabilityChanges(db, "1")).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,217 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"strings"
+)
+
+// CampaignColumns - the columns of campaigns, in the order they are read
+var CampaignColumns = []string{"id", "name", "startAt", "endAt", "serviceInstanceIDs", "spaceIDs", "probability", "frequency", "actions", "state", "report"}
+
+// SetupCampaignDB - creates the campaigns DB, and the campaign_apps DB of the apps that took part in each, if they do not exist
+func SetupCampaignDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS campaigns
+	(
+		id int NOT NULL AUTO_INCREMENT,
+		name varchar(255),
+		startAt varchar(255),
+		endAt varchar(255),
+		serviceInstanceIDs text,
+		spaceIDs text,
+		probability double,
+		frequency int,
+		actions text,
+		state varchar(255),
+		report text,
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS campaign_apps
+	(
+		campaignID int NOT NULL,
+		appID varchar(255) NOT NULL,
+		serviceInstanceID varchar(255),
+		PRIMARY KEY (campaignID, appID)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddCampaign - adds a row to campaigns database, returning the id of the campaign
+func AddCampaign(db *sql.DB, campaign sharedModel.Campaign) (int64, error) {
+	result, err := db.Exec("INSERT INTO campaigns (name, startAt, endAt, serviceInstanceIDs, spaceIDs, probability, frequency, actions, state) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		campaign.Name, campaign.StartAt, campaign.EndAt, strings.Join(campaign.ServiceInstanceIDs, ","), strings.Join(campaign.SpaceIDs, ","),
+		campaign.Probability, campaign.Frequency, strings.Join(campaign.Actions, ","), campaign.State)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetCampaigns - loads every campaign, newest first
+func GetCampaigns(db *sql.DB) ([]sharedModel.Campaign, error) {
+	return queryCampaigns(db, "SELECT "+strings.Join(CampaignColumns, ", ")+" FROM campaigns ORDER BY id DESC")
+}
+
+// GetCampaign - loads a campaign, returning sql.ErrNoRows if it does not exist
+func GetCampaign(db *sql.DB, id int64) (sharedModel.Campaign, error) {
+	campaigns, err := queryCampaigns(db, "SELECT "+strings.Join(CampaignColumns, ", ")+" FROM campaigns WHERE id=?", id)
+	if err != nil {
+		return sharedModel.Campaign{}, err
+	}
+	if len(campaigns) == 0 {
+		return sharedModel.Campaign{}, sql.ErrNoRows
+	}
+	return campaigns[0], nil
+}
+
+// ReadOpenCampaigns - loads the campaigns that have not ended, oldest first
+func ReadOpenCampaigns(db *sql.DB) ([]sharedModel.Campaign, error) {
+	return queryCampaigns(db, "SELECT "+strings.Join(CampaignColumns, ", ")+" FROM campaigns WHERE state<>? ORDER BY id", sharedModel.CampaignEnded)
+}
+
+// StartCampaign - marks a scheduled campaign active, returning false if it was not scheduled, so that only one processor
+// instance starts it
+func StartCampaign(db *sql.DB, id int64) (bool, error) {
+	return updateCampaign(db, "UPDATE campaigns SET state=? WHERE id=? AND state=?", sharedModel.CampaignActive, id, sharedModel.CampaignScheduled)
+}
+
+// EndCampaign - marks a campaign ended with its report, returning false if it had already ended, so that only one processor
+// instance reports on it
+func EndCampaign(db *sql.DB, id int64, report sharedModel.CampaignReport) (bool, error) {
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return false, err
+	}
+	return updateCampaign(db, "UPDATE campaigns SET state=?, report=? WHERE id=? AND state<>?", sharedModel.CampaignEnded, string(reportJSON), id, sharedModel.CampaignEnded)
+}
+
+// StopCampaign - brings the end of a campaign that has not ended forward to endAt, returning false if it had already ended
+func StopCampaign(db *sql.DB, id int64, endAt string) (bool, error) {
+	return updateCampaign(db, "UPDATE campaigns SET endAt=? WHERE id=? AND state<>? AND endAt>?", endAt, id, sharedModel.CampaignEnded, endAt)
+}
+
+// JoinCampaign - records that an app took part in a campaign
+func JoinCampaign(db *sql.DB, campaignID int64, serviceInstanceID string, appID string) error {
+	_, err := db.Exec("INSERT IGNORE INTO campaign_apps (campaignID, appID, serviceInstanceID) VALUES (?, ?, ?)", campaignID, appID, serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetCampaignEvents - loads the events of the apps that took part in a campaign between its start and end, oldest first
+func GetCampaignEvents(db *sql.DB, campaign sharedModel.Campaign) ([]sharedModel.Event, error) {
+	return queryEvents(db, "SELECT "+strings.Join(EventColumns, ", ")+" FROM chaos_events WHERE createdAt>=? AND createdAt<? "+
+		"AND appID IN (SELECT appID FROM campaign_apps WHERE campaignID=?) ORDER BY id", campaign.StartAt, campaign.EndAt, campaign.ID)
+}
+
+// BuildCampaignReport - summarises the events of a campaign
+func BuildCampaignReport(events []sharedModel.Event, generatedAt string) sharedModel.CampaignReport {
+	report := sharedModel.CampaignReport{GeneratedAt: generatedAt, Runs: len(events), Actions: make(map[string]int)}
+	apps := make(map[string]bool)
+	var recoverySeconds float64
+	for _, event := range events {
+		apps[event.AppID] = true
+		switch event.Outcome {
+		case sharedModel.OutcomeSucceeded:
+			report.Succeeded++
+			report.Actions[event.Action]++
+		case sharedModel.OutcomeFailed:
+			report.Failed++
+		case sharedModel.OutcomeSkipped:
+			report.Skipped++
+		}
+		switch event.Recovery {
+		case sharedModel.RecoveryRecovered:
+			report.Recovered++
+			recoverySeconds += event.RecoverySeconds
+			if event.RecoverySeconds > report.MaxSeconds {
+				report.MaxSeconds = event.RecoverySeconds
+			}
+		case sharedModel.RecoveryTimedOut:
+			report.TimedOut++
+		}
+		switch event.Hypothesis {
+		case sharedModel.HypothesisHeld:
+			report.HypothesisHeld++
+		case sharedModel.HypothesisFailed:
+			report.HypothesisFailed++
+		}
+	}
+	report.Apps = len(apps)
+	if report.Recovered > 0 {
+		report.MeanSeconds = recoverySeconds / float64(report.Recovered)
+	}
+	return report
+}
+
+func updateCampaign(db *sql.DB, query string, args ...interface{}) (bool, error) {
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+func queryCampaigns(db *sql.DB, query string, args ...interface{}) ([]sharedModel.Campaign, error) {
+	campaigns := []sharedModel.Campaign{}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			campaign                                           sharedModel.Campaign
+			name, serviceInstanceIDs, spaceIDs, actions, state sql.NullString
+			report                                             sql.NullString
+			probability                                        sql.NullFloat64
+			frequency                                          sql.NullInt64
+		)
+		err = rows.Scan(&campaign.ID, &name, &campaign.StartAt, &campaign.EndAt, &serviceInstanceIDs, &spaceIDs, &probability, &frequency,
+			&actions, &state, &report)
+		if err != nil {
+			return nil, err
+		}
+		campaign.Name = name.String
+		campaign.ServiceInstanceIDs = splitList(serviceInstanceIDs.String)
+		campaign.SpaceIDs = splitList(spaceIDs.String)
+		campaign.Probability = probability.Float64
+		campaign.Frequency = int(frequency.Int64)
+		campaign.Actions = splitList(actions.String)
+		campaign.State = state.String
+		if report.String != "" {
+			campaign.Report = &sharedModel.CampaignReport{}
+			if err = json.Unmarshal([]byte(report.String), campaign.Report); err != nil {
+				return nil, err
+			}
+		}
+		campaigns = append(campaigns, campaign)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+func splitList(list string) []string {
+	values := []string{}
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
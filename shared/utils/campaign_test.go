@@ -0,0 +1,124 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Campaigns", func() {
+	var (
+		db       *sql.DB
+		mock     sqlmock.Sqlmock
+		campaign = sharedModel.Campaign{ID: 4, Name: "game day", StartAt: "2014-11-12T10:00:00Z", EndAt: "2014-11-12T12:00:00Z",
+			ServiceInstanceIDs: []string{"1", "2"}, SpaceIDs: []string{"space-1"}, Probability: 1, Frequency: 5,
+			Actions: []string{"kill", "restart"}, State: sharedModel.CampaignScheduled}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#TargetsInstance", func() {
+		It("matches the target service instances only", func() {
+			Expect(campaign.TargetsInstance("2")).To(BeTrue())
+			Expect(campaign.TargetsInstance("3")).To(BeFalse())
+		})
+	})
+
+	Describe("#TargetsSpace", func() {
+		It("matches the target spaces only", func() {
+			Expect(campaign.TargetsSpace("space-1")).To(BeTrue())
+			Expect(campaign.TargetsSpace("space-2")).To(BeFalse())
+		})
+	})
+
+	Describe("#SetupCampaignDB", func() {
+		It("creates the campaigns and campaign_apps tables", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS campaigns").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS campaign_apps").WillReturnResult(sqlmock.NewResult(0, 0))
+			Expect(sharedUtils.SetupCampaignDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#AddCampaign", func() {
+		It("joins the lists and returns the id", func() {
+			mock.ExpectExec("INSERT INTO campaigns").WithArgs("game day", "2014-11-12T10:00:00Z", "2014-11-12T12:00:00Z", "1,2", "space-1", 1.0, 5,
+				"kill,restart", "scheduled").WillReturnResult(sqlmock.NewResult(4, 1))
+			id, err := sharedUtils.AddCampaign(db, campaign)
+			Expect(err).To(BeNil())
+			Expect(id).To(Equal(int64(4)))
+		})
+	})
+
+	Describe("#GetCampaign", func() {
+		It("loads the campaign and its report", func() {
+			rows := sqlmock.NewRows(sharedUtils.CampaignColumns).
+				AddRow(4, "game day", "2014-11-12T10:00:00Z", "2014-11-12T12:00:00Z", "1,2", "", 1.0, 5, "kill", "ended", `{"runs":3,"succeeded":2}`)
+			mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE id=").WithArgs(4).WillReturnRows(rows)
+			loaded, err := sharedUtils.GetCampaign(db, 4)
+			Expect(err).To(BeNil())
+			Expect(loaded.ServiceInstanceIDs).To(Equal([]string{"1", "2"}))
+			Expect(loaded.SpaceIDs).To(BeEmpty())
+			Expect(loaded.Actions).To(Equal([]string{"kill"}))
+			Expect(loaded.State).To(Equal(sharedModel.CampaignEnded))
+			Expect(*loaded.Report).To(Equal(sharedModel.CampaignReport{Runs: 3, Succeeded: 2}))
+		})
+
+		Context("When the campaign does not exist", func() {
+			It("returns sql.ErrNoRows", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM campaigns WHERE id=").WithArgs(4).WillReturnRows(sqlmock.NewRows(sharedUtils.CampaignColumns))
+				_, err := sharedUtils.GetCampaign(db, 4)
+				Expect(err).To(Equal(sql.ErrNoRows))
+			})
+		})
+	})
+
+	Describe("#StartCampaign", func() {
+		It("only starts a scheduled campaign", func() {
+			mock.ExpectExec("UPDATE campaigns SET state=\\? WHERE id=\\? AND state=\\?").WithArgs("active", 4, "scheduled").WillReturnResult(sqlmock.NewResult(0, 0))
+			started, err := sharedUtils.StartCampaign(db, 4)
+			Expect(err).To(BeNil())
+			Expect(started).To(BeFalse())
+		})
+	})
+
+	Describe("#EndCampaign", func() {
+		It("stores the report as JSON", func() {
+			mock.ExpectExec("UPDATE campaigns SET state=\\?, report=\\?").WithArgs("ended", sqlmock.AnyArg(), 4, "ended").WillReturnResult(sqlmock.NewResult(0, 1))
+			ended, err := sharedUtils.EndCampaign(db, 4, sharedModel.CampaignReport{Runs: 3})
+			Expect(err).To(BeNil())
+			Expect(ended).To(BeTrue())
+		})
+	})
+
+	Describe("#BuildCampaignReport", func() {
+		It("summarises the events", func() {
+			events := []sharedModel.Event{
+				{AppID: "app-1", Action: "kill", Outcome: "succeeded", Recovery: "recovered", RecoverySeconds: 10, Hypothesis: "held"},
+				{AppID: "app-1", Action: "kill", Outcome: "succeeded", Recovery: "recovered", RecoverySeconds: 30, Hypothesis: "failed"},
+				{AppID: "app-2", Action: "restart", Outcome: "succeeded", Recovery: "timed-out"},
+				{AppID: "app-2", Action: "kill", Outcome: "skipped"},
+				{AppID: "app-3", Action: "kill", Outcome: "failed"},
+			}
+			Expect(sharedUtils.BuildCampaignReport(events, "2014-11-12T12:00:00Z")).To(Equal(sharedModel.CampaignReport{GeneratedAt: "2014-11-12T12:00:00Z",
+				Apps: 3, Runs: 5, Succeeded: 3, Failed: 1, Skipped: 1, Actions: map[string]int{"kill": 2, "restart": 1}, Recovered: 2, TimedOut: 1,
+				MeanSeconds: 20, MaxSeconds: 30, HypothesisHeld: 1, HypothesisFailed: 1}))
+		})
+	})
+})
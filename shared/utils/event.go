@@ -0,0 +1,186 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"strings"
+)
+
+// EventColumns - the columns of chaos_events, in the order they are read
+var EventColumns = []string{"id", "appID", "serviceInstanceID", "serviceBindingID", "createdAt", "seed", "action", "instanceIndex",
+	"outcome", "reason", "recovery", "recoverySeconds", "hypothesis", "orgID", "spaceID", "beforeState", "afterState"}
+
+// maxReasonLength - the length of the reason columns, longer reasons such as errors holding whole Cloud Controller responses
+// are cut short so that MySQL strict mode does not reject the row
+const maxReasonLength = 255
+
+var eventMigrations = []Column{
+	{Name: "recovery", Definition: "varchar(255)"},
+	{Name: "recoverySeconds", Definition: "double"},
+	{Name: "hypothesis", Definition: "varchar(255)"},
+	{Name: "orgID", Definition: "varchar(255)"},
+	{Name: "spaceID", Definition: "varchar(255)"},
+	{Name: "beforeState", Definition: "varchar(255)"},
+	{Name: "afterState", Definition: "varchar(255)"},
+}
+
+// SetupEventDB - creates the chaos_events DB if it does not exist
+func SetupEventDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS chaos_events
+	(
+		id int NOT NULL AUTO_INCREMENT,
+		appID varchar(255),
+		serviceInstanceID varchar(255),
+		serviceBindingID varchar(255),
+		createdAt varchar(255),
+		seed bigint,
+		action varchar(255),
+		instanceIndex varchar(255),
+		outcome varchar(255),
+		reason varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return AddColumns(db, "chaos_events", eventMigrations)
+}
+
+// RecordEvent - adds a row to chaos_events database, returning the id of the event
+func RecordEvent(db *sql.DB, event sharedModel.Event) (int64, error) {
+	result, err := db.Exec("INSERT INTO chaos_events (appID, serviceInstanceID, serviceBindingID, createdAt, seed, action, instanceIndex, outcome, reason, orgID, spaceID, beforeState, afterState) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		event.AppID, event.ServiceInstanceID, event.ServiceBindingID, event.CreatedAt, event.Seed, event.Action, event.InstanceIndex, event.Outcome, truncateReason(event.Reason),
+		event.OrgID, event.SpaceID, event.Before, event.After)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateEventRecovery - records how an event's killed instance recovered
+func UpdateEventRecovery(db *sql.DB, id int64, recovery string, recoverySeconds float64) error {
+	_, err := db.Exec("UPDATE chaos_events SET recovery=?, recoverySeconds=? WHERE id=?", recovery, recoverySeconds, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateEventHypothesis - records whether the steady state hypothesis held after the chaos of an event
+func UpdateEventHypothesis(db *sql.DB, id int64, hypothesis string, reason string) error {
+	_, err := db.Exec("UPDATE chaos_events SET hypothesis=?, reason=? WHERE id=?", hypothesis, truncateReason(reason), id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateEventOutcome - records the outcome of an event that was unknown when it was recorded
+func UpdateEventOutcome(db *sql.DB, id int64, outcome string, reason string) error {
+	_, err := db.Exec("UPDATE chaos_events SET outcome=?, reason=? WHERE id=?", outcome, truncateReason(reason), id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetEvents - loads the most recent events of a service instance, newest first
+func GetEvents(db *sql.DB, serviceInstanceID string, limit int) ([]sharedModel.Event, error) {
+	return queryEvents(db, "SELECT "+strings.Join(EventColumns, ", ")+" FROM chaos_events WHERE serviceInstanceID=? ORDER BY id DESC LIMIT ?",
+		serviceInstanceID, limit)
+}
+
+// GetEvent - loads an event of a service instance, returning an empty event if there is none with the id
+func GetEvent(db *sql.DB, serviceInstanceID string, id int64) (sharedModel.Event, error) {
+	events, err := queryEvents(db, "SELECT "+strings.Join(EventColumns, ", ")+" FROM chaos_events WHERE serviceInstanceID=? AND id=?", serviceInstanceID, id)
+	if err != nil || len(events) == 0 {
+		return sharedModel.Event{}, err
+	}
+	return events[0], nil
+}
+
+// GetRecoveryStats - calculates the time to recover statistics of every app of a service instance
+func GetRecoveryStats(db *sql.DB, serviceInstanceID string) ([]sharedModel.RecoveryStats, error) {
+	stats := []sharedModel.RecoveryStats{}
+
+	rows, err := db.Query(`SELECT appID, COUNT(*),
+		SUM(CASE WHEN recovery=? THEN 1 ELSE 0 END),
+		SUM(CASE WHEN recovery=? THEN 1 ELSE 0 END),
+		AVG(CASE WHEN recovery=? THEN recoverySeconds END),
+		MAX(CASE WHEN recovery=? THEN recoverySeconds END)
+		FROM chaos_events WHERE serviceInstanceID=? AND recovery IS NOT NULL AND recovery<>'' GROUP BY appID ORDER BY appID`,
+		sharedModel.RecoveryRecovered, sharedModel.RecoveryTimedOut, sharedModel.RecoveryRecovered, sharedModel.RecoveryRecovered, serviceInstanceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			appStats                sharedModel.RecoveryStats
+			meanSeconds, maxSeconds sql.NullFloat64
+		)
+		err = rows.Scan(&appStats.AppID, &appStats.Kills, &appStats.Recovered, &appStats.TimedOut, &meanSeconds, &maxSeconds)
+		if err != nil {
+			return nil, err
+		}
+		appStats.MeanSeconds = meanSeconds.Float64
+		appStats.MaxSeconds = maxSeconds.Float64
+		stats = append(stats, appStats)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// truncateReason - cuts a reason short to the length of the reason columns
+func truncateReason(reason string) string {
+	runes := []rune(reason)
+	if len(runes) <= maxReasonLength {
+		return reason
+	}
+	return string(runes[:maxReasonLength-3]) + "..."
+}
+
+func queryEvents(db *sql.DB, query string, args ...interface{}) ([]sharedModel.Event, error) {
+	events := []sharedModel.Event{}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			event                                   sharedModel.Event
+			serviceBindingID, action, instanceIndex sql.NullString
+			outcome, reason, recovery, hypothesis   sql.NullString
+			orgID, spaceID, before, after           sql.NullString
+			seed                                    sql.NullInt64
+			recoverySeconds                         sql.NullFloat64
+		)
+		err = rows.Scan(&event.ID, &event.AppID, &event.ServiceInstanceID, &serviceBindingID, &event.CreatedAt, &seed, &action,
+			&instanceIndex, &outcome, &reason, &recovery, &recoverySeconds, &hypothesis, &orgID, &spaceID, &before, &after)
+		if err != nil {
+			return nil, err
+		}
+		event.ServiceBindingID = serviceBindingID.String
+		event.Seed = seed.Int64
+		event.Action = action.String
+		event.InstanceIndex = instanceIndex.String
+		event.Outcome = outcome.String
+		event.Reason = reason.String
+		event.Recovery = recovery.String
+		event.RecoverySeconds = recoverySeconds.Float64
+		event.Hypothesis = hypothesis.String
+		event.OrgID = orgID.String
+		event.SpaceID = spaceID.String
+		event.Before = before.String
+		event.After = after.String
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
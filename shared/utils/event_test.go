@@ -0,0 +1,211 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+	"strings"
+)
+
+var _ = Describe("Events", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupEventDB", func() {
+		It("creates the chaos_events table and adds any missing columns", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_events.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_events", "recovery").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectExec("ALTER TABLE chaos_events ADD COLUMN recovery .*").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_events", "recoverySeconds").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_events", "hypothesis").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_events", "orgID").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_events", "spaceID").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_events", "beforeState").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("chaos_events", "afterState").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			Expect(sharedUtils.SetupEventDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the table cannot be created", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_events.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+				Expect(sharedUtils.SetupEventDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#RecordEvent", func() {
+		It("inserts the event and returns its id", func() {
+			event := sharedModel.Event{AppID: "app-1", ServiceInstanceID: "1", ServiceBindingID: "2", CreatedAt: "2014-11-12T10:31:20Z", Seed: 42, Action: "kill", InstanceIndex: "0", Outcome: sharedModel.OutcomeSucceeded,
+				OrgID: "org-1", SpaceID: "space-1", Before: "STARTED with 2 instances (2 RUNNING)", After: "STARTED with 2 instances (1 DOWN, 1 RUNNING)"}
+			mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "1", "2", "2014-11-12T10:31:20Z", 42, "kill", "0", "succeeded", "", "org-1", "space-1",
+				"STARTED with 2 instances (2 RUNNING)", "STARTED with 2 instances (1 DOWN, 1 RUNNING)").WillReturnResult(sqlmock.NewResult(7, 1))
+			id, err := sharedUtils.RecordEvent(db, event)
+			Expect(err).To(BeNil())
+			Expect(id).To(Equal(int64(7)))
+		})
+
+		Context("When the reason is longer than the reason column", func() {
+			It("cuts the reason short", func() {
+				reason := "PUT /v2/apps/app-1 returned 500: " + strings.Repeat("x", 300)
+				mock.ExpectExec("INSERT INTO chaos_events.*").WithArgs("app-1", "", "", "", 0, "", "", "failed", reason[:252]+"...", "", "", "", "").
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				_, err := sharedUtils.RecordEvent(db, sharedModel.Event{AppID: "app-1", Outcome: sharedModel.OutcomeFailed, Reason: reason})
+				Expect(err).To(BeNil())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO chaos_events.*").WillReturnError(fmt.Errorf("An error has occured: %s", "INSERT error"))
+				_, err := sharedUtils.RecordEvent(db, sharedModel.Event{})
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#UpdateEventRecovery", func() {
+		It("updates the recovery of the event", func() {
+			mock.ExpectExec("UPDATE chaos_events SET recovery=\\?, recoverySeconds=\\? WHERE id=\\?").WithArgs("recovered", 12.5, 7).WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.UpdateEventRecovery(db, 7, sharedModel.RecoveryRecovered, 12.5)).To(BeNil())
+		})
+
+		Context("When the update fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("UPDATE chaos_events.*").WillReturnError(fmt.Errorf("An error has occured: %s", "UPDATE error"))
+				Expect(sharedUtils.UpdateEventRecovery(db, 7, sharedModel.RecoveryTimedOut, 0)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#UpdateEventHypothesis", func() {
+		It("updates the hypothesis of the event", func() {
+			mock.ExpectExec("UPDATE chaos_events SET hypothesis=\\?, reason=\\? WHERE id=\\?").WithArgs("failed", "probe failed", 7).WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.UpdateEventHypothesis(db, 7, sharedModel.HypothesisFailed, "probe failed")).To(BeNil())
+		})
+
+		Context("When the update fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("UPDATE chaos_events.*").WillReturnError(fmt.Errorf("An error has occured: %s", "UPDATE error"))
+				Expect(sharedUtils.UpdateEventHypothesis(db, 7, sharedModel.HypothesisHeld, "")).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#UpdateEventOutcome", func() {
+		It("updates the outcome and reason of the event", func() {
+			mock.ExpectExec("UPDATE chaos_events SET outcome=\\?, reason=\\? WHERE id=\\?").WithArgs("failed", "API error", 7).WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.UpdateEventOutcome(db, 7, sharedModel.OutcomeFailed, "API error")).To(BeNil())
+		})
+
+		Context("When the update fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("UPDATE chaos_events.*").WillReturnError(fmt.Errorf("An error has occured: %s", "UPDATE error"))
+				Expect(sharedUtils.UpdateEventOutcome(db, 7, sharedModel.OutcomeSucceeded, "")).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetEvents", func() {
+		It("returns the most recent events of the service instance", func() {
+			rows := sqlmock.NewRows(sharedUtils.EventColumns).
+				AddRow(2, "app-1", "1", "2", "2014-11-12T10:36:20Z", 42, "kill", "0", "succeeded", "", "recovered", 12.5, "held", "org-1", "space-1", "STARTED with 1 instances (1 RUNNING)", "STOPPED with 1 instances").
+				AddRow(1, "app-1", "1", "2", "2014-11-12T10:31:20Z", 41, "kill", "", "skipped", "app is unhealthy", nil, nil, nil, nil, nil, nil, nil)
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=\\? ORDER BY id DESC LIMIT \\?$").WithArgs("1", 50).WillReturnRows(rows)
+
+			events, err := sharedUtils.GetEvents(db, "1", 50)
+			Expect(err).To(BeNil())
+			Expect(events).To(Equal([]sharedModel.Event{
+				{ID: 2, AppID: "app-1", ServiceInstanceID: "1", ServiceBindingID: "2", CreatedAt: "2014-11-12T10:36:20Z", Seed: 42, Action: "kill",
+					InstanceIndex: "0", Outcome: "succeeded", Recovery: "recovered", RecoverySeconds: 12.5, Hypothesis: "held",
+					OrgID: "org-1", SpaceID: "space-1", Before: "STARTED with 1 instances (1 RUNNING)", After: "STOPPED with 1 instances"},
+				{ID: 1, AppID: "app-1", ServiceInstanceID: "1", ServiceBindingID: "2", CreatedAt: "2014-11-12T10:31:20Z", Seed: 41, Action: "kill",
+					Outcome: "skipped", Reason: "app is unhealthy"},
+			}))
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_events").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.GetEvents(db, "1", 50)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetEvent", func() {
+		It("returns the event of the service instance", func() {
+			rows := sqlmock.NewRows(sharedUtils.EventColumns).
+				AddRow(2, "app-1", "1", "2", "2014-11-12T10:36:20Z", 42, "kill", "0", "succeeded", "", "recovered", 12.5, "held", nil, nil, nil, nil)
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_events WHERE serviceInstanceID=\\? AND id=\\?$").WithArgs("1", 2).WillReturnRows(rows)
+
+			event, err := sharedUtils.GetEvent(db, "1", 2)
+			Expect(err).To(BeNil())
+			Expect(event).To(Equal(sharedModel.Event{ID: 2, AppID: "app-1", ServiceInstanceID: "1", ServiceBindingID: "2", CreatedAt: "2014-11-12T10:36:20Z",
+				Seed: 42, Action: "kill", InstanceIndex: "0", Outcome: "succeeded", Recovery: "recovered", RecoverySeconds: 12.5, Hypothesis: "held"}))
+		})
+
+		Context("When the service instance has no event with the id", func() {
+			It("returns an empty event", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_events").WillReturnRows(sqlmock.NewRows(sharedUtils.EventColumns))
+				event, err := sharedUtils.GetEvent(db, "1", 2)
+				Expect(err).To(BeNil())
+				Expect(event).To(Equal(sharedModel.Event{}))
+			})
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_events").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.GetEvent(db, "1", 2)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetRecoveryStats", func() {
+		It("returns the recovery statistics of every app", func() {
+			rows := sqlmock.NewRows([]string{"appID", "kills", "recovered", "timedOut", "mean", "max"}).
+				AddRow("app-1", 3, 2, 1, 15.5, 20.0).
+				AddRow("app-2", 1, 0, 1, nil, nil)
+			mock.ExpectQuery("^SELECT appID, COUNT(.+) FROM chaos_events WHERE serviceInstanceID=\\?.* GROUP BY appID").
+				WithArgs("recovered", "timed-out", "recovered", "recovered", "1").WillReturnRows(rows)
+
+			stats, err := sharedUtils.GetRecoveryStats(db, "1")
+			Expect(err).To(BeNil())
+			Expect(stats).To(Equal([]sharedModel.RecoveryStats{
+				{AppID: "app-1", Kills: 3, Recovered: 2, TimedOut: 1, MeanSeconds: 15.5, MaxSeconds: 20},
+				{AppID: "app-2", Kills: 1, TimedOut: 1},
+			}))
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT appID, COUNT").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.GetRecoveryStats(db, "1")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"os"
+)
+
+// HaltEnv - the environment variable that halts chaos when set, its value is the reason
+const HaltEnv = "CHAOS_HALT"
+
+// SetupHaltDB - creates the chaos_halt DB if it does not exist
+func SetupHaltDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS chaos_halt
+	(
+		id int NOT NULL,
+		halted boolean,
+		setBy varchar(255),
+		reason varchar(255),
+		setAt varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetHalt - returns the halt in force, set by the CHAOS_HALT environment variable or else by the chaos_halt database
+func GetHalt(db *sql.DB) (sharedModel.Halt, error) {
+	if reason := os.Getenv(HaltEnv); reason != "" {
+		return sharedModel.Halt{Halted: true, SetBy: HaltEnv, Reason: reason}, nil
+	}
+
+	var (
+		halt                 sharedModel.Halt
+		halted               sql.NullBool
+		setBy, reason, setAt sql.NullString
+	)
+	err := db.QueryRow("SELECT halted, setBy, reason, setAt FROM chaos_halt WHERE id=1").Scan(&halted, &setBy, &reason, &setAt)
+	if err == sql.ErrNoRows {
+		return sharedModel.Halt{}, nil
+	}
+	if err != nil {
+		return sharedModel.Halt{}, err
+	}
+	halt.Halted = halted.Bool
+	halt.SetBy = setBy.String
+	halt.Reason = reason.String
+	halt.SetAt = setAt.String
+	return halt, nil
+}
+
+// SetHalt - writes the halt to the chaos_halt database, cutting a reason too long for its column short
+func SetHalt(db *sql.DB, halt sharedModel.Halt) error {
+	_, err := db.Exec("INSERT INTO chaos_halt (id, halted, setBy, reason, setAt) VALUES (1, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE halted=VALUES(halted), setBy=VALUES(setBy), reason=VALUES(reason), setAt=VALUES(setAt)",
+		halt.Halted, halt.SetBy, truncateReason(halt.Reason), halt.SetAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
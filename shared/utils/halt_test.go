@@ -0,0 +1,112 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+	"strings"
+)
+
+var _ = Describe("Halt", func() {
+	var (
+		db        *sql.DB
+		mock      sqlmock.Sqlmock
+		haltNames = []string{"halted", "setBy", "reason", "setAt"}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("CHAOS_HALT")
+		db.Close()
+	})
+
+	Describe("#SetupHaltDB", func() {
+		It("creates the chaos_halt table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_halt.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.SetupHaltDB(db)).To(BeNil())
+		})
+
+		Context("When the table cannot be created", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_halt.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+				Expect(sharedUtils.SetupHaltDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetHalt", func() {
+		Context("When the environment variable is set", func() {
+			It("returns a halt with the variable as the reason without reading the database", func() {
+				os.Setenv("CHAOS_HALT", "change freeze")
+				halt, err := sharedUtils.GetHalt(db)
+				Expect(err).To(BeNil())
+				Expect(halt).To(Equal(sharedModel.Halt{Halted: true, SetBy: "CHAOS_HALT", Reason: "change freeze"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the database has a halt", func() {
+			It("returns the halt", func() {
+				rows := sqlmock.NewRows(haltNames).AddRow(true, "operator", "incident 42", "2014-11-12T10:31:20Z")
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt WHERE id=1$").WillReturnRows(rows)
+				halt, err := sharedUtils.GetHalt(db)
+				Expect(err).To(BeNil())
+				Expect(halt).To(Equal(sharedModel.Halt{Halted: true, SetBy: "operator", Reason: "incident 42", SetAt: "2014-11-12T10:31:20Z"}))
+			})
+		})
+
+		Context("When the database has no halt", func() {
+			It("returns that chaos is not halted", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnRows(sqlmock.NewRows(haltNames))
+				halt, err := sharedUtils.GetHalt(db)
+				Expect(err).To(BeNil())
+				Expect(halt.Halted).To(BeFalse())
+			})
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_halt").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.GetHalt(db)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#SetHalt", func() {
+		It("upserts the halt", func() {
+			mock.ExpectExec("INSERT INTO chaos_halt (.+) ON DUPLICATE KEY UPDATE").WithArgs(true, "operator", "incident 42", "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.SetHalt(db, sharedModel.Halt{Halted: true, SetBy: "operator", Reason: "incident 42", SetAt: "2014-11-12T10:31:20Z"})).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the reason is longer than its column", func() {
+			It("cuts the reason short", func() {
+				reason := strings.Repeat("x", 300)
+				mock.ExpectExec("INSERT INTO chaos_halt (.+) ON DUPLICATE KEY UPDATE").WithArgs(true, "operator", strings.Repeat("x", 252)+"...", "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(1, 1))
+				Expect(sharedUtils.SetHalt(db, sharedModel.Halt{Halted: true, SetBy: "operator", Reason: reason, SetAt: "2014-11-12T10:31:20Z"})).To(BeNil())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO chaos_halt").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				Expect(sharedUtils.SetHalt(db, sharedModel.Halt{})).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Column struct - a column added to a table after it was first created
+type Column struct {
+	Name       string
+	Definition string
+}
+
+// AddColumns - adds any of the columns missing from an existing table, so tables created by earlier releases pick up new columns
+func AddColumns(db *sql.DB, table string, columns []Column) error {
+	for _, column := range columns {
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? AND COLUMN_NAME=?", table, column.Name).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column.Name, column.Definition))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("#AddColumns", func() {
+	var (
+		db      *sql.DB
+		mock    sqlmock.Sqlmock
+		columns []sharedUtils.Column
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		columns = []sharedUtils.Column{{Name: "first", Definition: "int"}, {Name: "second", Definition: "varchar(255)"}}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("adds only the columns that are missing", func() {
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("test", "first").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("test", "second").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("ALTER TABLE test ADD COLUMN second varchar\\(255\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+		Expect(sharedUtils.AddColumns(db, "test", columns)).To(BeNil())
+		Expect(mock.ExpectationsWereMet()).To(BeNil())
+	})
+
+	Context("When the columns cannot be checked", func() {
+		It("returns an error", func() {
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+			Expect(sharedUtils.AddColumns(db, "test", columns)).ToNot(BeNil())
+		})
+	})
+
+	Context("When a column cannot be added", func() {
+		It("returns an error", func() {
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("test", "first").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectExec("ALTER TABLE test ADD COLUMN first int").WillReturnError(fmt.Errorf("An error has occured: %s", "ALTER error"))
+			Expect(sharedUtils.AddColumns(db, "test", columns)).ToNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,101 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+)
+
+// SetupPauseDB - creates the paused_bindings DB if it does not exist
+func SetupPauseDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS paused_bindings
+	(
+		serviceBindingID varchar(255),
+		serviceInstanceID varchar(255),
+		appID varchar(255),
+		pausedAt varchar(255),
+		reason varchar(255),
+		PRIMARY KEY (serviceBindingID)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// PauseBinding - pauses chaos for a binding, replacing the reason if it is already paused
+func PauseBinding(db *sql.DB, pause sharedModel.PausedBinding) error {
+	_, err := db.Exec("INSERT INTO paused_bindings (serviceBindingID, serviceInstanceID, appID, pausedAt, reason) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE pausedAt=VALUES(pausedAt), reason=VALUES(reason)",
+		pause.ServiceBindingID, pause.ServiceInstanceID, pause.AppID, pause.PausedAt, truncateReason(pause.Reason))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadPausedBindings - loads every paused binding to memory from database, keyed by service binding ID
+func ReadPausedBindings(db *sql.DB) (map[string]sharedModel.PausedBinding, error) {
+	pausedMap := make(map[string]sharedModel.PausedBinding)
+
+	paused, err := queryPausedBindings(db, "SELECT serviceBindingID, serviceInstanceID, appID, pausedAt, reason FROM paused_bindings")
+	if err != nil {
+		return nil, err
+	}
+	for _, pause := range paused {
+		pausedMap[pause.ServiceBindingID] = pause
+	}
+	return pausedMap, nil
+}
+
+// GetPausedBindings - loads the paused bindings of a service instance
+func GetPausedBindings(db *sql.DB, serviceInstanceID string) ([]sharedModel.PausedBinding, error) {
+	return queryPausedBindings(db, "SELECT serviceBindingID, serviceInstanceID, appID, pausedAt, reason FROM paused_bindings WHERE serviceInstanceID=? ORDER BY pausedAt", serviceInstanceID)
+}
+
+// ResumeBinding - resumes chaos for a paused binding of a service instance, returning false if it was not paused
+func ResumeBinding(db *sql.DB, serviceInstanceID string, serviceBindingID string) (bool, error) {
+	result, err := db.Exec("DELETE FROM paused_bindings WHERE serviceInstanceID=? AND serviceBindingID=?", serviceInstanceID, serviceBindingID)
+	if err != nil {
+		return false, err
+	}
+	resumed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return resumed > 0, nil
+}
+
+// DeletePausedBindings - deletes every paused binding of a service instance
+func DeletePausedBindings(db *sql.DB, serviceInstanceID string) error {
+	_, err := db.Exec("DELETE FROM paused_bindings WHERE serviceInstanceID=?", serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func queryPausedBindings(db *sql.DB, query string, args ...interface{}) ([]sharedModel.PausedBinding, error) {
+	paused := []sharedModel.PausedBinding{}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			pause            sharedModel.PausedBinding
+			pausedAt, reason sql.NullString
+		)
+		err = rows.Scan(&pause.ServiceBindingID, &pause.ServiceInstanceID, &pause.AppID, &pausedAt, &reason)
+		if err != nil {
+			return nil, err
+		}
+		pause.PausedAt = pausedAt.String
+		pause.Reason = reason.String
+		paused = append(paused, pause)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return paused, nil
+}
@@ -0,0 +1,153 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Paused bindings", func() {
+	var (
+		db         *sql.DB
+		mock       sqlmock.Sqlmock
+		pauseNames = []string{"serviceBindingID", "serviceInstanceID", "appID", "pausedAt", "reason"}
+		pause      = sharedModel.PausedBinding{ServiceBindingID: "2", ServiceInstanceID: "1", AppID: "app-1", PausedAt: "2014-11-12T10:31:20Z", Reason: "app crashed 3 times"}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupPauseDB", func() {
+		It("creates the paused_bindings table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS paused_bindings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.SetupPauseDB(db)).To(BeNil())
+		})
+
+		Context("When the table cannot be created", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS paused_bindings.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+				Expect(sharedUtils.SetupPauseDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#PauseBinding", func() {
+		It("upserts the paused binding", func() {
+			mock.ExpectExec("INSERT INTO paused_bindings (.+) ON DUPLICATE KEY UPDATE").WithArgs("2", "1", "app-1", "2014-11-12T10:31:20Z", "app crashed 3 times").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.PauseBinding(db, pause)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO paused_bindings").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				Expect(sharedUtils.PauseBinding(db, pause)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#ReadPausedBindings", func() {
+		It("returns every paused binding by service binding ID", func() {
+			rows := sqlmock.NewRows(pauseNames).
+				AddRow("2", "1", "app-1", "2014-11-12T10:31:20Z", "app crashed 3 times").
+				AddRow("3", "1", "app-2", nil, nil)
+			mock.ExpectQuery("^SELECT (.+) FROM paused_bindings$").WillReturnRows(rows)
+			pausedMap, err := sharedUtils.ReadPausedBindings(db)
+			Expect(err).To(BeNil())
+			Expect(pausedMap).To(Equal(map[string]sharedModel.PausedBinding{
+				"2": pause,
+				"3": {ServiceBindingID: "3", ServiceInstanceID: "1", AppID: "app-2"},
+			}))
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM paused_bindings").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.ReadPausedBindings(db)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		Context("When a row cannot be scanned", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM paused_bindings").WillReturnRows(sqlmock.NewRows([]string{"serviceBindingID"}).AddRow("2"))
+				_, err := sharedUtils.ReadPausedBindings(db)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetPausedBindings", func() {
+		It("returns the paused bindings of the service instance", func() {
+			rows := sqlmock.NewRows(pauseNames).AddRow("2", "1", "app-1", "2014-11-12T10:31:20Z", "app crashed 3 times")
+			mock.ExpectQuery("^SELECT (.+) FROM paused_bindings WHERE serviceInstanceID=\\? ORDER BY pausedAt$").WithArgs("1").WillReturnRows(rows)
+			paused, err := sharedUtils.GetPausedBindings(db, "1")
+			Expect(err).To(BeNil())
+			Expect(paused).To(Equal([]sharedModel.PausedBinding{pause}))
+		})
+
+		Context("When there are no paused bindings", func() {
+			It("returns an empty list", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM paused_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(pauseNames))
+				paused, err := sharedUtils.GetPausedBindings(db, "1")
+				Expect(err).To(BeNil())
+				Expect(paused).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#ResumeBinding", func() {
+		It("returns true when the binding was paused", func() {
+			mock.ExpectExec("DELETE FROM paused_bindings WHERE serviceInstanceID=\\? AND serviceBindingID=\\?").WithArgs("1", "2").WillReturnResult(sqlmock.NewResult(0, 1))
+			resumed, err := sharedUtils.ResumeBinding(db, "1", "2")
+			Expect(err).To(BeNil())
+			Expect(resumed).To(BeTrue())
+		})
+
+		Context("When the binding was not paused", func() {
+			It("returns false", func() {
+				mock.ExpectExec("DELETE FROM paused_bindings").WithArgs("1", "2").WillReturnResult(sqlmock.NewResult(0, 0))
+				resumed, err := sharedUtils.ResumeBinding(db, "1", "2")
+				Expect(err).To(BeNil())
+				Expect(resumed).To(BeFalse())
+			})
+		})
+
+		Context("When the delete fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("DELETE FROM paused_bindings").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.ResumeBinding(db, "1", "2")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeletePausedBindings", func() {
+		It("deletes the paused bindings of the service instance", func() {
+			mock.ExpectExec("DELETE FROM paused_bindings WHERE serviceInstanceID=").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 2))
+			Expect(sharedUtils.DeletePausedBindings(db, "1")).To(BeNil())
+		})
+
+		Context("When the delete fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("DELETE FROM paused_bindings").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				Expect(sharedUtils.DeletePausedBindings(db, "1")).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,101 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+)
+
+// SetupProbeDB - creates the chaos_probes DB if it does not exist
+func SetupProbeDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS chaos_probes
+	(
+		id int NOT NULL AUTO_INCREMENT,
+		serviceInstanceID varchar(255),
+		url varchar(2048),
+		expectedStatus int,
+		maxLatencyMs int,
+		bodyMatch varchar(255),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadProbes - loads the probes of every service instance to memory from database
+func ReadProbes(db *sql.DB) (map[string][]sharedModel.Probe, error) {
+	probesMap := make(map[string][]sharedModel.Probe)
+
+	probes, err := queryProbes(db, "SELECT id, serviceInstanceID, url, expectedStatus, maxLatencyMs, bodyMatch FROM chaos_probes ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	for _, probe := range probes {
+		probesMap[probe.ServiceInstanceID] = append(probesMap[probe.ServiceInstanceID], probe)
+	}
+	return probesMap, nil
+}
+
+// GetProbes - loads the probes of a service instance
+func GetProbes(db *sql.DB, serviceInstanceID string) ([]sharedModel.Probe, error) {
+	return queryProbes(db, "SELECT id, serviceInstanceID, url, expectedStatus, maxLatencyMs, bodyMatch FROM chaos_probes WHERE serviceInstanceID=? ORDER BY id", serviceInstanceID)
+}
+
+// AddProbe - adds a row to chaos_probes database, returning the id of the probe
+func AddProbe(db *sql.DB, probe sharedModel.Probe) (int64, error) {
+	result, err := db.Exec("INSERT INTO chaos_probes (serviceInstanceID, url, expectedStatus, maxLatencyMs, bodyMatch) VALUES (?, ?, ?, ?, ?)",
+		probe.ServiceInstanceID, probe.URL, probe.ExpectedStatus, probe.MaxLatencyMs, probe.BodyMatch)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// DeleteProbe - deletes a probe of a service instance, returning false if it did not exist
+func DeleteProbe(db *sql.DB, serviceInstanceID string, id int64) (bool, error) {
+	result, err := db.Exec("DELETE FROM chaos_probes WHERE serviceInstanceID=? AND id=?", serviceInstanceID, id)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+// DeleteProbes - deletes every probe of a service instance
+func DeleteProbes(db *sql.DB, serviceInstanceID string) error {
+	_, err := db.Exec("DELETE FROM chaos_probes WHERE serviceInstanceID=?", serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func queryProbes(db *sql.DB, query string, args ...interface{}) ([]sharedModel.Probe, error) {
+	probes := []sharedModel.Probe{}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			probe     sharedModel.Probe
+			bodyMatch sql.NullString
+		)
+		err = rows.Scan(&probe.ID, &probe.ServiceInstanceID, &probe.URL, &probe.ExpectedStatus, &probe.MaxLatencyMs, &bodyMatch)
+		if err != nil {
+			return nil, err
+		}
+		probe.BodyMatch = bodyMatch.String
+		probes = append(probes, probe)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return probes, nil
+}
@@ -0,0 +1,153 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Probes", func() {
+	var (
+		db         *sql.DB
+		mock       sqlmock.Sqlmock
+		probeRows  sqlmock.Rows
+		probeNames = []string{"id", "serviceInstanceID", "url", "expectedStatus", "maxLatencyMs", "bodyMatch"}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		probeRows = sqlmock.NewRows(probeNames).
+			AddRow(1, "1", "https://app-1.example.com/health", 200, 500, "UP").
+			AddRow(2, "1", "https://app-1.example.com/", 200, 1000, nil).
+			AddRow(3, "2", "https://app-2.example.com/", 204, 0, "")
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupProbeDB", func() {
+		It("creates the chaos_probes table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_probes.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.SetupProbeDB(db)).To(BeNil())
+		})
+
+		Context("When the table cannot be created", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_probes.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+				Expect(sharedUtils.SetupProbeDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#ReadProbes", func() {
+		It("returns the probes of every service instance", func() {
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_probes ORDER BY id$").WillReturnRows(probeRows)
+			probesMap, err := sharedUtils.ReadProbes(db)
+			Expect(err).To(BeNil())
+			Expect(probesMap).To(HaveLen(2))
+			Expect(probesMap["1"]).To(Equal([]sharedModel.Probe{
+				{ID: 1, ServiceInstanceID: "1", URL: "https://app-1.example.com/health", ExpectedStatus: 200, MaxLatencyMs: 500, BodyMatch: "UP"},
+				{ID: 2, ServiceInstanceID: "1", URL: "https://app-1.example.com/", ExpectedStatus: 200, MaxLatencyMs: 1000},
+			}))
+			Expect(probesMap["2"]).To(HaveLen(1))
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_probes").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.ReadProbes(db)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetProbes", func() {
+		It("returns the probes of the service instance", func() {
+			rows := sqlmock.NewRows(probeNames).AddRow(1, "1", "https://app-1.example.com/health", 200, 500, "UP")
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_probes WHERE serviceInstanceID=\\? ORDER BY id$").WithArgs("1").WillReturnRows(rows)
+			probes, err := sharedUtils.GetProbes(db, "1")
+			Expect(err).To(BeNil())
+			Expect(probes).To(Equal([]sharedModel.Probe{
+				{ID: 1, ServiceInstanceID: "1", URL: "https://app-1.example.com/health", ExpectedStatus: 200, MaxLatencyMs: 500, BodyMatch: "UP"},
+			}))
+		})
+
+		Context("When the service instance has no probes", func() {
+			It("returns an empty list", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_probes WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(probeNames))
+				probes, err := sharedUtils.GetProbes(db, "1")
+				Expect(err).To(BeNil())
+				Expect(probes).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#AddProbe", func() {
+		It("inserts the probe and returns its id", func() {
+			probe := sharedModel.Probe{ServiceInstanceID: "1", URL: "https://app-1.example.com/health", ExpectedStatus: 200, MaxLatencyMs: 500, BodyMatch: "UP"}
+			mock.ExpectExec("INSERT INTO chaos_probes.*").WithArgs("1", "https://app-1.example.com/health", 200, 500, "UP").WillReturnResult(sqlmock.NewResult(4, 1))
+			id, err := sharedUtils.AddProbe(db, probe)
+			Expect(err).To(BeNil())
+			Expect(id).To(Equal(int64(4)))
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO chaos_probes.*").WillReturnError(fmt.Errorf("An error has occured: %s", "INSERT error"))
+				_, err := sharedUtils.AddProbe(db, sharedModel.Probe{})
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeleteProbe", func() {
+		It("deletes the probe", func() {
+			mock.ExpectExec("DELETE FROM chaos_probes WHERE serviceInstanceID=\\? AND id=\\?").WithArgs("1", 4).WillReturnResult(sqlmock.NewResult(0, 1))
+			deleted, err := sharedUtils.DeleteProbe(db, "1", 4)
+			Expect(err).To(BeNil())
+			Expect(deleted).To(BeTrue())
+		})
+
+		Context("When the probe does not exist", func() {
+			It("returns false", func() {
+				mock.ExpectExec("DELETE FROM chaos_probes.*").WillReturnResult(sqlmock.NewResult(0, 0))
+				deleted, err := sharedUtils.DeleteProbe(db, "1", 4)
+				Expect(err).To(BeNil())
+				Expect(deleted).To(BeFalse())
+			})
+		})
+
+		Context("When the delete fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("DELETE FROM chaos_probes.*").WillReturnError(fmt.Errorf("An error has occured: %s", "DELETE error"))
+				_, err := sharedUtils.DeleteProbe(db, "1", 4)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#DeleteProbes", func() {
+		It("deletes every probe of the service instance", func() {
+			mock.ExpectExec("DELETE FROM chaos_probes WHERE serviceInstanceID=\\?$").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 2))
+			Expect(sharedUtils.DeleteProbes(db, "1")).To(BeNil())
+		})
+
+		Context("When the delete fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("DELETE FROM chaos_probes.*").WillReturnError(fmt.Errorf("An error has occured: %s", "DELETE error"))
+				Expect(sharedUtils.DeleteProbes(db, "1")).ToNot(BeNil())
+			})
+		})
+	})
+})
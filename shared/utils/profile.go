@@ -0,0 +1,120 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"strconv"
+	"strings"
+)
+
+// SetupProfileDB - creates the chaos_profiles DB if it does not exist
+func SetupProfileDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS chaos_profiles
+	(
+		serviceInstanceID varchar(255),
+		actions text,
+		PRIMARY KEY (serviceInstanceID)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadProfiles - loads the weighted actions of every service instance with a profile to memory from database
+func ReadProfiles(db *sql.DB) (map[string][]sharedModel.WeightedAction, error) {
+	profilesMap := make(map[string][]sharedModel.WeightedAction)
+
+	rows, err := db.Query("SELECT serviceInstanceID, actions FROM chaos_profiles")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var serviceInstanceID, actions string
+		if err = rows.Scan(&serviceInstanceID, &actions); err != nil {
+			return nil, err
+		}
+		weighted, err := ParseProfile(actions)
+		if err != nil {
+			return nil, err
+		}
+		if len(weighted) > 0 {
+			profilesMap[serviceInstanceID] = weighted
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return profilesMap, nil
+}
+
+// GetProfile - loads the profile of a service instance, without actions if none has been saved
+func GetProfile(db *sql.DB, serviceInstanceID string) (sharedModel.Profile, error) {
+	var actions string
+
+	err := db.QueryRow("SELECT actions FROM chaos_profiles WHERE serviceInstanceID=?", serviceInstanceID).Scan(&actions)
+	if err == sql.ErrNoRows {
+		return sharedModel.Profile{ServiceInstanceID: serviceInstanceID, Actions: []sharedModel.WeightedAction{}}, nil
+	}
+	if err != nil {
+		return sharedModel.Profile{}, err
+	}
+	weighted, err := ParseProfile(actions)
+	if err != nil {
+		return sharedModel.Profile{}, err
+	}
+	return sharedModel.Profile{ServiceInstanceID: serviceInstanceID, Actions: weighted}, nil
+}
+
+// UpdateProfile - upserts the profile of a service instance
+func UpdateProfile(db *sql.DB, profile sharedModel.Profile) error {
+	_, err := db.Exec("INSERT INTO chaos_profiles (serviceInstanceID, actions) VALUES (?, ?) ON DUPLICATE KEY UPDATE actions=VALUES(actions)",
+		profile.ServiceInstanceID, FormatProfile(profile.Actions))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteProfile - deletes the profile of a service instance
+func DeleteProfile(db *sql.DB, serviceInstanceID string) error {
+	_, err := db.Exec("DELETE FROM chaos_profiles WHERE serviceInstanceID=?", serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// FormatProfile - returns weighted actions as a comma separated list of action:weight pairs
+func FormatProfile(actions []sharedModel.WeightedAction) string {
+	var pairs []string
+	for _, weighted := range actions {
+		pairs = append(pairs, fmt.Sprintf("%s:%d", weighted.Action, weighted.Weight))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ParseProfile - splits a comma separated list of action:weight pairs, dropping blanks. Pairs are split at their last colon
+// as plugin actions hold one too.
+func ParseProfile(actions string) ([]sharedModel.WeightedAction, error) {
+	weighted := []sharedModel.WeightedAction{}
+	for _, pair := range strings.Split(actions, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		split := strings.LastIndex(pair, ":")
+		if split < 0 {
+			return nil, fmt.Errorf("%s is not an action:weight pair", pair)
+		}
+		action := strings.TrimSpace(pair[:split])
+		weight, err := strconv.Atoi(strings.TrimSpace(pair[split+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("the weight of %s must be a whole number", action)
+		}
+		weighted = append(weighted, sharedModel.WeightedAction{Action: action, Weight: weight})
+	}
+	return weighted, nil
+}
@@ -0,0 +1,117 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Profiles", func() {
+	var (
+		db      *sql.DB
+		mock    sqlmock.Sqlmock
+		actions = []sharedModel.WeightedAction{{Action: "kill", Weight: 70}, {Action: "restart", Weight: 20}, {Action: "network-partition", Weight: 10}}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupProfileDB", func() {
+		It("creates the chaos_profiles table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_profiles.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.SetupProfileDB(db)).To(BeNil())
+		})
+	})
+
+	Describe("#ReadProfiles", func() {
+		It("returns the weighted actions of every service instance with a profile", func() {
+			rows := sqlmock.NewRows([]string{"serviceInstanceID", "actions"}).
+				AddRow("1", "kill:70,restart:20,network-partition:10").
+				AddRow("2", "")
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_profiles$").WillReturnRows(rows)
+			profiles, err := sharedUtils.ReadProfiles(db)
+			Expect(err).To(BeNil())
+			Expect(profiles).To(Equal(map[string][]sharedModel.WeightedAction{"1": actions}))
+		})
+	})
+
+	Describe("#GetProfile", func() {
+		It("returns the profile of the service instance", func() {
+			rows := sqlmock.NewRows([]string{"actions"}).AddRow("kill:70,restart:20,network-partition:10")
+			mock.ExpectQuery("^SELECT actions FROM chaos_profiles WHERE serviceInstanceID=\\?$").WithArgs("1").WillReturnRows(rows)
+			profile, err := sharedUtils.GetProfile(db, "1")
+			Expect(err).To(BeNil())
+			Expect(profile).To(Equal(sharedModel.Profile{ServiceInstanceID: "1", Actions: actions}))
+		})
+
+		Context("When no profile has been saved", func() {
+			It("returns a profile without actions", func() {
+				mock.ExpectQuery("^SELECT actions FROM chaos_profiles WHERE serviceInstanceID=\\?$").WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"actions"}))
+				profile, err := sharedUtils.GetProfile(db, "1")
+				Expect(err).To(BeNil())
+				Expect(profile.Actions).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#UpdateProfile", func() {
+		It("upserts the profile", func() {
+			mock.ExpectExec("INSERT INTO chaos_profiles .* ON DUPLICATE KEY UPDATE .*").WithArgs("1", "kill:70,restart:20,network-partition:10").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.UpdateProfile(db, sharedModel.Profile{ServiceInstanceID: "1", Actions: actions})).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#DeleteProfile", func() {
+		It("deletes the profile", func() {
+			mock.ExpectExec("DELETE FROM chaos_profiles WHERE serviceInstanceID=\\?").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(sharedUtils.DeleteProfile(db, "1")).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Describe("#ParseProfile", func() {
+		It("splits action:weight pairs, trimming spaces and dropping blanks", func() {
+			Expect(sharedUtils.ParseProfile(" kill:70, restart : 20,,network-partition:10 ")).To(Equal(actions))
+		})
+
+		It("splits plugin actions at the weight", func() {
+			Expect(sharedUtils.ParseProfile("plugin:feature-flag:5")).To(Equal([]sharedModel.WeightedAction{{Action: "plugin:feature-flag", Weight: 5}}))
+		})
+
+		Context("When a pair has no weight", func() {
+			It("returns an error", func() {
+				_, err := sharedUtils.ParseProfile("kill")
+				Expect(err).To(MatchError("kill is not an action:weight pair"))
+			})
+		})
+
+		Context("When a weight is not a number", func() {
+			It("returns an error", func() {
+				_, err := sharedUtils.ParseProfile("kill:lots")
+				Expect(err).To(MatchError("the weight of kill must be a whole number"))
+			})
+		})
+	})
+
+	Describe("#FormatProfile", func() {
+		It("joins the weighted actions", func() {
+			Expect(sharedUtils.FormatProfile(actions)).To(Equal("kill:70,restart:20,network-partition:10"))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+)
+
+// SetupReplayDB - creates the chaos_replays DB if it does not exist
+func SetupReplayDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS chaos_replays
+	(
+		serviceBindingID varchar(255),
+		serviceInstanceID varchar(255),
+		appID varchar(255),
+		eventID int,
+		seed bigint,
+		requestedAt varchar(255),
+		PRIMARY KEY (serviceBindingID)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RequestReplay - asks for a run to be replayed on the next run of its binding, replacing any replay not yet made
+func RequestReplay(db *sql.DB, replay sharedModel.Replay) error {
+	_, err := db.Exec("INSERT INTO chaos_replays (serviceBindingID, serviceInstanceID, appID, eventID, seed, requestedAt) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE eventID=VALUES(eventID), seed=VALUES(seed), requestedAt=VALUES(requestedAt)",
+		replay.ServiceBindingID, replay.ServiceInstanceID, replay.AppID, replay.EventID, replay.Seed, replay.RequestedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadReplays - loads every replay not yet made to memory from database, keyed by service binding ID
+func ReadReplays(db *sql.DB) (map[string]sharedModel.Replay, error) {
+	replays := make(map[string]sharedModel.Replay)
+
+	rows, err := db.Query("SELECT serviceBindingID, serviceInstanceID, appID, eventID, seed, requestedAt FROM chaos_replays")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var replay sharedModel.Replay
+		err = rows.Scan(&replay.ServiceBindingID, &replay.ServiceInstanceID, &replay.AppID, &replay.EventID, &replay.Seed, &replay.RequestedAt)
+		if err != nil {
+			return nil, err
+		}
+		replays[replay.ServiceBindingID] = replay
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return replays, nil
+}
+
+// ClaimReplay - removes the replay of a binding before it is made, reporting whether this caller removed it so that only
+// one processor instance makes it. A replay of another event requested since is kept.
+func ClaimReplay(db *sql.DB, serviceBindingID string, eventID int64) (bool, error) {
+	result, err := db.Exec("DELETE FROM chaos_replays WHERE serviceBindingID=? AND eventID=?", serviceBindingID, eventID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
@@ -0,0 +1,99 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Replays", func() {
+	var (
+		db          *sql.DB
+		mock        sqlmock.Sqlmock
+		replayNames = []string{"serviceBindingID", "serviceInstanceID", "appID", "eventID", "seed", "requestedAt"}
+		replay      = sharedModel.Replay{ServiceBindingID: "2", ServiceInstanceID: "1", AppID: "app-1", EventID: 7, Seed: -4237981, RequestedAt: "2014-11-12T10:31:20Z"}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupReplayDB", func() {
+		It("creates the chaos_replays table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_replays.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.SetupReplayDB(db)).To(BeNil())
+		})
+
+		Context("When the table cannot be created", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS chaos_replays.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+				Expect(sharedUtils.SetupReplayDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#RequestReplay", func() {
+		It("upserts the replay", func() {
+			mock.ExpectExec("INSERT INTO chaos_replays (.+) ON DUPLICATE KEY UPDATE").WithArgs("2", "1", "app-1", 7, -4237981, "2014-11-12T10:31:20Z").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.RequestReplay(db, replay)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the insert fails", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO chaos_replays").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				Expect(sharedUtils.RequestReplay(db, replay)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#ReadReplays", func() {
+		It("returns the replays keyed by service binding", func() {
+			mock.ExpectQuery("^SELECT (.+) FROM chaos_replays$").WillReturnRows(sqlmock.NewRows(replayNames).AddRow("2", "1", "app-1", 7, -4237981, "2014-11-12T10:31:20Z"))
+			replays, err := sharedUtils.ReadReplays(db)
+			Expect(err).To(BeNil())
+			Expect(replays).To(Equal(map[string]sharedModel.Replay{"2": replay}))
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM chaos_replays$").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.ReadReplays(db)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#ClaimReplay", func() {
+		It("deletes the replay of the event", func() {
+			mock.ExpectExec("^DELETE FROM chaos_replays WHERE serviceBindingID=\\? AND eventID=\\?$").WithArgs("2", 7).WillReturnResult(sqlmock.NewResult(0, 1))
+			claimed, err := sharedUtils.ClaimReplay(db, "2", 7)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeTrue())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When another processor instance claimed the replay first", func() {
+			It("returns false", func() {
+				mock.ExpectExec("^DELETE FROM chaos_replays").WithArgs("2", 7).WillReturnResult(sqlmock.NewResult(0, 0))
+				claimed, err := sharedUtils.ClaimReplay(db, "2", 7)
+				Expect(err).To(BeNil())
+				Expect(claimed).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,69 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+)
+
+// SetupRouteFaultsDB - creates the route_faults DB if it does not exist
+func SetupRouteFaultsDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS route_faults
+	(
+		serviceInstanceID varchar(255),
+		latencyMs int,
+		latencyPercent int,
+		errorStatus int,
+		errorPercent int,
+		dropPercent int,
+		bandwidthKBps int,
+		PRIMARY KEY (serviceInstanceID)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetRouteFaults - loads the route faults of a service instance, no faults if none have been saved
+func GetRouteFaults(db *sql.DB, serviceInstanceID string) (sharedModel.RouteFaults, error) {
+	var latencyMs, latencyPercent, errorStatus, errorPercent, dropPercent, bandwidthKBps sql.NullInt64
+
+	err := db.QueryRow("SELECT latencyMs, latencyPercent, errorStatus, errorPercent, dropPercent, bandwidthKBps FROM route_faults WHERE serviceInstanceID=?", serviceInstanceID).
+		Scan(&latencyMs, &latencyPercent, &errorStatus, &errorPercent, &dropPercent, &bandwidthKBps)
+	if err == sql.ErrNoRows {
+		return sharedModel.RouteFaults{ServiceInstanceID: serviceInstanceID}, nil
+	}
+	if err != nil {
+		return sharedModel.RouteFaults{}, err
+	}
+	return sharedModel.RouteFaults{
+		ServiceInstanceID: serviceInstanceID,
+		LatencyMs:         int(latencyMs.Int64),
+		LatencyPercent:    int(latencyPercent.Int64),
+		ErrorStatus:       int(errorStatus.Int64),
+		ErrorPercent:      int(errorPercent.Int64),
+		DropPercent:       int(dropPercent.Int64),
+		BandwidthKBps:     int(bandwidthKBps.Int64),
+	}, nil
+}
+
+// UpdateRouteFaults - upserts the route faults of a service instance
+func UpdateRouteFaults(db *sql.DB, faults sharedModel.RouteFaults) error {
+	_, err := db.Exec("INSERT INTO route_faults (serviceInstanceID, latencyMs, latencyPercent, errorStatus, errorPercent, dropPercent, bandwidthKBps) VALUES (?, ?, ?, ?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE latencyMs=VALUES(latencyMs), latencyPercent=VALUES(latencyPercent), errorStatus=VALUES(errorStatus), "+
+		"errorPercent=VALUES(errorPercent), dropPercent=VALUES(dropPercent), bandwidthKBps=VALUES(bandwidthKBps)",
+		faults.ServiceInstanceID, faults.LatencyMs, faults.LatencyPercent, faults.ErrorStatus, faults.ErrorPercent, faults.DropPercent, faults.BandwidthKBps)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteRouteFaults - deletes the route faults of a service instance
+func DeleteRouteFaults(db *sql.DB, serviceInstanceID string) error {
+	_, err := db.Exec("DELETE FROM route_faults WHERE serviceInstanceID=?", serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Route faults", func() {
+	var (
+		db          *sql.DB
+		mock        sqlmock.Sqlmock
+		faultsNames = []string{"latencyMs", "latencyPercent", "errorStatus", "errorPercent", "dropPercent", "bandwidthKBps"}
+		faults      = sharedModel.RouteFaults{ServiceInstanceID: "1", LatencyMs: 500, LatencyPercent: 50, ErrorStatus: 503, ErrorPercent: 10, DropPercent: 5, BandwidthKBps: 64}
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupRouteFaultsDB", func() {
+		It("creates the route_faults table", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS route_faults.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.SetupRouteFaultsDB(db)).To(BeNil())
+		})
+
+		Context("When the table cannot be created", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS route_faults.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+				Expect(sharedUtils.SetupRouteFaultsDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetRouteFaults", func() {
+		It("returns the route faults of the service instance", func() {
+			mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").
+				WillReturnRows(sqlmock.NewRows(faultsNames).AddRow(500, 50, 503, 10, 5, 64))
+			Expect(sharedUtils.GetRouteFaults(db, "1")).To(Equal(faults))
+		})
+
+		Context("When no route faults have been saved", func() {
+			It("returns no faults", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(faultsNames))
+				Expect(sharedUtils.GetRouteFaults(db, "1")).To(Equal(sharedModel.RouteFaults{ServiceInstanceID: "1"}))
+			})
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM route_faults WHERE serviceInstanceID=").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.GetRouteFaults(db, "1")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#UpdateRouteFaults", func() {
+		It("upserts the route faults", func() {
+			mock.ExpectExec("INSERT INTO route_faults .* ON DUPLICATE KEY UPDATE .*").WithArgs("1", 500, 50, 503, 10, 5, 64).WillReturnResult(sqlmock.NewResult(1, 1))
+			Expect(sharedUtils.UpdateRouteFaults(db, faults)).To(BeNil())
+		})
+
+		Context("When the sql command raises an error", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("INSERT INTO route_faults.*").WillReturnError(fmt.Errorf("An error has occured: %s", "INSERT error"))
+				Expect(sharedUtils.UpdateRouteFaults(db, faults)).To(MatchError("An error has occured: INSERT error"))
+			})
+		})
+	})
+
+	Describe("#DeleteRouteFaults", func() {
+		It("deletes the route faults of the service instance", func() {
+			mock.ExpectExec("DELETE FROM route_faults WHERE serviceInstanceID=.*").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(sharedUtils.DeleteRouteFaults(db, "1")).To(BeNil())
+		})
+
+		Context("When the sql command raises an error", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("DELETE FROM route_faults.*").WillReturnError(fmt.Errorf("An error has occured: %s", "DELETE error"))
+				Expect(sharedUtils.DeleteRouteFaults(db, "1")).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,205 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+)
+
+const scheduledChangeColumns = "id, serviceInstanceID, applyAt, probability, frequency, state, appliedAt, revertsID, replacedProbability, replacedFrequency"
+
+// SetupScheduledChangeDB - creates the scheduled_changes DB if it does not exist
+func SetupScheduledChangeDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS scheduled_changes
+	(
+		id int NOT NULL AUTO_INCREMENT,
+		serviceInstanceID varchar(255),
+		applyAt varchar(255),
+		probability double,
+		frequency int,
+		state varchar(255),
+		appliedAt varchar(255),
+		revertsID int,
+		replacedProbability double,
+		replacedFrequency int,
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddScheduledChange - adds a pending row to scheduled_changes database, returning the id of the change
+func AddScheduledChange(db *sql.DB, change sharedModel.ScheduledChange) (int64, error) {
+	probability, frequency := changeValues(change)
+	result, err := db.Exec("INSERT INTO scheduled_changes (serviceInstanceID, applyAt, probability, frequency, state, revertsID) VALUES (?, ?, ?, ?, ?, ?)",
+		change.ServiceInstanceID, change.ApplyAt, probability, frequency, sharedModel.ChangePending, change.RevertsID)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetScheduledChanges - loads the latest scheduled changes of a service instance, latest first
+func GetScheduledChanges(db *sql.DB, serviceInstanceID string, limit int) ([]sharedModel.ScheduledChange, error) {
+	return queryScheduledChanges(db, "SELECT "+scheduledChangeColumns+" FROM scheduled_changes "+
+		"WHERE serviceInstanceID=? ORDER BY applyAt DESC, id DESC LIMIT ?", serviceInstanceID, limit)
+}
+
+// ReadDueScheduledChanges - loads the pending changes of every service instance whose time has come by now, in the order they
+// are applied
+func ReadDueScheduledChanges(db *sql.DB, now string) ([]sharedModel.ScheduledChange, error) {
+	return queryScheduledChanges(db, "SELECT "+scheduledChangeColumns+" FROM scheduled_changes "+
+		"WHERE state=? AND applyAt<=? ORDER BY applyAt, id", sharedModel.ChangePending, now)
+}
+
+// ApplyScheduledChange - claims a pending change and writes it to its service instance, returning false if it was no longer
+// pending, so that only one processor instance applies it. The values the change replaces are recorded first, and a revert
+// writes back those recorded by the change it reverts.
+func ApplyScheduledChange(db *sql.DB, change sharedModel.ScheduledChange, appliedAt string) (bool, error) {
+	result, err := db.Exec("UPDATE scheduled_changes SET state=?, appliedAt=? WHERE id=? AND state=?",
+		sharedModel.ChangeApplied, appliedAt, change.ID, sharedModel.ChangePending)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+
+	probability, frequency := changeValues(change)
+	if change.RevertsID != 0 {
+		err = db.QueryRow("SELECT replacedProbability, replacedFrequency FROM scheduled_changes WHERE id=?", change.RevertsID).Scan(&probability, &frequency)
+	} else {
+		err = recordReplacedValues(db, change)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	_, err = db.Exec("UPDATE service_instances SET probability=COALESCE(?, probability), frequency=COALESCE(?, frequency) WHERE id=?",
+		probability, frequency, change.ServiceInstanceID)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// recordReplacedValues - records the probability and frequency of a service instance that a change is about to replace
+func recordReplacedValues(db *sql.DB, change sharedModel.ScheduledChange) error {
+	var (
+		probability float64
+		frequency   int
+	)
+	err := db.QueryRow("SELECT probability, frequency FROM service_instances WHERE id=?", change.ServiceInstanceID).Scan(&probability, &frequency)
+	if err != nil {
+		return err
+	}
+
+	replacedProbability := sql.NullFloat64{Float64: probability, Valid: change.Probability != nil}
+	replacedFrequency := sql.NullInt64{Int64: int64(frequency), Valid: change.Frequency != nil}
+	_, err = db.Exec("UPDATE scheduled_changes SET replacedProbability=?, replacedFrequency=? WHERE id=?", replacedProbability, replacedFrequency, change.ID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CancelScheduledChange - cancels a pending change of a service instance, and its pending revert, returning false if there
+// was no such pending change
+func CancelScheduledChange(db *sql.DB, serviceInstanceID string, id int64) (bool, error) {
+	result, err := db.Exec("UPDATE scheduled_changes SET state=? WHERE serviceInstanceID=? AND id=? AND state=?",
+		sharedModel.ChangeCancelled, serviceInstanceID, id, sharedModel.ChangePending)
+	if err != nil {
+		return false, err
+	}
+	cancelled, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if cancelled == 0 {
+		return false, nil
+	}
+
+	_, err = db.Exec("UPDATE scheduled_changes SET state=? WHERE serviceInstanceID=? AND revertsID=? AND state=?",
+		sharedModel.ChangeCancelled, serviceInstanceID, id, sharedModel.ChangePending)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteScheduledChanges - deletes every scheduled change of a service instance
+func DeleteScheduledChanges(db *sql.DB, serviceInstanceID string) error {
+	_, err := db.Exec("DELETE FROM scheduled_changes WHERE serviceInstanceID=?", serviceInstanceID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func changeValues(change sharedModel.ScheduledChange) (sql.NullFloat64, sql.NullInt64) {
+	var (
+		probability sql.NullFloat64
+		frequency   sql.NullInt64
+	)
+	if change.Probability != nil {
+		probability = sql.NullFloat64{Float64: *change.Probability, Valid: true}
+	}
+	if change.Frequency != nil {
+		frequency = sql.NullInt64{Int64: int64(*change.Frequency), Valid: true}
+	}
+	return probability, frequency
+}
+
+func nullValues(probability sql.NullFloat64, frequency sql.NullInt64) (*float64, *int) {
+	var (
+		probabilityValue *float64
+		frequencyValue   *int
+	)
+	if probability.Valid {
+		probabilityValue = &probability.Float64
+	}
+	if frequency.Valid {
+		value := int(frequency.Int64)
+		frequencyValue = &value
+	}
+	return probabilityValue, frequencyValue
+}
+
+func queryScheduledChanges(db *sql.DB, query string, args ...interface{}) ([]sharedModel.ScheduledChange, error) {
+	changes := []sharedModel.ScheduledChange{}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			change                           sharedModel.ScheduledChange
+			state, appliedAt                 sql.NullString
+			probability, replacedProbability sql.NullFloat64
+			frequency, replacedFrequency     sql.NullInt64
+			revertsID                        sql.NullInt64
+		)
+		err = rows.Scan(&change.ID, &change.ServiceInstanceID, &change.ApplyAt, &probability, &frequency, &state, &appliedAt, &revertsID,
+			&replacedProbability, &replacedFrequency)
+		if err != nil {
+			return nil, err
+		}
+		change.Probability, change.Frequency = nullValues(probability, frequency)
+		change.ReplacedProbability, change.ReplacedFrequency = nullValues(replacedProbability, replacedFrequency)
+		change.State = state.String
+		change.AppliedAt = appliedAt.String
+		change.RevertsID = revertsID.Int64
+		changes = append(changes, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,133 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Scheduled changes", func() {
+	var (
+		db          *sql.DB
+		mock        sqlmock.Sqlmock
+		change      sharedModel.ScheduledChange
+		changeNames = []string{"id", "serviceInstanceID", "applyAt", "probability", "frequency", "state", "appliedAt", "revertsID", "replacedProbability", "replacedFrequency"}
+		probability = 0.4
+		dbError     = fmt.Errorf("An error has occurred: %s", "DB error")
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+		change = sharedModel.ScheduledChange{ID: 3, ServiceInstanceID: "1", ApplyAt: "2014-11-17T09:00:00Z", Probability: &probability}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#AddScheduledChange", func() {
+		It("stores the change as pending, leaving out what is kept", func() {
+			mock.ExpectExec("INSERT INTO scheduled_changes").WithArgs("1", "2014-11-17T09:00:00Z", 0.4, nil, "pending", 0).WillReturnResult(sqlmock.NewResult(3, 1))
+			id, err := sharedUtils.AddScheduledChange(db, change)
+			Expect(err).To(BeNil())
+			Expect(id).To(Equal(int64(3)))
+		})
+	})
+
+	Describe("#ReadDueScheduledChanges", func() {
+		It("loads the pending changes whose time has come", func() {
+			rows := sqlmock.NewRows(changeNames).
+				AddRow(3, "1", "2014-11-17T09:00:00Z", 0.4, nil, "pending", nil, 0, nil, nil).
+				AddRow(4, "2", "2014-11-17T09:00:00Z", nil, 10, "pending", nil, 2, nil, nil)
+			mock.ExpectQuery("^SELECT (.+) FROM scheduled_changes WHERE state=\\? AND applyAt<=\\?").WithArgs("pending", "2014-11-17T09:01:00Z").WillReturnRows(rows)
+			changes, err := sharedUtils.ReadDueScheduledChanges(db, "2014-11-17T09:01:00Z")
+			Expect(err).To(BeNil())
+			Expect(changes).To(HaveLen(2))
+			Expect(*changes[0].Probability).To(Equal(0.4))
+			Expect(changes[0].Frequency).To(BeNil())
+			Expect(changes[1].Probability).To(BeNil())
+			Expect(*changes[1].Frequency).To(Equal(10))
+			Expect(changes[1].RevertsID).To(Equal(int64(2)))
+		})
+	})
+
+	Describe("#ApplyScheduledChange", func() {
+		It("claims the change, records the values it replaces and writes it to the service instance", func() {
+			mock.ExpectExec("UPDATE scheduled_changes SET state=\\?, appliedAt=\\?").WithArgs("applied", "2014-11-17T09:01:00Z", 3, "pending").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery("^SELECT probability, frequency FROM service_instances WHERE id=\\?").WithArgs("1").
+				WillReturnRows(sqlmock.NewRows([]string{"probability", "frequency"}).AddRow(0.2, 5))
+			mock.ExpectExec("UPDATE scheduled_changes SET replacedProbability=\\?, replacedFrequency=\\? WHERE id=\\?").WithArgs(0.2, nil, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE service_instances SET probability=COALESCE").WithArgs(0.4, nil, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+			applied, err := sharedUtils.ApplyScheduledChange(db, change, "2014-11-17T09:01:00Z")
+			Expect(err).To(BeNil())
+			Expect(applied).To(BeTrue())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the change is a revert", func() {
+			It("writes back the values replaced by the change it reverts", func() {
+				revert := sharedModel.ScheduledChange{ID: 4, ServiceInstanceID: "1", ApplyAt: "2014-11-21T17:00:00Z", RevertsID: 3}
+				mock.ExpectExec("UPDATE scheduled_changes SET state=\\?, appliedAt=\\?").WithArgs("applied", "2014-11-21T17:00:10Z", 4, "pending").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("^SELECT replacedProbability, replacedFrequency FROM scheduled_changes WHERE id=\\?").WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"replacedProbability", "replacedFrequency"}).AddRow(0.3, nil))
+				mock.ExpectExec("UPDATE service_instances SET probability=COALESCE").WithArgs(0.3, nil, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+				applied, err := sharedUtils.ApplyScheduledChange(db, revert, "2014-11-21T17:00:10Z")
+				Expect(err).To(BeNil())
+				Expect(applied).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When another processor claimed the change first", func() {
+			It("leaves the service instance alone", func() {
+				mock.ExpectExec("UPDATE scheduled_changes SET state=\\?, appliedAt=\\?").WillReturnResult(sqlmock.NewResult(0, 0))
+				applied, err := sharedUtils.ApplyScheduledChange(db, change, "2014-11-17T09:01:00Z")
+				Expect(err).To(BeNil())
+				Expect(applied).To(BeFalse())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		Context("When the service instance cannot be updated", func() {
+			It("returns the error", func() {
+				mock.ExpectExec("UPDATE scheduled_changes SET state=\\?, appliedAt=\\?").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("^SELECT probability, frequency FROM service_instances").WillReturnRows(sqlmock.NewRows([]string{"probability", "frequency"}).AddRow(0.2, 5))
+				mock.ExpectExec("UPDATE scheduled_changes SET replacedProbability").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE service_instances").WillReturnError(dbError)
+				_, err := sharedUtils.ApplyScheduledChange(db, change, "2014-11-17T09:01:00Z")
+				Expect(err).To(Equal(dbError))
+			})
+		})
+	})
+
+	Describe("#CancelScheduledChange", func() {
+		It("cancels a pending change of the service instance and its pending revert", func() {
+			mock.ExpectExec("UPDATE scheduled_changes SET state=\\? WHERE serviceInstanceID=\\? AND id=\\? AND state=\\?").WithArgs("cancelled", "1", 3, "pending").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE scheduled_changes SET state=\\? WHERE serviceInstanceID=\\? AND revertsID=\\? AND state=\\?").WithArgs("cancelled", "1", 3, "pending").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			cancelled, err := sharedUtils.CancelScheduledChange(db, "1", 3)
+			Expect(err).To(BeNil())
+			Expect(cancelled).To(BeTrue())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		It("only cancels a pending change of the service instance", func() {
+			mock.ExpectExec("UPDATE scheduled_changes SET state=\\? WHERE serviceInstanceID=\\? AND id=\\? AND state=\\?").WithArgs("cancelled", "1", 3, "pending").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			cancelled, err := sharedUtils.CancelScheduledChange(db, "1", 3)
+			Expect(err).To(BeNil())
+			Expect(cancelled).To(BeFalse())
+		})
+	})
+})
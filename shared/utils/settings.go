@@ -0,0 +1,174 @@
+package sharedUtils
+
+import (
+	"database/sql"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"strings"
+)
+
+// InstanceSettingsColumns - the columns of instance_settings, in the order they are read
+var InstanceSettingsColumns = []string{"serviceInstanceID", "seed", "strategy", "strategyIndex", "killCount", "killPercent", "minHealthy",
+	"minInstances", "minHealthyPercent", "healthyStates", "action", "actionDelay", "intensity", "mtbfMinutes",
+	"adaptiveStep", "maxRecoverySeconds"}
+
+var (
+	instanceSettingsColumns    = strings.Join(InstanceSettingsColumns, ", ")
+	instanceSettingsMigrations = []Column{
+		{Name: "strategy", Definition: "varchar(255)"},
+		{Name: "strategyIndex", Definition: "int"},
+		{Name: "killCount", Definition: "int"},
+		{Name: "killPercent", Definition: "int"},
+		{Name: "minHealthy", Definition: "int"},
+		{Name: "minInstances", Definition: "int"},
+		{Name: "minHealthyPercent", Definition: "int"},
+		{Name: "healthyStates", Definition: "varchar(255)"},
+		{Name: "action", Definition: "varchar(255)"},
+		{Name: "actionDelay", Definition: "int"},
+		{Name: "intensity", Definition: "int"},
+		{Name: "mtbfMinutes", Definition: "int"},
+		{Name: "adaptiveStep", Definition: "int"},
+		{Name: "maxRecoverySeconds", Definition: "int"},
+	}
+)
+
+const minutesPerDay = 24 * 60
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// SetupInstanceSettingsDB - creates the instance_settings DB if it does not exist
+func SetupInstanceSettingsDB(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS instance_settings
+	(
+		serviceInstanceID varchar(255),
+		seed bigint,
+		PRIMARY KEY (serviceInstanceID)
+	)`)
+	if err != nil {
+		return err
+	}
+	return AddColumns(db, "instance_settings", instanceSettingsMigrations)
+}
+
+// ReadInstanceSettings - Loads instance settings to memory from Database
+func ReadInstanceSettings(db *sql.DB) (map[string]sharedModel.InstanceSettings, error) {
+	settingsMap := make(map[string]sharedModel.InstanceSettings)
+
+	rows, err := db.Query("SELECT " + instanceSettingsColumns + " FROM instance_settings")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		settings, err := scanInstanceSettings(rows)
+		if err != nil {
+			return nil, err
+		}
+		settingsMap[settings.ServiceInstanceID] = settings
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return settingsMap, nil
+}
+
+// GetInstanceSettings - loads the settings of a service instance, returning the defaults if none have been saved
+func GetInstanceSettings(db *sql.DB, serviceInstanceID string) (sharedModel.InstanceSettings, error) {
+	row := db.QueryRow("SELECT "+instanceSettingsColumns+" FROM instance_settings WHERE serviceInstanceID=?", serviceInstanceID)
+	settings, err := scanInstanceSettings(row)
+	if err == sql.ErrNoRows {
+		return sharedModel.NewInstanceSettings(serviceInstanceID), nil
+	}
+	if err != nil {
+		return sharedModel.InstanceSettings{}, err
+	}
+	return settings, nil
+}
+
+func scanInstanceSettings(row rowScanner) (sharedModel.InstanceSettings, error) {
+	var (
+		serviceInstanceID                                       string
+		seed, strategyIndex, killCount, killPercent, minHealthy sql.NullInt64
+		minInstances, minHealthyPercent, actionDelay, intensity sql.NullInt64
+		mtbfMinutes, adaptiveStep, maxRecoverySeconds           sql.NullInt64
+		strategy, healthyStates, action                         sql.NullString
+	)
+
+	err := row.Scan(&serviceInstanceID, &seed, &strategy, &strategyIndex, &killCount, &killPercent, &minHealthy,
+		&minInstances, &minHealthyPercent, &healthyStates, &action, &actionDelay, &intensity, &mtbfMinutes,
+		&adaptiveStep, &maxRecoverySeconds)
+	if err != nil {
+		return sharedModel.InstanceSettings{}, err
+	}
+
+	settings := sharedModel.NewInstanceSettings(serviceInstanceID)
+	settings.Seed = seed.Int64
+	if sharedModel.IsStrategy(strategy.String) {
+		settings.Strategy = strategy.String
+	}
+	settings.StrategyIndex = int(strategyIndex.Int64)
+	if killCount.Int64 > 0 {
+		settings.KillCount = int(killCount.Int64)
+	}
+	settings.KillPercent = int(killPercent.Int64)
+	if minHealthy.Valid {
+		settings.MinHealthy = int(minHealthy.Int64)
+	}
+	if minInstances.Valid {
+		settings.MinInstances = int(minInstances.Int64)
+	}
+	if minHealthyPercent.Valid {
+		settings.MinHealthyPercent = int(minHealthyPercent.Int64)
+	}
+	if states := ParseHealthyStates(healthyStates.String); len(states) > 0 {
+		settings.HealthyStates = states
+	}
+	if sharedModel.IsAction(action.String) {
+		settings.Action = action.String
+	}
+	if actionDelay.Valid {
+		settings.ActionDelay = int(actionDelay.Int64)
+	}
+	if intensity.Int64 > 0 {
+		settings.Intensity = int(intensity.Int64)
+	}
+	settings.MTBFMinutes = int(mtbfMinutes.Int64)
+	settings.AdaptiveStep = int(adaptiveStep.Int64)
+	if maxRecoverySeconds.Int64 > 0 {
+		settings.MaxRecoverySeconds = int(maxRecoverySeconds.Int64)
+	}
+	return settings, nil
+}
+
+// InstanceSettingsValues - returns the values of settings in the order of InstanceSettingsColumns
+func InstanceSettingsValues(settings sharedModel.InstanceSettings) []interface{} {
+	return []interface{}{settings.ServiceInstanceID, settings.Seed, settings.Strategy, settings.StrategyIndex,
+		settings.KillCount, settings.KillPercent, settings.MinHealthy,
+		settings.MinInstances, settings.MinHealthyPercent, strings.Join(settings.HealthyStates, ","), settings.Action, settings.ActionDelay, settings.Intensity,
+		settings.MTBFMinutes, settings.AdaptiveStep, settings.MaxRecoverySeconds}
+}
+
+// ExpectedKillsPerDay - returns the number of chaos runs a day expected of a bound app, from its mean time between failures
+// when one is set, otherwise from the probability of chaos on each run every frequency minutes
+func ExpectedKillsPerDay(probability float64, frequency int, mtbfMinutes int) float64 {
+	if mtbfMinutes > 0 {
+		return minutesPerDay / float64(mtbfMinutes)
+	}
+	if frequency <= 0 {
+		return 0
+	}
+	return probability * minutesPerDay / float64(frequency)
+}
+
+// ParseHealthyStates - splits a comma separated list of app instance states, dropping blanks and unknown states
+func ParseHealthyStates(states string) []string {
+	var parsed []string
+	for _, state := range strings.Split(states, ",") {
+		state = strings.ToUpper(strings.TrimSpace(state))
+		if sharedModel.IsInstanceState(state) {
+			parsed = append(parsed, state)
+		}
+	}
+	return parsed
+}
@@ -0,0 +1,158 @@
+package sharedUtils_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FidelityInternational/chaos-galago/shared/model"
+	"github.com/FidelityInternational/chaos-galago/shared/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Instance settings", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		if err != nil {
+			fmt.Printf("\nan error '%s' was not expected when opening a stub database connection\n", err)
+			os.Exit(1)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("#SetupInstanceSettingsDB", func() {
+		It("creates the instance_settings table and adds any missing columns", func() {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS instance_settings.*").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("instance_settings", "strategy").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectExec("ALTER TABLE instance_settings ADD COLUMN strategy .*").WillReturnResult(sqlmock.NewResult(0, 0))
+			for _, column := range []string{"strategyIndex", "killCount", "killPercent", "minHealthy", "minInstances", "minHealthyPercent", "healthyStates", "action", "actionDelay", "intensity", "mtbfMinutes", "adaptiveStep", "maxRecoverySeconds"} {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.COLUMNS .*").WithArgs("instance_settings", column).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			}
+			Expect(sharedUtils.SetupInstanceSettingsDB(db)).To(BeNil())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+
+		Context("When the table cannot be created", func() {
+			It("returns an error", func() {
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS instance_settings.*").WillReturnError(fmt.Errorf("An error has occured: %s", "Database Create Error"))
+				Expect(sharedUtils.SetupInstanceSettingsDB(db)).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#ReadInstanceSettings", func() {
+		It("returns the settings of every service instance", func() {
+			rows := sqlmock.NewRows(sharedUtils.InstanceSettingsColumns).
+				AddRow("1", 42, "oldest", 3, 2, 50, 1, 2, 0, "RUNNING,STARTING", "stop-start", 0, 80, 240, 5, 90).
+				AddRow("2", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+			mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnRows(rows)
+
+			settingsMap, err := sharedUtils.ReadInstanceSettings(db)
+			Expect(err).To(BeNil())
+			Expect(settingsMap).To(HaveLen(2))
+			Expect(settingsMap["1"]).To(Equal(sharedModel.InstanceSettings{ServiceInstanceID: "1", Seed: 42, Strategy: "oldest", StrategyIndex: 3,
+				KillCount: 2, KillPercent: 50, MinHealthy: 1, MinInstances: 2, MinHealthyPercent: 0, HealthyStates: []string{"RUNNING", "STARTING"},
+				Action: "stop-start", ActionDelay: 0, Intensity: 80, MTBFMinutes: 240, AdaptiveStep: 5, MaxRecoverySeconds: 90}))
+			Expect(settingsMap["2"]).To(Equal(sharedModel.NewInstanceSettings("2")))
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM instance_settings$").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.ReadInstanceSettings(db)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#GetInstanceSettings", func() {
+		It("returns the settings of the service instance", func() {
+			rows := sqlmock.NewRows(sharedUtils.InstanceSettingsColumns).AddRow("1", 42, "not-a-strategy", nil, 0, nil, nil, nil, nil, "", "not-an-action", nil, 0, nil, nil, 0)
+			mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(rows)
+
+			settings, err := sharedUtils.GetInstanceSettings(db, "1")
+			Expect(err).To(BeNil())
+			Expect(settings.ServiceInstanceID).To(Equal("1"))
+			Expect(settings.Seed).To(Equal(int64(42)))
+			Expect(settings.Strategy).To(Equal("random"))
+			Expect(settings.KillCount).To(Equal(1))
+			Expect(settings.HealthyStates).To(Equal([]string{"RUNNING"}))
+			Expect(settings.Action).To(Equal("kill"))
+			Expect(settings.ActionDelay).To(Equal(30))
+			Expect(settings.Intensity).To(Equal(50))
+			Expect(settings.MaxRecoverySeconds).To(Equal(60))
+		})
+
+		Context("When no settings have been saved", func() {
+			It("returns the default settings", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnRows(sqlmock.NewRows(sharedUtils.InstanceSettingsColumns))
+				settings, err := sharedUtils.GetInstanceSettings(db, "1")
+				Expect(err).To(BeNil())
+				Expect(settings).To(Equal(sharedModel.NewInstanceSettings("1")))
+			})
+		})
+
+		Context("When the query fails", func() {
+			It("returns an error", func() {
+				mock.ExpectQuery("^SELECT (.+) FROM instance_settings WHERE serviceInstanceID=").WithArgs("1").WillReturnError(fmt.Errorf("An error has occured: %s", "DB error"))
+				_, err := sharedUtils.GetInstanceSettings(db, "1")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("#InstanceSettingsValues", func() {
+		It("returns a value for every column", func() {
+			settings := sharedModel.NewInstanceSettings("1")
+			settings.HealthyStates = []string{"RUNNING", "STARTING"}
+			values := sharedUtils.InstanceSettingsValues(settings)
+			Expect(values).To(HaveLen(len(sharedUtils.InstanceSettingsColumns)))
+			Expect(values[0]).To(Equal("1"))
+			Expect(values[len(values)-7]).To(Equal("RUNNING,STARTING"))
+			Expect(values[len(values)-6]).To(Equal("kill"))
+			Expect(values[len(values)-5]).To(Equal(30))
+			Expect(values[len(values)-4]).To(Equal(50))
+			Expect(values[len(values)-3]).To(Equal(0))
+			Expect(values[len(values)-2]).To(Equal(0))
+			Expect(values[len(values)-1]).To(Equal(60))
+		})
+	})
+
+	Describe("#ExpectedKillsPerDay", func() {
+		It("counts the runs a day that make chaos with the probability", func() {
+			Expect(sharedUtils.ExpectedKillsPerDay(0.2, 5, 0)).To(BeNumerically("~", 57.6, 0.001))
+		})
+
+		It("counts the failures a day of the mean time between failures", func() {
+			Expect(sharedUtils.ExpectedKillsPerDay(0.2, 5, 240)).To(BeNumerically("~", 6, 0.001))
+		})
+
+		Context("When no frequency is set", func() {
+			It("expects none", func() {
+				Expect(sharedUtils.ExpectedKillsPerDay(0.2, 0, 0)).To(BeZero())
+			})
+		})
+	})
+
+	Describe("#ParseHealthyStates", func() {
+		It("returns the known states in upper case", func() {
+			Expect(sharedUtils.ParseHealthyStates("running, starting,,bogus")).To(Equal([]string{"RUNNING", "STARTING"}))
+		})
+
+		Context("When no state is known", func() {
+			It("returns no states", func() {
+				Expect(sharedUtils.ParseHealthyStates("")).To(BeEmpty())
+			})
+		})
+	})
+})